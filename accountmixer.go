@@ -0,0 +1,143 @@
+package dcrlibwallet
+
+import (
+	"context"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// mixedOutputMinCount is the minimum number of outputs of the same amount
+// that a transaction must pay for those outputs to be considered mixed. Mixes
+// pay every peer outputs of the same amount, other transactions rarely pay
+// several outputs of the same amount.
+const mixedOutputMinCount = 3
+
+// SetMixerAccounts designates `mixedAccount` as the account that holds mixed
+// funds and `unmixedAccount` as the account that holds unmixed funds. Change
+// from transactions that spend mixed funds is sent to the unmixed account so
+// that the mixed account only receives mixed outputs. Outputs of the mixed
+// account that were not received from a mix are not selected to fund
+// transactions from the mixed account and are not counted in its spendable
+// balance, see `Balance.Unmixed`.
+func (wallet *Wallet) SetMixerAccounts(mixedAccount, unmixedAccount int32) error {
+	if mixedAccount == unmixedAccount || mixedAccount < 0 || unmixedAccount < 0 ||
+		mixedAccount == ImportedAccountNumber || unmixedAccount == ImportedAccountNumber {
+		return errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	for _, account := range []int32{mixedAccount, unmixedAccount} {
+		_, err := wallet.internal.AccountProperties(ctx, uint32(account))
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	wallet.SaveUserConfigValue(MixedAccountConfigKey, mixedAccount)
+	wallet.SaveUserConfigValue(UnmixedAccountConfigKey, unmixedAccount)
+	return nil
+}
+
+// ClearMixerAccounts removes the mixed and unmixed account designation.
+func (wallet *Wallet) ClearMixerAccounts() {
+	wallet.DeleteUserConfigValueForKey(MixedAccountConfigKey)
+	wallet.DeleteUserConfigValueForKey(UnmixedAccountConfigKey)
+}
+
+// MixedAccountNumber returns the number of the mixed account, or -1 if no
+// account has been designated as the mixed account.
+func (wallet *Wallet) MixedAccountNumber() int32 {
+	return wallet.ReadInt32ConfigValueForKey(MixedAccountConfigKey, -1)
+}
+
+// UnmixedAccountNumber returns the number of the unmixed account, or -1 if no
+// account has been designated as the unmixed account.
+func (wallet *Wallet) UnmixedAccountNumber() int32 {
+	return wallet.ReadInt32ConfigValueForKey(UnmixedAccountConfigKey, -1)
+}
+
+// changeAccount returns the account that receives change from transactions
+// spending funds of `sourceAccount`.
+func (wallet *Wallet) changeAccount(sourceAccount uint32) uint32 {
+	if wallet.isMixedAccount(sourceAccount) {
+		return uint32(wallet.UnmixedAccountNumber())
+	}
+	return sourceAccount
+}
+
+// isMixedAccount returns true if `account` is designated as the mixed account
+// along with an unmixed account.
+func (wallet *Wallet) isMixedAccount(account uint32) bool {
+	mixedAccount, unmixedAccount := wallet.MixedAccountNumber(), wallet.UnmixedAccountNumber()
+	return mixedAccount >= 0 && unmixedAccount >= 0 && int32(account) == mixedAccount
+}
+
+// isMixedOutput returns true if the output at `index` of the transaction with
+// hash `txHash` was received from a mix.
+func (wallet *Wallet) isMixedOutput(ctx context.Context, txHash *chainhash.Hash, index uint32) bool {
+	txDetails, err := wallet.internal.TxDetails(ctx, txHash)
+	if err != nil {
+		return false
+	}
+	return isMixTxOutput(&txDetails.MsgTx, index)
+}
+
+// isMixTxOutput returns true if `tx` pays at least `mixedOutputMinCount`
+// outputs of the same amount as the output at `index`.
+func isMixTxOutput(tx *wire.MsgTx, index uint32) bool {
+	if int(index) >= len(tx.TxOut) {
+		return false
+	}
+
+	amount := tx.TxOut[index].Value
+	var sameAmountOutputs int
+	for _, txOut := range tx.TxOut {
+		if txOut.Value == amount {
+			sameAmountOutputs++
+		}
+	}
+	return sameAmountOutputs >= mixedOutputMinCount
+}
+
+// mixedOutputs returns the outputs of `outputs` that were received from a
+// mix.
+func (wallet *Wallet) mixedOutputs(ctx context.Context, outputs []*w.TransactionOutput) []*w.TransactionOutput {
+	mixedOutputs := make([]*w.TransactionOutput, 0, len(outputs))
+	for _, output := range outputs {
+		if wallet.isMixedOutput(ctx, &output.OutPoint.Hash, output.OutPoint.Index) {
+			mixedOutputs = append(mixedOutputs, output)
+		}
+	}
+	return mixedOutputs
+}
+
+// unmixedBalance returns the total amount of the outputs of the mixed account
+// with at least `requiredConfirmations` confirmations that were not received
+// from a mix. Returns 0 for other accounts.
+func (wallet *Wallet) unmixedBalance(account uint32, requiredConfirmations int32) (int64, error) {
+	if !wallet.isMixedAccount(account) {
+		return 0, nil
+	}
+
+	policy := w.OutputSelectionPolicy{
+		Account:               account,
+		RequiredConfirmations: requiredConfirmations,
+	}
+
+	ctx := wallet.shutdownContext()
+	outputs, err := wallet.internal.UnspentOutputs(ctx, policy)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var total int64
+	for _, output := range outputs {
+		if !wallet.isMixedOutput(ctx, &output.OutPoint.Hash, output.OutPoint.Index) {
+			total += output.Output.Value
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,32 @@
+package dcrlibwallet
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/wire"
+)
+
+func TestIsMixTxOutput(t *testing.T) {
+	tx := wire.NewMsgTx()
+	for _, amount := range []int64{5e7, 5e7, 5e7, 1e8, 1e8, 3e8} {
+		tx.AddTxOut(wire.NewTxOut(amount, []byte{0x51}))
+	}
+
+	tests := []struct {
+		name  string
+		index uint32
+		mixed bool
+	}{
+		{name: "mixed output", index: 0, mixed: true},
+		{name: "last mixed output", index: 2, mixed: true},
+		{name: "two outputs of the same amount", index: 3, mixed: false},
+		{name: "single output of its amount", index: 5, mixed: false},
+		{name: "index out of range", index: 6, mixed: false},
+	}
+
+	for _, test := range tests {
+		if mixed := isMixTxOutput(tx, test.index); mixed != test.mixed {
+			t.Fatalf("%s: mixed is %v, want %v", test.name, mixed, test.mixed)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// listenForAccountNotifications publishes the accounts created or renamed in
+// the specified wallet to the account notification listeners. The wallet's
+// account notifications are also sent when account key counts change, those
+// notifications are ignored.
+func (mw *MultiWallet) listenForAccountNotifications(walletID int) {
+	wallet := mw.WalletWithID(walletID)
+	n := wallet.internal.NtfnServer.AccountNotifications()
+	defer n.Done() // disassociate this notification client from server when this function exits.
+
+	accounts, err := wallet.internal.Accounts(wallet.shutdownContext())
+	if err != nil {
+		log.Errorf("[%d] Error reading accounts for account notifications: %v", walletID, err)
+		return
+	}
+
+	accountNames := make(map[uint32]string, len(accounts.Accounts))
+	for _, account := range accounts.Accounts {
+		accountNames[account.AccountNumber] = account.AccountName
+	}
+
+	for {
+		v := <-n.C
+		accountName, known := accountNames[v.AccountNumber]
+		accountNames[v.AccountNumber] = v.AccountName
+
+		if !known {
+			log.Infof("[%d] New account %d: %s", walletID, v.AccountNumber, v.AccountName)
+			mw.publishAccountCreated(walletID, int32(v.AccountNumber))
+		} else if accountName != v.AccountName {
+			log.Infof("[%d] Account %d renamed to %s", walletID, v.AccountNumber, v.AccountName)
+			mw.publishAccountRenamed(walletID, int32(v.AccountNumber), v.AccountName)
+		}
+	}
+}
+
+func (mw *MultiWallet) AddAccountNotificationListener(accountNotificationListener AccountNotificationListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	_, ok := mw.accountNotificationListeners[uniqueIdentifier]
+	if ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.accountNotificationListeners[uniqueIdentifier] = accountNotificationListener
+
+	return nil
+}
+
+func (mw *MultiWallet) RemoveAccountNotificationListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.accountNotificationListeners, uniqueIdentifier)
+}
+
+func (mw *MultiWallet) publishAccountCreated(walletID int, accountNumber int32) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, accountNotificationListener := range mw.accountNotificationListeners {
+		accountNotificationListener.OnAccountCreated(walletID, accountNumber)
+	}
+}
+
+func (mw *MultiWallet) publishAccountRenamed(walletID int, accountNumber int32, newName string) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, accountNotificationListener := range mw.accountNotificationListeners {
+		accountNotificationListener.OnAccountRenamed(walletID, accountNumber, newName)
+	}
+}
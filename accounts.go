@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/asdine/storm/q"
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrwallet/errors/v2"
 )
@@ -12,26 +13,63 @@ import (
 func (wallet *Wallet) GetAccounts() (string, error) {
 	accountsResponse, err := wallet.GetAccountsRaw()
 	if err != nil {
-		return "", nil
+		return "", err
+	}
+
+	result, err := json.Marshal(accountsResponse)
+	if err != nil {
+		return "", err
 	}
 
-	result, _ := json.Marshal(accountsResponse)
 	return string(result), nil
 }
 
+// GetAccountsRaw returns the accounts of the wallet that are not hidden.
 func (wallet *Wallet) GetAccountsRaw() (*Accounts, error) {
+	return wallet.getAccounts(false)
+}
+
+// GetAllAccounts returns the json-encoded accounts of the wallet, including
+// hidden accounts.
+func (wallet *Wallet) GetAllAccounts() (string, error) {
+	accountsResponse, err := wallet.GetAllAccountsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := json.Marshal(accountsResponse)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+func (wallet *Wallet) GetAllAccountsRaw() (*Accounts, error) {
+	return wallet.getAccounts(true)
+}
+
+func (wallet *Wallet) getAccounts(includeHidden bool) (*Accounts, error) {
 	resp, err := wallet.internal.Accounts(wallet.shutdownContext())
 	if err != nil {
 		return nil, err
 	}
-	accounts := make([]*Account, len(resp.Accounts))
-	for i, account := range resp.Accounts {
+
+	hiddenAccounts := wallet.hiddenAccounts()
+	mixedAccount, unmixedAccount := wallet.MixedAccountNumber(), wallet.UnmixedAccountNumber()
+	accounts := make([]*Account, 0, len(resp.Accounts))
+	for _, account := range resp.Accounts {
+		hidden := hiddenAccounts[int32(account.AccountNumber)]
+		if hidden && !includeHidden {
+			continue
+		}
+
 		balance, err := wallet.GetAccountBalance(int32(account.AccountNumber))
 		if err != nil {
 			return nil, err
 		}
 
-		accounts[i] = &Account{
+		accounts = append(accounts, &Account{
 			WalletID:         wallet.ID,
 			Number:           int32(account.AccountNumber),
 			Name:             account.AccountName,
@@ -40,11 +78,15 @@ func (wallet *Wallet) GetAccountsRaw() (*Accounts, error) {
 			ExternalKeyCount: int32(account.LastUsedExternalIndex + 20),
 			InternalKeyCount: int32(account.LastUsedInternalIndex + 20),
 			ImportedKeyCount: int32(account.ImportedKeyCount),
-		}
+			Hidden:           hidden,
+			Imported:         int32(account.AccountNumber) == ImportedAccountNumber,
+			Mixed:            int32(account.AccountNumber) == mixedAccount,
+			Unmixed:          int32(account.AccountNumber) == unmixedAccount,
+		})
 	}
 
 	return &Accounts{
-		Count:              len(resp.Accounts),
+		Count:              len(accounts),
 		CurrentBlockHash:   resp.CurrentBlockHash[:],
 		CurrentBlockHeight: resp.CurrentBlockHeight,
 		Acc:                accounts,
@@ -77,6 +119,21 @@ func (accountsInterator *AccountsIterator) Reset() {
 	accountsInterator.currentIndex = 0
 }
 
+// AccountsCount returns the number of accounts in the response. Use with
+// `AccountAt` to read the accounts from gomobile bindings, which cannot
+// access the `Acc` slice.
+func (accounts *Accounts) AccountsCount() int {
+	return len(accounts.Acc)
+}
+
+// AccountAt returns the account at `index`, or nil if `index` is out of range.
+func (accounts *Accounts) AccountAt(index int) *Account {
+	if index < 0 || index >= len(accounts.Acc) {
+		return nil
+	}
+	return accounts.Acc[index]
+}
+
 func (wallet *Wallet) GetAccount(accountNumber int32) (*Account, error) {
 	props, err := wallet.internal.AccountProperties(wallet.shutdownContext(), uint32(accountNumber))
 	if err != nil {
@@ -97,18 +154,38 @@ func (wallet *Wallet) GetAccount(accountNumber int32) (*Account, error) {
 		ExternalKeyCount: int32(props.LastUsedExternalIndex + 20),
 		InternalKeyCount: int32(props.LastUsedInternalIndex + 20),
 		ImportedKeyCount: int32(props.ImportedKeyCount),
+		Hidden:           wallet.IsAccountHidden(accountNumber),
+		Imported:         accountNumber == ImportedAccountNumber,
+		Mixed:            accountNumber == wallet.MixedAccountNumber(),
+		Unmixed:          accountNumber == wallet.UnmixedAccountNumber(),
 	}
 
 	return account, nil
 }
 
+// GetAccountBalance returns the balance of the specified account under the
+// wallet's confirmation policy.
 func (wallet *Wallet) GetAccountBalance(accountNumber int32) (*Balance, error) {
-	balance, err := wallet.internal.CalculateAccountBalance(wallet.shutdownContext(), uint32(accountNumber), wallet.RequiredConfirmations())
+	return wallet.GetAccountBalanceWithConfirmations(accountNumber, wallet.RequiredConfirmations())
+}
+
+// GetAccountBalanceWithConfirmations returns the balance of the specified
+// account, counting outputs with at least `requiredConfirmations`
+// confirmations as spendable. Pass 0 for the balance including unconfirmed
+// outputs or `wallet.RequiredConfirmations()` for the wallet's confirmation
+// policy, which also counts unconfirmed change as spendable if the wallet
+// spends unconfirmed change.
+func (wallet *Wallet) GetAccountBalanceWithConfirmations(accountNumber int32, requiredConfirmations int32) (*Balance, error) {
+	if requiredConfirmations < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	balance, err := wallet.internal.CalculateAccountBalance(wallet.shutdownContext(), uint32(accountNumber), requiredConfirmations)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Balance{
+	accountBalance := &Balance{
 		Total:                   int64(balance.Total),
 		Spendable:               int64(balance.Spendable),
 		ImmatureReward:          int64(balance.ImmatureCoinbaseRewards),
@@ -116,18 +193,42 @@ func (wallet *Wallet) GetAccountBalance(accountNumber int32) (*Balance, error) {
 		LockedByTickets:         int64(balance.LockedByTickets),
 		VotingAuthority:         int64(balance.VotingAuthority),
 		UnConfirmed:             int64(balance.Unconfirmed),
-	}, nil
+	}
+
+	// unconfirmed change is spendable under the wallet's confirmation policy
+	// if the wallet allows spending unconfirmed change.
+	if requiredConfirmations > 0 && requiredConfirmations == wallet.RequiredConfirmations() &&
+		wallet.SpendUnconfirmedChange() {
+		unconfirmedChange, err := wallet.unconfirmedChange(uint32(accountNumber))
+		if err != nil {
+			return nil, err
+		}
+		accountBalance.Spendable += unconfirmedChange
+		accountBalance.UnConfirmed -= unconfirmedChange
+	}
+
+	unmixed, err := wallet.unmixedBalance(uint32(accountNumber), requiredConfirmations)
+	if err != nil {
+		return nil, err
+	}
+	accountBalance.Spendable -= unmixed
+	accountBalance.Unmixed = unmixed
+
+	return accountBalance, nil
 }
 
 func (wallet *Wallet) SpendableForAccount(account int32) (int64, error) {
-	bals, err := wallet.internal.CalculateAccountBalance(wallet.shutdownContext(), uint32(account), wallet.RequiredConfirmations())
+	balance, err := wallet.GetAccountBalance(account)
 	if err != nil {
 		log.Error(err)
 		return 0, translateError(err)
 	}
-	return int64(bals.Spendable), nil
+	return balance.Spendable, nil
 }
 
+// NextAccount creates a new account named `accountName` and returns the new
+// account number. Account notification listeners are notified of the new
+// account.
 func (wallet *Wallet) NextAccount(accountName string, privPass []byte) (int32, error) {
 	lock := make(chan time.Time, 1)
 	defer func() {
@@ -137,24 +238,93 @@ func (wallet *Wallet) NextAccount(accountName string, privPass []byte) (int32, e
 		lock <- time.Time{} // send matters, not the value
 	}()
 
+	if wallet.IsWatchingOnlyWallet() {
+		return 0, errors.New(ErrWalletIsWatchOnly)
+	}
+
 	ctx := wallet.shutdownContext()
-	err := wallet.internal.Unlock(ctx, privPass, lock)
+	err := wallet.unlock(ctx, privPass, lock)
 	if err != nil {
 		log.Error(err)
-		return 0, errors.New(ErrInvalidPassphrase)
+		return 0, err
 	}
 
 	accountNumber, err := wallet.internal.NextAccount(ctx, accountName)
+	if err != nil {
+		return 0, translateError(err)
+	}
 
-	return int32(accountNumber), err
+	return int32(accountNumber), nil
 }
 
+// RenameAccount renames the specified account. The account name is also
+// updated in the indexed transactions that debit or credit the account.
 func (wallet *Wallet) RenameAccount(accountNumber int32, newName string) error {
 	err := wallet.internal.RenameAccount(wallet.shutdownContext(), uint32(accountNumber), newName)
 	if err != nil {
 		return translateError(err)
 	}
 
+	err = wallet.renameAccountInIndexedTransactions(accountNumber, newName)
+	if err != nil {
+		log.Errorf("[%d] Error updating account name in indexed transactions: %v", wallet.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// renameAccountInIndexedTransactions updates the account name recorded in the
+// indexed transactions that spend from or pay to the account.
+func (wallet *Wallet) renameAccountInIndexedTransactions(accountNumber int32, newName string) error {
+	accounts := map[int32]bool{accountNumber: true}
+	matchers := []q.Matcher{q.Or(
+		q.NewFieldMatcher("Inputs", txInputsAccountMatcher(accounts)),
+		q.NewFieldMatcher("Outputs", txOutputsAccountMatcher(accounts)),
+	)}
+
+	var transactions []Transaction
+	err := wallet.txDB.ReadMatching(0, 0, matchers, false, &transactions)
+	if err != nil {
+		return err
+	}
+
+	for i := range transactions {
+		tx := &transactions[i]
+		renamed := false
+		for _, input := range tx.Inputs {
+			if input.AccountNumber == accountNumber && input.AccountName != newName {
+				input.AccountName = newName
+				renamed = true
+			}
+		}
+		for _, output := range tx.Outputs {
+			if output.AccountNumber == accountNumber && output.AccountName != newName {
+				output.AccountName = newName
+				renamed = true
+			}
+		}
+		for _, debit := range tx.AccountDebits {
+			if debit.AccountNumber == accountNumber && debit.AccountName != newName {
+				debit.AccountName = newName
+				renamed = true
+			}
+		}
+		for _, delta := range tx.AccountDeltas {
+			if delta.AccountNumber == accountNumber && delta.AccountName != newName {
+				delta.AccountName = newName
+				renamed = true
+			}
+		}
+
+		if renamed {
+			_, err = wallet.txDB.SaveOrUpdate(&Transaction{}, tx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -175,6 +345,41 @@ func (wallet *Wallet) AccountNumber(accountName string) (uint32, error) {
 	return wallet.internal.AccountNumber(wallet.shutdownContext(), accountName)
 }
 
+// DefaultAccount returns the number of the account used by send and ticket
+// purchase operations when no account is specified. Account 0 is the default
+// account if no default account has been set.
+func (wallet *Wallet) DefaultAccount() int32 {
+	return wallet.ReadInt32ConfigValueForKey(DefaultAccountConfigKey, 0)
+}
+
+// SetDefaultAccount sets the account used by send and ticket purchase
+// operations when no account is specified.
+func (wallet *Wallet) SetDefaultAccount(accountNumber int32) error {
+	if accountNumber < 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	_, err := wallet.internal.AccountProperties(wallet.shutdownContext(), uint32(accountNumber))
+	if err != nil {
+		return translateError(err)
+	}
+
+	wallet.SaveUserConfigValue(DefaultAccountConfigKey, accountNumber)
+	return nil
+}
+
+// AccountXPub returns the extended public key of the specified account.
+// Only the public passphrase is required to read the xpub, which has already
+// been provided when the wallet was opened.
+func (wallet *Wallet) AccountXPub(accountNumber int32) (string, error) {
+	extendedPublicKey, err := wallet.internal.MasterPubKey(wallet.shutdownContext(), uint32(accountNumber))
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return extendedPublicKey.String(), nil
+}
+
 func (wallet *Wallet) HDPathForAccount(accountNumber int32) (string, error) {
 	cointype, err := wallet.internal.CoinType(wallet.shutdownContext())
 	if err != nil {
@@ -199,3 +404,44 @@ func (wallet *Wallet) HDPathForAccount(accountNumber int32) (string, error) {
 
 	return hdPath + strconv.Itoa(int(accountNumber)), nil
 }
+
+// CoinType returns the BIP0044 coin type used to derive this wallet's accounts.
+func (wallet *Wallet) CoinType() (int32, error) {
+	coinType, err := wallet.internal.CoinType(wallet.shutdownContext())
+	if err != nil {
+		return -1, translateError(err)
+	}
+
+	return int32(coinType), nil
+}
+
+// UsesLegacyCoinType returns true if this wallet derives its accounts using
+// the legacy coin type instead of the SLIP0044 registered coin type.
+func (wallet *Wallet) UsesLegacyCoinType() (bool, error) {
+	coinType, err := wallet.internal.CoinType(wallet.shutdownContext())
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return coinType == wallet.chainParams.LegacyCoinType, nil
+}
+
+// UpgradeToSLIP0044CoinType changes the coin type used to derive this wallet's
+// accounts from the legacy coin type to the SLIP0044 coin type. The upgrade is
+// only possible if no address of the legacy coin type has been used.
+// Wallets are created and restored with the legacy coin type unless another
+// coin type is passed to `CreateNewWalletWithCoinType` or
+// `RestoreWalletWithCoinType`.
+func (wallet *Wallet) UpgradeToSLIP0044CoinType() error {
+	if wallet.IsWatchingOnlyWallet() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
+	err := wallet.internal.UpgradeToSLIP0044CoinType(wallet.shutdownContext())
+	if err != nil {
+		return translateError(err)
+	}
+
+	log.Infof("[%d] Upgraded to SLIP0044 coin type", wallet.ID)
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"github.com/decred/dcrd/dcrec"
 	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/hdkeychain/v2"
 	"github.com/decred/dcrwallet/errors/v2"
 	w "github.com/decred/dcrwallet/wallet/v3"
 	"github.com/decred/dcrwallet/wallet/v3/udb"
@@ -12,11 +14,15 @@ import (
 
 // AddressInfo holds information about an address
 // If the address belongs to the querying wallet, IsMine will be true and the AccountNumber and AccountName values will be populated
+// Branch and Index are also populated for addresses derived from the account's xpub.
 type AddressInfo struct {
 	Address       string
 	IsMine        bool
+	WalletID      int
 	AccountNumber uint32
 	AccountName   string
+	Branch        uint32
+	Index         uint32
 }
 
 func (wallet *Wallet) IsAddressValid(address string) bool {
@@ -62,13 +68,52 @@ func (wallet *Wallet) AddressInfo(address string) (*AddressInfo, error) {
 	info, _ := wallet.internal.AddressInfo(wallet.shutdownContext(), addr)
 	if info != nil {
 		addressInfo.IsMine = true
+		addressInfo.WalletID = wallet.ID
 		addressInfo.AccountNumber = info.Account()
 		addressInfo.AccountName = wallet.AccountName(int32(info.Account()))
+
+		if pubKeyAddr, ok := info.(udb.ManagedPubKeyAddress); ok {
+			addressInfo.Branch = pubKeyAddr.Branch()
+			addressInfo.Index = pubKeyAddr.Index()
+		}
 	}
 
 	return addressInfo, nil
 }
 
+// AddressInfo returns information about `address`, including the wallet,
+// account, branch and index of the address if it belongs to any of the opened
+// wallets. IsMine is false if the address does not belong to any opened
+// wallet.
+func (mw *MultiWallet) AddressInfo(address string) (*AddressInfo, error) {
+	addr, err := dcrutil.DecodeAddress(address, mw.chainParams)
+	if err != nil {
+		log.Error(err)
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		have, err := wallet.internal.HaveAddress(wallet.shutdownContext(), addr)
+		if err != nil || !have {
+			continue
+		}
+
+		return wallet.AddressInfo(address)
+	}
+
+	return &AddressInfo{Address: address}, nil
+}
+
+// HaveAddress returns true if `address` belongs to any of the opened wallets.
+func (mw *MultiWallet) HaveAddress(address string) bool {
+	addressInfo, err := mw.AddressInfo(address)
+	return err == nil && addressInfo.IsMine
+}
+
 func (wallet *Wallet) CurrentAddress(account int32) (string, error) {
 	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
 		return "", errors.E(ErrAddressDiscoveryNotDone)
@@ -122,3 +167,100 @@ func (wallet *Wallet) AddressPubKey(address string) (string, error) {
 		return "", fmt.Errorf("address is not a managed pub key address")
 	}
 }
+
+// CurrentReceiveAddress returns the most recently handed out receive address
+// of the account if the address has not received any funds. Otherwise, a new
+// receive address is handed out as with `NextReceiveAddress`.
+func (wallet *Wallet) CurrentReceiveAddress(account int32) (string, error) {
+	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
+		return "", errors.E(ErrAddressDiscoveryNotDone)
+	}
+
+	ctx := wallet.shutdownContext()
+	addr, err := wallet.internal.CurrentAddress(uint32(account))
+	if err != nil {
+		log.Error(err)
+		return "", translateError(err)
+	}
+
+	addrInfo, err := wallet.internal.AddressInfo(ctx, addr)
+	if err != nil {
+		log.Error(err)
+		return "", translateError(err)
+	}
+
+	props, err := wallet.internal.AccountProperties(ctx, uint32(account))
+	if err != nil {
+		log.Error(err)
+		return "", translateError(err)
+	}
+
+	// the last used index is ^uint32(0) if no address of the account has
+	// been used.
+	pubKeyAddr, ok := addrInfo.(udb.ManagedPubKeyAddress)
+	if ok && props.LastUsedExternalIndex != ^uint32(0) && pubKeyAddr.Index() <= props.LastUsedExternalIndex {
+		return wallet.NextReceiveAddress(account)
+	}
+
+	return addr.Address(), nil
+}
+
+// NextReceiveAddress hands out a new receive address of the account. The
+// wallet records the address as handed out so it is watched for transactions.
+// Addresses are handed out within the wallet's gap limit; previously handed
+// out unused addresses are returned again once the gap limit is reached, so
+// that address discovery during restore always finds funds sent to them.
+func (wallet *Wallet) NextReceiveAddress(account int32) (string, error) {
+	if wallet.IsRestored && !wallet.HasDiscoveredAccounts {
+		return "", errors.E(ErrAddressDiscoveryNotDone)
+	}
+
+	addr, err := wallet.internal.NewExternalAddress(wallet.shutdownContext(), uint32(account), w.WithGapPolicyWrap())
+	if err != nil {
+		log.Error(err)
+		return "", translateError(err)
+	}
+
+	return addr.Address(), nil
+}
+
+// AddressAtIndex derives the address at `index` of `branch` of the specified
+// account, where branch 0 holds the receive (external) addresses and branch 1
+// holds the change (internal) addresses. The address is derived from the
+// account xpub and is not handed out or watched by the wallet.
+func (wallet *Wallet) AddressAtIndex(account int32, branch, index uint32) (string, error) {
+	if branch != udb.ExternalBranch && branch != udb.InternalBranch {
+		return "", errors.New(ErrInvalid)
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return "", errors.New(ErrInvalid)
+	}
+
+	accountXPub, err := wallet.internal.MasterPubKey(wallet.shutdownContext(), uint32(account))
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	branchXPub, err := accountXPub.Child(branch)
+	if err != nil {
+		return "", err
+	}
+
+	addressXPub, err := branchXPub.Child(index)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, err := addressXPub.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+
+	addr, err := dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(pubKey.SerializeCompressed()), wallet.chainParams,
+		dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return "", err
+	}
+
+	return addr.Address(), nil
+}
@@ -0,0 +1,63 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+)
+
+// DestinationAddressReuse describes a send destination whose address has
+// received funds before. IsOwnAddress is true if the address belongs to the
+// source wallet, otherwise the address is a counterparty address that this
+// wallet has paid before. PreviousReceiveCount is the number of transaction
+// outputs that paid to the address, only counting outputs of transactions
+// sent by this wallet for counterparty addresses.
+type DestinationAddressReuse struct {
+	DestinationIndex     int    `json:"destination_index"`
+	Address              string `json:"address"`
+	IsOwnAddress         bool   `json:"is_own_address"`
+	PreviousReceiveCount int32  `json:"previous_receive_count"`
+}
+
+// AddressReuse returns the json-encoded list of send destinations whose
+// addresses have received funds before.
+func (tx *TxAuthor) AddressReuse() (string, error) {
+	addressReuse, err := tx.AddressReuseRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedAddressReuse, err := json.Marshal(addressReuse)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedAddressReuse), nil
+}
+
+// AddressReuseRaw returns the send destinations whose addresses are used
+// addresses of the source wallet or counterparty addresses that the source
+// wallet has paid before, according to the wallet's indexed transactions.
+// The indexed transactions are scanned on every call, so the check is meant
+// to be run once before sending rather than with every fee estimate.
+func (tx *TxAuthor) AddressReuseRaw() ([]*DestinationAddressReuse, error) {
+	usages, err := tx.sourceWallet.addressUsages()
+	if err != nil {
+		return nil, err
+	}
+
+	addressReuse := make([]*DestinationAddressReuse, 0)
+	for i, destination := range tx.destinations {
+		usage, ok := usages[destination.Address]
+		if !ok {
+			continue
+		}
+
+		addressReuse = append(addressReuse, &DestinationAddressReuse{
+			DestinationIndex:     i,
+			Address:              destination.Address,
+			IsOwnAddress:         usage.isMine,
+			PreviousReceiveCount: usage.receiveCount,
+		})
+	}
+
+	return addressReuse, nil
+}
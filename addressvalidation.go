@@ -0,0 +1,111 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+)
+
+// Address types returned by `ValidateAddress`.
+const (
+	AddressTypeP2PKH        = "P2PKH"
+	AddressTypeP2PKHEd25519 = "P2PKH-Ed25519"
+	AddressTypeP2PKHSchnorr = "P2PKH-Schnorr"
+	AddressTypeP2SH         = "P2SH"
+	AddressTypeP2PK         = "P2PK"
+	AddressTypeP2PKEd25519  = "P2PK-Ed25519"
+	AddressTypeP2PKSchnorr  = "P2PK-Schnorr"
+)
+
+// AddressValidation describes an address. An address that is valid for a
+// different network than the wallet's has IsValid false and the name of the
+// address' network as Network. Network is empty if the address could not be
+// decoded for any network.
+type AddressValidation struct {
+	Address       string `json:"address"`
+	IsValid       bool   `json:"is_valid"`
+	Network       string `json:"network"`
+	Type          string `json:"type"`
+	ScriptVersion uint16 `json:"script_version"`
+	IsMine        bool   `json:"is_mine"`
+}
+
+// addressNetworks are the networks checked when an address is not valid for
+// the wallet's network.
+var addressNetworks = []*chaincfg.Params{
+	chaincfg.MainNetParams(),
+	chaincfg.TestNet3Params(),
+	chaincfg.SimNetParams(),
+	chaincfg.RegNetParams(),
+}
+
+// ValidateAddress returns the json-encoded `AddressValidation` of `address`.
+func (wallet *Wallet) ValidateAddress(address string) (string, error) {
+	validation := wallet.ValidateAddressRaw(address)
+	jsonEncodedValidation, err := json.Marshal(validation)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedValidation), nil
+}
+
+func (wallet *Wallet) ValidateAddressRaw(address string) *AddressValidation {
+	validation := &AddressValidation{
+		Address: address,
+	}
+
+	addr, err := dcrutil.DecodeAddress(address, wallet.chainParams)
+	if err != nil {
+		for _, params := range addressNetworks {
+			if params.Net == wallet.chainParams.Net {
+				continue
+			}
+			if otherNetAddr, err := dcrutil.DecodeAddress(address, params); err == nil {
+				validation.Network = params.Name
+				validation.Type = addressType(otherNetAddr)
+				break
+			}
+		}
+		return validation
+	}
+
+	validation.IsValid = true
+	validation.Network = wallet.chainParams.Name
+	validation.Type = addressType(addr)
+
+	// all address types currently pay to version 0 scripts.
+	validation.ScriptVersion = 0
+
+	if wallet.WalletOpened() {
+		validation.IsMine, _ = wallet.internal.HaveAddress(wallet.shutdownContext(), addr)
+	}
+
+	return validation
+}
+
+func addressType(addr dcrutil.Address) string {
+	switch a := addr.(type) {
+	case *dcrutil.AddressPubKeyHash:
+		switch a.DSA() {
+		case dcrec.STEd25519:
+			return AddressTypeP2PKHEd25519
+		case dcrec.STSchnorrSecp256k1:
+			return AddressTypeP2PKHSchnorr
+		default:
+			return AddressTypeP2PKH
+		}
+	case *dcrutil.AddressScriptHash:
+		return AddressTypeP2SH
+	case *dcrutil.AddressSecpPubKey:
+		return AddressTypeP2PK
+	case *dcrutil.AddressEdwardsPubKey:
+		return AddressTypeP2PKEd25519
+	case *dcrutil.AddressSecSchnorrPubKey:
+		return AddressTypeP2PKSchnorr
+	default:
+		return ""
+	}
+}
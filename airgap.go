@@ -0,0 +1,284 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/wallet/v3/txauthor"
+	"github.com/decred/dcrwallet/wallet/v3/udb"
+)
+
+// UnsignedTxPackageVersion is the version of the unsigned transaction
+// packages created by this library.
+const UnsignedTxPackageVersion = 1
+
+// UnsignedTxPackage is an unsigned transaction with the details an offline
+// signer needs to sign it: the amount and script of each previous output and
+// the derivation path of the key that signs each input. Packages are encoded
+// as base64 of the json-encoded package, suitable for QR codes. Signer is the
+// fingerprint of the offline signer account of a paired wallet, see
+// `PairingPayload`.
+type UnsignedTxPackage struct {
+	Version     int32                     `json:"version"`
+	Network     string                    `json:"network"`
+	Signer      string                    `json:"signer,omitempty"`
+	Tx          string                    `json:"tx"`
+	ChangeIndex int32                     `json:"change_index"`
+	Inputs      []*UnsignedTxPackageInput `json:"inputs"`
+}
+
+// UnsignedTxPackageInput describes the previous output spent by an input of
+// an unsigned transaction package. Path is the BIP0044 derivation path of the
+// key that signs the input, empty if the address is not derived from the
+// wallet seed (e.g. imported addresses).
+type UnsignedTxPackageInput struct {
+	OutPoint string `json:"outpoint"`
+	Amount   int64  `json:"amount"`
+	PkScript string `json:"pk_script"`
+	Address  string `json:"address"`
+	Account  uint32 `json:"account"`
+	Branch   uint32 `json:"branch"`
+	Index    uint32 `json:"index"`
+	Path     string `json:"path"`
+}
+
+// EncodeUnsignedTxPackage returns the base64 encoding of the json-encoded
+// package.
+func EncodeUnsignedTxPackage(pkg *UnsignedTxPackage) (string, error) {
+	jsonEncodedPackage, err := json.Marshal(pkg)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(jsonEncodedPackage), nil
+}
+
+// DecodeUnsignedTxPackage decodes a package encoded with
+// `EncodeUnsignedTxPackage`.
+func DecodeUnsignedTxPackage(encodedPackage string) (*UnsignedTxPackage, error) {
+	jsonEncodedPackage, err := base64.StdEncoding.DecodeString(encodedPackage)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	pkg := &UnsignedTxPackage{}
+	err = json.Unmarshal(jsonEncodedPackage, pkg)
+	if err != nil || pkg.Version != UnsignedTxPackageVersion {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return pkg, nil
+}
+
+// MsgTx returns the unsigned transaction of the package.
+func (pkg *UnsignedTxPackage) MsgTx() (*wire.MsgTx, error) {
+	serializedTx, err := hex.DecodeString(pkg.Tx)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	msgTx := wire.NewMsgTx()
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return msgTx, nil
+}
+
+// ExportUnsignedTransaction constructs this transaction and returns the
+// encoded `UnsignedTxPackage` of the transaction, to be signed by an offline
+// signer. The source wallet may be a watching only wallet.
+func (tx *TxAuthor) ExportUnsignedTransaction() (string, error) {
+	unsignedTx, err := tx.constructTransaction()
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	pkg, err := tx.sourceWallet.unsignedTxPackage(unsignedTx)
+	if err != nil {
+		return "", err
+	}
+
+	return EncodeUnsignedTxPackage(pkg)
+}
+
+// unsignedTxPackage creates the package of `unsignedTx`, whose inputs must
+// spend outputs of the wallet.
+func (wallet *Wallet) unsignedTxPackage(unsignedTx *txauthor.AuthoredTx) (*UnsignedTxPackage, error) {
+	var txBuf bytes.Buffer
+	txBuf.Grow(unsignedTx.Tx.SerializeSize())
+	err := unsignedTx.Tx.Serialize(&txBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	// the inputs of a wallet paired with an offline signer are signed by the
+	// signer account, whose keys are derived with the signer's coin type.
+	signer := wallet.PairedSigner()
+	signerAccount := uint32(wallet.ReadLongConfigValueForKey(PairedAccountConfigKey, 0))
+
+	var coinType uint32
+	if signer != "" {
+		coinType = uint32(wallet.ReadLongConfigValueForKey(PairedCoinTypeConfigKey, 0))
+	} else {
+		coinType, err = wallet.internal.CoinType(wallet.shutdownContext())
+		if err != nil {
+			return nil, translateError(err)
+		}
+	}
+
+	pkg := &UnsignedTxPackage{
+		Version:     UnsignedTxPackageVersion,
+		Network:     wallet.chainParams.Name,
+		Signer:      signer,
+		Tx:          hex.EncodeToString(txBuf.Bytes()),
+		ChangeIndex: int32(unsignedTx.ChangeIndex),
+		Inputs:      make([]*UnsignedTxPackageInput, len(unsignedTx.Tx.TxIn)),
+	}
+
+	for i, txIn := range unsignedTx.Tx.TxIn {
+		pkScript := unsignedTx.PrevScripts[i]
+		input := &UnsignedTxPackageInput{
+			OutPoint: txIn.PreviousOutPoint.String(),
+			Amount:   txIn.ValueIn,
+			PkScript: hex.EncodeToString(pkScript),
+		}
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(0, pkScript, wallet.chainParams)
+		if err == nil && len(addrs) == 1 {
+			input.Address = addrs[0].Address()
+
+			addressInfo, err := wallet.AddressInfo(input.Address)
+			if err == nil && addressInfo.IsMine {
+				input.Account = addressInfo.AccountNumber
+				input.Branch = addressInfo.Branch
+				input.Index = addressInfo.Index
+				if signer != "" && input.Account == 0 {
+					input.Account = signerAccount
+				}
+				if input.Account != udb.ImportedAddrAccount {
+					input.Path = fmt.Sprintf("m/44'/%d'/%d'/%d/%d", coinType,
+						input.Account, input.Branch, input.Index)
+				}
+			}
+		}
+
+		pkg.Inputs[i] = input
+	}
+
+	return pkg, nil
+}
+
+// PublishSignedTransaction publishes `signedTxHex`, a transaction signed by an
+// offline signer, after checking that it is the transaction of the encoded
+// `UnsignedTxPackage` it was signed from, with valid signatures for every
+// input. The package is not trusted: signatures are verified against the
+// scripts of the spent outputs read from the wallet, so every input must
+// spend an output of the wallet. Returns the hash of the published
+// transaction. The wallet need not be unlocked, so watching only wallets can
+// publish transactions signed offline.
+func (wallet *Wallet) PublishSignedTransaction(encodedPackage, signedTxHex string) ([]byte, error) {
+	pkg, err := DecodeUnsignedTxPackage(encodedPackage)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Network != wallet.chainParams.Name {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	unsignedTx, err := pkg.MsgTx()
+	if err != nil {
+		return nil, err
+	}
+
+	serializedTx, err := hex.DecodeString(signedTxHex)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	signedTx := wire.NewMsgTx()
+	err = signedTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if len(pkg.Inputs) != len(unsignedTx.TxIn) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	pkScripts := make([][]byte, len(unsignedTx.TxIn))
+	for i, txIn := range unsignedTx.TxIn {
+		pkScripts[i] = wallet.prevOutputScript(&txIn.PreviousOutPoint)
+		if pkScripts[i] == nil {
+			log.Errorf("[%d] Signed transaction spends unknown output %v", wallet.ID, txIn.PreviousOutPoint)
+			return nil, errors.New(ErrInvalid)
+		}
+	}
+
+	err = validateSignedTx(unsignedTx, signedTx, pkScripts)
+	if err != nil {
+		log.Errorf("[%d] Signed transaction does not match the unsigned package: %v", wallet.ID, err)
+		return nil, errors.New(ErrInvalid)
+	}
+
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	txHash, err := wallet.internal.PublishTransaction(wallet.shutdownContext(), signedTx, serializedTx, n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return txHash[:], nil
+}
+
+// validateSignedTx checks that `signedTx` only differs from `unsignedTx` by
+// the signature scripts of its inputs and that every input is validly signed
+// for the script of the output it spends, `pkScripts` in input order.
+func validateSignedTx(unsignedTx, signedTx *wire.MsgTx, pkScripts [][]byte) error {
+	if signedTx.Version != unsignedTx.Version || signedTx.LockTime != unsignedTx.LockTime ||
+		signedTx.Expiry != unsignedTx.Expiry {
+		return fmt.Errorf("transaction header mismatch")
+	}
+
+	if len(signedTx.TxIn) != len(unsignedTx.TxIn) || len(pkScripts) != len(unsignedTx.TxIn) {
+		return fmt.Errorf("input count mismatch")
+	}
+	for i, txIn := range signedTx.TxIn {
+		if txIn.PreviousOutPoint != unsignedTx.TxIn[i].PreviousOutPoint || txIn.Sequence != unsignedTx.TxIn[i].Sequence {
+			return fmt.Errorf("input %d mismatch", i)
+		}
+	}
+
+	if len(signedTx.TxOut) != len(unsignedTx.TxOut) {
+		return fmt.Errorf("output count mismatch")
+	}
+	for i, txOut := range signedTx.TxOut {
+		unsignedTxOut := unsignedTx.TxOut[i]
+		if txOut.Value != unsignedTxOut.Value || txOut.Version != unsignedTxOut.Version ||
+			!bytes.Equal(txOut.PkScript, unsignedTxOut.PkScript) {
+			return fmt.Errorf("output %d mismatch", i)
+		}
+	}
+
+	for i, pkScript := range pkScripts {
+		vm, err := txscript.NewEngine(pkScript, signedTx, i, txscript.StandardVerifyFlags, 0, nil)
+		if err != nil {
+			return err
+		}
+		if err = vm.Execute(); err != nil {
+			return fmt.Errorf("input %d signature: %v", i, err)
+		}
+	}
+
+	return nil
+}
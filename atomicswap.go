@@ -0,0 +1,269 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// Atomic swap contracts are compatible with decred/atomicswap: the recipient
+// redeems the contract output by revealing a 32-byte secret whose SHA256 hash
+// is committed to in the contract, or the contract creator refunds the output
+// once the contract lock time has passed.
+const (
+	atomicSwapSecretSize = 32
+
+	// the initiator's contract is locked for longer than the participant's,
+	// so the participant has time to redeem the initiator's contract after
+	// the initiator reveals the secret.
+	atomicSwapInitiatorLockTime   = 48 * time.Hour
+	atomicSwapParticipantLockTime = 24 * time.Hour
+)
+
+// AtomicSwapContractDetails are the terms of an atomic swap contract, as
+// reported by `AuditAtomicSwapContract`. LockTime is the unix time after
+// which the contract creator can refund the contract output.
+type AtomicSwapContractDetails struct {
+	ContractAddress  string `json:"contract_address"`
+	ContractTxHash   string `json:"contract_tx_hash"`
+	ContractOutPoint string `json:"contract_outpoint"`
+	Amount           int64  `json:"amount"`
+	RecipientAddress string `json:"recipient_address"`
+	RefundAddress    string `json:"refund_address"`
+	SecretHash       string `json:"secret_hash"`
+	LockTime         int64  `json:"lock_time"`
+}
+
+// atomicSwapContractScript returns the atomic swap contract that pays to
+// `recipient` if the secret of `secretHash` is revealed, or to `refund` after
+// `lockTime`.
+func atomicSwapContractScript(recipient, refund *dcrutil.AddressPubKeyHash, lockTime int64, secretHash []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+
+	b.AddOp(txscript.OP_IF) // normal redeem path
+	{
+		// require the initiator's secret to be a known length that the
+		// redeeming party can audit, then check its hash.
+		b.AddOp(txscript.OP_SIZE)
+		b.AddInt64(atomicSwapSecretSize)
+		b.AddOp(txscript.OP_EQUALVERIFY)
+		b.AddOp(txscript.OP_SHA256)
+		b.AddData(secretHash)
+		b.AddOp(txscript.OP_EQUALVERIFY)
+
+		// the recipient's pubkey hash
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(recipient.Hash160()[:])
+	}
+	b.AddOp(txscript.OP_ELSE) // refund path
+	{
+		b.AddInt64(lockTime)
+		b.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+		b.AddOp(txscript.OP_DROP)
+
+		// the refund pubkey hash
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(refund.Hash160()[:])
+	}
+	b.AddOp(txscript.OP_ENDIF)
+
+	// the signature of the recipient or refund pubkey
+	b.AddOp(txscript.OP_EQUALVERIFY)
+	b.AddOp(txscript.OP_CHECKSIG)
+
+	return b.Script()
+}
+
+// atomicSwapPubKeyHashAddress decodes a P2PKH address that may be used in an
+// atomic swap contract.
+func atomicSwapPubKeyHashAddress(address string, chainParams dcrutil.AddressParams) (*dcrutil.AddressPubKeyHash, error) {
+	addr, err := dcrutil.DecodeAddress(address, chainParams)
+	if err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	pkhAddr, ok := addr.(*dcrutil.AddressPubKeyHash)
+	if !ok || pkhAddr.DSA() != dcrec.STEcdsaSecp256k1 {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	return pkhAddr, nil
+}
+
+// auditAtomicSwapContract decodes the hex-encoded contract and the transaction
+// that pays to it, returning the contract, the transaction, the index of the
+// contract output and the terms of the contract.
+func (mw *MultiWallet) auditAtomicSwapContract(contractHex, contractTxHex string) ([]byte, *wire.MsgTx, uint32,
+	*AtomicSwapContractDetails, error) {
+
+	contract, err := hex.DecodeString(contractHex)
+	if err != nil {
+		return nil, nil, 0, nil, errors.New(ErrInvalid)
+	}
+
+	contractTx, err := decodeRawTx(contractTxHex)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
+	pushes, err := txscript.ExtractAtomicSwapDataPushes(0, contract)
+	if err != nil || pushes == nil || pushes.SecretSize != atomicSwapSecretSize {
+		return nil, nil, 0, nil, errors.New(ErrInvalid)
+	}
+
+	contractAddr, err := dcrutil.NewAddressScriptHash(contract, mw.chainParams)
+	if err != nil {
+		return nil, nil, 0, nil, errors.New(ErrInvalid)
+	}
+	contractPkScript, err := txscript.PayToAddrScript(contractAddr)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
+	contractOutputIndex := -1
+	for i, txOut := range contractTx.TxOut {
+		if bytes.Equal(txOut.PkScript, contractPkScript) {
+			contractOutputIndex = i
+			break
+		}
+	}
+	if contractOutputIndex == -1 {
+		return nil, nil, 0, nil, errors.New(ErrNotExist)
+	}
+
+	recipientAddr, err := dcrutil.NewAddressPubKeyHash(pushes.RecipientHash160[:], mw.chainParams, dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return nil, nil, 0, nil, errors.New(ErrInvalid)
+	}
+	refundAddr, err := dcrutil.NewAddressPubKeyHash(pushes.RefundHash160[:], mw.chainParams, dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return nil, nil, 0, nil, errors.New(ErrInvalid)
+	}
+
+	contractTxHash := contractTx.TxHash()
+	outPoint := wire.NewOutPoint(&contractTxHash, uint32(contractOutputIndex), wire.TxTreeRegular)
+
+	details := &AtomicSwapContractDetails{
+		ContractAddress:  contractAddr.Address(),
+		ContractTxHash:   contractTxHash.String(),
+		ContractOutPoint: outPoint.String(),
+		Amount:           contractTx.TxOut[contractOutputIndex].Value,
+		RecipientAddress: recipientAddr.Address(),
+		RefundAddress:    refundAddr.Address(),
+		SecretHash:       hex.EncodeToString(pushes.SecretHash[:]),
+		LockTime:         pushes.LockTime,
+	}
+
+	return contract, contractTx, uint32(contractOutputIndex), details, nil
+}
+
+// AuditAtomicSwapContract checks that the hex-encoded contract transaction
+// pays to the hex-encoded atomic swap contract and returns the json-encoded
+// `AtomicSwapContractDetails` of the contract, so the terms offered by the
+// counterparty can be verified before funding or redeeming a swap.
+func (mw *MultiWallet) AuditAtomicSwapContract(contractHex, contractTxHex string) (string, error) {
+	details, err := mw.AuditAtomicSwapContractRaw(contractHex, contractTxHex)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedDetails, err := json.Marshal(details)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedDetails), nil
+}
+
+func (mw *MultiWallet) AuditAtomicSwapContractRaw(contractHex, contractTxHex string) (*AtomicSwapContractDetails, error) {
+	_, _, _, details, err := mw.auditAtomicSwapContract(contractHex, contractTxHex)
+	return details, err
+}
+
+// ExtractAtomicSwapSecret returns the hex-encoded secret revealed by the
+// hex-encoded transaction that redeemed an atomic swap contract with the
+// hex-encoded `secretHash`. Returns `ErrNotExist` if the transaction does not
+// reveal the secret.
+func ExtractAtomicSwapSecret(redemptionTxHex, secretHashHex string) (string, error) {
+	redemptionTx, err := decodeRawTx(redemptionTxHex)
+	if err != nil {
+		return "", err
+	}
+
+	secretHash, err := hex.DecodeString(secretHashHex)
+	if err != nil || len(secretHash) != sha256.Size {
+		return "", errors.New(ErrInvalid)
+	}
+
+	for _, txIn := range redemptionTx.TxIn {
+		pushes, err := txscript.PushedData(txIn.SignatureScript)
+		if err != nil {
+			continue
+		}
+		for _, push := range pushes {
+			if len(push) != atomicSwapSecretSize {
+				continue
+			}
+			hash := sha256.Sum256(push)
+			if bytes.Equal(hash[:], secretHash) {
+				return hex.EncodeToString(push), nil
+			}
+		}
+	}
+
+	return "", errors.New(ErrNotExist)
+}
+
+// atomicSwapRedeemSigScript returns the signature script that redeems an
+// atomic swap contract by revealing `secret`.
+func atomicSwapRedeemSigScript(contract, sig, pubKey, secret []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubKey)
+	b.AddData(secret)
+	b.AddInt64(1)
+	b.AddData(contract)
+	return b.Script()
+}
+
+// atomicSwapRefundSigScript returns the signature script that refunds an
+// atomic swap contract after its lock time.
+func atomicSwapRefundSigScript(contract, sig, pubKey []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubKey)
+	b.AddInt64(0)
+	b.AddData(contract)
+	return b.Script()
+}
+
+// atomicSwapSigScriptSize returns the worst case size of the signature script
+// that redeems (with the secret) or refunds `contract`.
+func atomicSwapSigScriptSize(contract []byte, redeem bool) int {
+	// signature, compressed pubkey and the branch opcode
+	size := 1 + 73 + 1 + 33 + 1
+	if redeem {
+		size += 1 + atomicSwapSecretSize
+	}
+
+	// the contract push
+	switch {
+	case len(contract) < txscript.OP_PUSHDATA1:
+		size++
+	case len(contract) <= 0xff:
+		size += 2
+	default:
+		size += 3
+	}
+	return size + len(contract)
+}
@@ -0,0 +1,193 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+func testPubKeyHashAddress(t *testing.T, params *chaincfg.Params, b byte) *dcrutil.AddressPubKeyHash {
+	t.Helper()
+
+	address, err := dcrutil.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20), params, dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func testRawTx(t *testing.T, msgTx *wire.MsgTx) string {
+	t.Helper()
+
+	serializedTx, err := msgTx.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(serializedTx)
+}
+
+func TestAuditAtomicSwapContract(t *testing.T) {
+	params := chaincfg.TestNet3Params()
+	mw := &MultiWallet{chainParams: params}
+
+	recipient := testPubKeyHashAddress(t, params, 1)
+	refund := testPubKeyHashAddress(t, params, 2)
+	secretHash := sha256.Sum256(bytes.Repeat([]byte{3}, atomicSwapSecretSize))
+	const lockTime = 1577836800
+
+	contract, err := atomicSwapContractScript(recipient, refund, lockTime, secretHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddr, err := dcrutil.NewAddressScriptHash(contract, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractPkScript, err := txscript.PayToAddrScript(contractAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundPkScript, err := txscript.PayToAddrScript(refund)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contractTx := wire.NewMsgTx()
+	contractTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, 0, nil))
+	contractTx.AddTxOut(wire.NewTxOut(2e8, refundPkScript))
+	contractTx.AddTxOut(wire.NewTxOut(1e8, contractPkScript))
+
+	otherTx := wire.NewMsgTx()
+	otherTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, 0, nil))
+	otherTx.AddTxOut(wire.NewTxOut(1e8, refundPkScript))
+
+	tests := []struct {
+		name          string
+		contractHex   string
+		contractTxHex string
+		wantErr       string
+	}{
+		{
+			name:          "contract paid by the transaction",
+			contractHex:   hex.EncodeToString(contract),
+			contractTxHex: testRawTx(t, contractTx),
+		},
+		{
+			name:          "contract not paid by the transaction",
+			contractHex:   hex.EncodeToString(contract),
+			contractTxHex: testRawTx(t, otherTx),
+			wantErr:       ErrNotExist,
+		},
+		{
+			name:          "script is not a contract",
+			contractHex:   hex.EncodeToString(refundPkScript),
+			contractTxHex: testRawTx(t, contractTx),
+			wantErr:       ErrInvalid,
+		},
+		{
+			name:          "contract is not hex",
+			contractHex:   "contract",
+			contractTxHex: testRawTx(t, contractTx),
+			wantErr:       ErrInvalid,
+		},
+		{
+			name:          "transaction is not hex",
+			contractHex:   hex.EncodeToString(contract),
+			contractTxHex: "transaction",
+			wantErr:       ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		details, err := mw.AuditAtomicSwapContractRaw(test.contractHex, test.contractTxHex)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Fatalf("%s: error %v, want %s", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		contractTxHash := contractTx.TxHash()
+		want := AtomicSwapContractDetails{
+			ContractAddress:  contractAddr.Address(),
+			ContractTxHash:   contractTxHash.String(),
+			ContractOutPoint: wire.NewOutPoint(&contractTxHash, 1, wire.TxTreeRegular).String(),
+			Amount:           1e8,
+			RecipientAddress: recipient.Address(),
+			RefundAddress:    refund.Address(),
+			SecretHash:       hex.EncodeToString(secretHash[:]),
+			LockTime:         lockTime,
+		}
+		if *details != want {
+			t.Fatalf("%s: details %+v, want %+v", test.name, *details, want)
+		}
+	}
+}
+
+func TestExtractAtomicSwapSecret(t *testing.T) {
+	params := chaincfg.TestNet3Params()
+	secret := bytes.Repeat([]byte{3}, atomicSwapSecretSize)
+	secretHash := sha256.Sum256(secret)
+
+	contract, err := atomicSwapContractScript(testPubKeyHashAddress(t, params, 1),
+		testPubKeyHashAddress(t, params, 2), 1577836800, secretHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := bytes.Repeat([]byte{4}, 72)
+	pubKey := bytes.Repeat([]byte{5}, 33)
+	redeemSigScript, err := atomicSwapRedeemSigScript(contract, sig, pubKey, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundSigScript, err := atomicSwapRefundSigScript(contract, sig, pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		sigScript     []byte
+		secretHashHex string
+		wantErr       string
+	}{
+		{name: "redemption", sigScript: redeemSigScript, secretHashHex: hex.EncodeToString(secretHash[:])},
+		{name: "refund", sigScript: refundSigScript, secretHashHex: hex.EncodeToString(secretHash[:]),
+			wantErr: ErrNotExist},
+		{name: "other secret hash", sigScript: redeemSigScript, secretHashHex: hex.EncodeToString(make([]byte, 32)),
+			wantErr: ErrNotExist},
+		{name: "short secret hash", sigScript: redeemSigScript, secretHashHex: hex.EncodeToString(secretHash[:16]),
+			wantErr: ErrInvalid},
+	}
+
+	for _, test := range tests {
+		redemptionTx := wire.NewMsgTx()
+		redemptionTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, 0, test.sigScript))
+		redemptionTx.AddTxOut(wire.NewTxOut(1e8, []byte{txscript.OP_TRUE}))
+
+		secretHex, err := ExtractAtomicSwapSecret(testRawTx(t, redemptionTx), test.secretHashHex)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Fatalf("%s: error %v, want %s", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if secretHex != hex.EncodeToString(secret) {
+			t.Fatalf("%s: secret %s, want %x", test.name, secretHex, secret)
+		}
+	}
+}
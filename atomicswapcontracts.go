@@ -0,0 +1,595 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/decred/dcrwallet/wallet/v3/txrules"
+	"github.com/decred/dcrwallet/wallet/v3/udb"
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// Roles of a wallet in an atomic swap.
+const (
+	AtomicSwapRoleInitiator   = "initiator"
+	AtomicSwapRoleParticipant = "participant"
+)
+
+// Statuses of an atomic swap.
+const (
+	AtomicSwapStatusFunded   = "funded"
+	AtomicSwapStatusRedeemed = "redeemed"
+	AtomicSwapStatusRefunded = "refunded"
+)
+
+// atomicSwapSecretMessage is the message signed by the key of the refund
+// address of an atomic swap initiated by a wallet to derive the swap secret.
+const atomicSwapSecretMessage = "dcrlibwallet atomic swap secret"
+
+// Actions of the wallet transactions of an atomic swap, reported in the
+// AtomicSwapAction field of transactions.
+const (
+	AtomicSwapActionFund   = "fund"
+	AtomicSwapActionRedeem = "redeem"
+	AtomicSwapActionRefund = "refund"
+)
+
+// AtomicSwap is an atomic swap that a wallet initiated or participated in.
+// Contract is the hex-encoded contract funded by the wallet, the wallet can
+// refund the contract after LockTime if the counterparty does not redeem it.
+// RedeemTxHash is the hash of the transaction in which the wallet redeemed
+// the counterparty's contract. Secret is empty until the secret is revealed
+// by a redemption: the initiator's secret is derived from the key of the
+// refund address rather than stored, it is returned by `AtomicSwapSecret`.
+type AtomicSwap struct {
+	ID               int       `storm:"id,increment" json:"id"`
+	WalletID         int       `storm:"index" json:"wallet_id"`
+	Role             string    `json:"role"`
+	Status           string    `storm:"index" json:"status"`
+	Contract         string    `json:"contract"`
+	ContractAddress  string    `json:"contract_address"`
+	ContractTx       string    `json:"contract_tx"`
+	ContractTxHash   string    `storm:"index" json:"contract_tx_hash"`
+	Amount           int64     `json:"amount"`
+	RecipientAddress string    `json:"recipient_address"`
+	RefundAddress    string    `json:"refund_address"`
+	SecretHash       string    `storm:"index" json:"secret_hash"`
+	Secret           string    `json:"secret"`
+	LockTime         int64     `json:"lock_time"`
+	RedeemTxHash     string    `storm:"index" json:"redeem_tx_hash"`
+	RefundTxHash     string    `storm:"index" json:"refund_tx_hash"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// InitiateAtomicSwap generates a secret and funds an atomic swap contract that
+// pays `amount` from `account` to `participantAddress` if the secret is
+// revealed, or refunds the wallet after 48 hours. Returns the json-encoded
+// `AtomicSwap`, whose contract and contract transaction are shared with the
+// participant. The secret, returned by `AtomicSwapSecret`, must not be shared.
+func (mw *MultiWallet) InitiateAtomicSwap(walletID int, account int32, participantAddress string, amount int64,
+	privPass []byte) (string, error) {
+
+	// the secret is derived once the refund address is known
+	swap := &AtomicSwap{
+		Role:     AtomicSwapRoleInitiator,
+		LockTime: time.Now().Add(atomicSwapInitiatorLockTime).Unix(),
+	}
+
+	return mw.fundAtomicSwap(walletID, account, swap, participantAddress, amount, privPass)
+}
+
+// ParticipateAtomicSwap funds an atomic swap contract that pays `amount` from
+// `account` to `initiatorAddress` if the secret of the hex-encoded
+// `secretHash` from the initiator's contract is revealed, or refunds the
+// wallet after 24 hours. The initiator's contract should be audited with
+// `AuditAtomicSwapContract` first. Returns the json-encoded `AtomicSwap`.
+func (mw *MultiWallet) ParticipateAtomicSwap(walletID int, account int32, initiatorAddress string, amount int64,
+	secretHash string, privPass []byte) (string, error) {
+
+	hash, err := hex.DecodeString(secretHash)
+	if err != nil || len(hash) != sha256.Size {
+		return "", errors.New(ErrInvalid)
+	}
+
+	swap := &AtomicSwap{
+		Role:       AtomicSwapRoleParticipant,
+		SecretHash: hex.EncodeToString(hash),
+		LockTime:   time.Now().Add(atomicSwapParticipantLockTime).Unix(),
+	}
+
+	return mw.fundAtomicSwap(walletID, account, swap, initiatorAddress, amount, privPass)
+}
+
+// fundAtomicSwap creates the contract of `swap` paying to `recipientAddress`,
+// sends `amount` to the contract and saves the swap.
+func (mw *MultiWallet) fundAtomicSwap(walletID int, account int32, swap *AtomicSwap, recipientAddress string,
+	amount int64, privPass []byte) (string, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	if amount <= 0 || amount > MaxAmountAtom {
+		return "", errors.New(ErrInvalidAmount)
+	}
+
+	recipientAddr, err := atomicSwapPubKeyHashAddress(recipientAddress, mw.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := wallet.shutdownContext()
+	refundAddr, err := wallet.internal.NewInternalAddress(ctx, uint32(account), w.WithGapPolicyWrap())
+	if err != nil {
+		return "", translateError(err)
+	}
+	refundPkhAddr, err := atomicSwapPubKeyHashAddress(refundAddr.Address(), mw.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	if swap.Role == AtomicSwapRoleInitiator {
+		secret, err := wallet.atomicSwapSecret(refundPkhAddr, privPass)
+		if err != nil {
+			return "", err
+		}
+		secretHash := sha256.Sum256(secret)
+		swap.SecretHash = hex.EncodeToString(secretHash[:])
+	}
+
+	secretHash, _ := hex.DecodeString(swap.SecretHash)
+	contract, err := atomicSwapContractScript(recipientAddr, refundPkhAddr, swap.LockTime, secretHash)
+	if err != nil {
+		return "", err
+	}
+	contractAddr, err := dcrutil.NewAddressScriptHash(contract, mw.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	tx := mw.NewUnsignedTx(wallet, account)
+	tx.AddSendDestination(contractAddr.Address(), amount, false)
+
+	txHash, err := tx.Broadcast(privPass)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		return "", err
+	}
+	txDetails, err := wallet.internal.TxDetails(ctx, hash)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(txDetails.MsgTx.SerializeSize())
+	err = txDetails.MsgTx.Serialize(&txBuf)
+	if err != nil {
+		return "", err
+	}
+
+	swap.WalletID = walletID
+	swap.Status = AtomicSwapStatusFunded
+	swap.Contract = hex.EncodeToString(contract)
+	swap.ContractAddress = contractAddr.Address()
+	swap.ContractTx = hex.EncodeToString(txBuf.Bytes())
+	swap.ContractTxHash = hash.String()
+	swap.Amount = amount
+	swap.RecipientAddress = recipientAddr.Address()
+	swap.RefundAddress = refundPkhAddr.Address()
+	swap.CreatedAt = time.Now()
+
+	err = mw.db.Save(swap)
+	if err != nil {
+		log.Errorf("[%d] Error saving atomic swap with contract tx %s: %v", walletID, swap.ContractTxHash, err)
+		return "", err
+	}
+
+	log.Infof("[%d] Funded atomic swap contract %s as %s", walletID, swap.ContractAddress, swap.Role)
+
+	jsonEncodedSwap, err := json.Marshal(swap)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedSwap), nil
+}
+
+// atomicSwapSecret returns the secret of the atomic swaps initiated by the
+// wallet with the refund address `refundAddr`. The secret is derived from
+// the deterministic signature of a fixed message by the key of the refund
+// address, so it is never stored and only known to the holder of the key.
+func (wallet *Wallet) atomicSwapSecret(refundAddr dcrutil.Address, privPass []byte) ([]byte, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		lock <- time.Time{}
+	}()
+
+	ctx := wallet.shutdownContext()
+	err := wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := wallet.internal.SignMessage(ctx, atomicSwapSecretMessage, refundAddr)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	secret := sha256.Sum256(sig)
+	return secret[:], nil
+}
+
+// AtomicSwapSecret returns the hex-encoded secret of the specified atomic
+// swap of the wallet with ID `walletID`. The secret of a swap initiated by
+// the wallet is derived with the wallet's keys, it is needed to redeem the
+// participant's contract and must not be shared before.
+func (mw *MultiWallet) AtomicSwapSecret(walletID, swapID int, privPass []byte) (string, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	swap := &AtomicSwap{}
+	err := mw.db.Select(q.Eq("ID", swapID), q.Eq("WalletID", walletID)).First(swap)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return "", errors.New(ErrNotExist)
+		}
+		return "", err
+	}
+
+	if swap.Secret != "" {
+		return swap.Secret, nil
+	}
+	if swap.Role != AtomicSwapRoleInitiator {
+		return "", errors.New(ErrNotExist)
+	}
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	refundAddr, err := dcrutil.DecodeAddress(swap.RefundAddress, wallet.chainParams)
+	if err != nil {
+		return "", errors.New(ErrInvalidAddress)
+	}
+
+	secret, err := wallet.atomicSwapSecret(refundAddr, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	secretHash := sha256.Sum256(secret)
+	if hex.EncodeToString(secretHash[:]) != swap.SecretHash {
+		log.Errorf("[%d] Derived secret of atomic swap %d does not match its secret hash", walletID, swapID)
+		return "", errors.New(ErrInvalid)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// RedeemAtomicSwap redeems the counterparty's hex-encoded atomic swap contract
+// paid by the hex-encoded contract transaction to a new internal address of
+// the wallet, by revealing the hex-encoded secret. The contract recipient
+// must be an address of the wallet. Returns the hash of the redemption
+// transaction.
+func (mw *MultiWallet) RedeemAtomicSwap(walletID int, contractHex, contractTxHex, secretHex string,
+	privPass []byte) ([]byte, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	contract, contractTx, outputIndex, details, err := mw.auditAtomicSwapContract(contractHex, contractTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := hex.DecodeString(secretHex)
+	if err != nil || len(secret) != atomicSwapSecretSize {
+		return nil, errors.New(ErrInvalid)
+	}
+	secretHash := sha256.Sum256(secret)
+	if hex.EncodeToString(secretHash[:]) != details.SecretHash {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	redeemTxHash, err := wallet.spendAtomicSwapContract(contract, contractTx, outputIndex, details.RecipientAddress,
+		0, secret, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	// record the redemption against this wallet's side of the swap
+	var swaps []AtomicSwap
+	err = mw.db.Select(q.Eq("WalletID", walletID), q.Eq("SecretHash", details.SecretHash)).Find(&swaps)
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error reading atomic swap: %v", walletID, err)
+	}
+	for i := range swaps {
+		swaps[i].Secret = secretHex
+		swaps[i].RedeemTxHash = redeemTxHash.String()
+		if swaps[i].Status == AtomicSwapStatusFunded {
+			swaps[i].Status = AtomicSwapStatusRedeemed
+		}
+		if err = mw.db.Update(&swaps[i]); err != nil {
+			log.Errorf("[%d] Error updating atomic swap %d: %v", walletID, swaps[i].ID, err)
+		}
+	}
+
+	log.Infof("[%d] Redeemed atomic swap contract %s", walletID, details.ContractAddress)
+	return redeemTxHash[:], nil
+}
+
+// RefundAtomicSwap refunds the wallet's hex-encoded atomic swap contract paid
+// by the hex-encoded contract transaction to a new internal address of the
+// wallet. The contract can only be refunded after its lock time. Returns the
+// hash of the refund transaction.
+func (mw *MultiWallet) RefundAtomicSwap(walletID int, contractHex, contractTxHex string, privPass []byte) ([]byte, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	contract, contractTx, outputIndex, details, err := mw.auditAtomicSwapContract(contractHex, contractTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().Unix() < details.LockTime {
+		return nil, errors.New(ErrFailedPrecondition)
+	}
+
+	refundTxHash, err := wallet.spendAtomicSwapContract(contract, contractTx, outputIndex, details.RefundAddress,
+		details.LockTime, nil, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	swap := &AtomicSwap{}
+	err = mw.db.Select(q.Eq("WalletID", walletID), q.Eq("ContractTxHash", details.ContractTxHash)).First(swap)
+	if err == nil {
+		swap.Status = AtomicSwapStatusRefunded
+		swap.RefundTxHash = refundTxHash.String()
+		if err = mw.db.Update(swap); err != nil {
+			log.Errorf("[%d] Error updating atomic swap %d: %v", walletID, swap.ID, err)
+		}
+	} else if err != storm.ErrNotFound {
+		log.Errorf("[%d] Error reading atomic swap: %v", walletID, err)
+	}
+
+	log.Infof("[%d] Refunded atomic swap contract %s", walletID, details.ContractAddress)
+	return refundTxHash[:], nil
+}
+
+// spendAtomicSwapContract publishes a transaction that spends the contract
+// output at `outputIndex` of `contractTx` with the key of `spenderAddress`,
+// revealing `secret` to redeem the contract, or refunding the contract after
+// `lockTime` if `secret` is nil.
+func (wallet *Wallet) spendAtomicSwapContract(contract []byte, contractTx *wire.MsgTx, outputIndex uint32,
+	spenderAddress string, lockTime int64, secret []byte, privPass []byte) (*chainhash.Hash, error) {
+
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
+	addressInfo, err := wallet.AddressInfo(spenderAddress)
+	if err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+	if !addressInfo.IsMine {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	// imported accounts have no internal addresses
+	destinationAccount := addressInfo.AccountNumber
+	if destinationAccount == udb.ImportedAddrAccount {
+		destinationAccount = uint32(wallet.DefaultAccount())
+	}
+
+	ctx := wallet.shutdownContext()
+	destinationAddress, err := wallet.internal.NewInternalAddress(ctx, destinationAccount, w.WithGapPolicyWrap())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	contractOutput := contractTx.TxOut[outputIndex]
+	output, err := txhelper.MakeTxOutput(destinationAddress.Address(), contractOutput.Value, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	contractTxHash := contractTx.TxHash()
+	spendTx := wire.NewMsgTx()
+	txIn := wire.NewTxIn(wire.NewOutPoint(&contractTxHash, outputIndex, wire.TxTreeRegular), contractOutput.Value, nil)
+	redeem := secret != nil
+	if !redeem {
+		// the lock time is only enforced for inputs that are not final
+		spendTx.LockTime = uint32(lockTime)
+		txIn.Sequence = wire.MaxTxInSequenceNum - 1
+	}
+	spendTx.AddTxIn(txIn)
+	spendTx.AddTxOut(output)
+
+	fee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb,
+		spendTx.SerializeSize()+atomicSwapSigScriptSize(contract, redeem))
+	output.Value -= int64(fee)
+	if output.Value <= 0 || txrules.IsDustOutput(output, txrules.DefaultRelayFeePerKb) {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	lock := make(chan time.Time, 1)
+	defer func() {
+		lock <- time.Time{}
+	}()
+
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	spenderAddr, err := dcrutil.DecodeAddress(spenderAddress, wallet.chainParams)
+	if err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+	encodedWIF, err := wallet.internal.DumpWIFPrivateKey(ctx, spenderAddr)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	wif, err := dcrutil.DecodeWIF(encodedWIF, wallet.chainParams.PrivateKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := txscript.RawTxInSignature(spendTx, 0, contract, txscript.SigHashAll, wif.PrivKey(), dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+
+	var sigScript []byte
+	if redeem {
+		sigScript, err = atomicSwapRedeemSigScript(contract, sig, wif.PubKey(), secret)
+	} else {
+		sigScript, err = atomicSwapRefundSigScript(contract, sig, wif.PubKey())
+	}
+	if err != nil {
+		return nil, err
+	}
+	spendTx.TxIn[0].SignatureScript = sigScript
+
+	vm, err := txscript.NewEngine(contractOutput.PkScript, spendTx, 0, txscript.StandardVerifyFlags, 0, nil)
+	if err == nil {
+		err = vm.Execute()
+	}
+	if err != nil {
+		log.Errorf("[%d] Invalid atomic swap contract spend: %v", wallet.ID, err)
+		return nil, errors.New(ErrInvalid)
+	}
+
+	var serializedTx bytes.Buffer
+	serializedTx.Grow(spendTx.SerializeSize())
+	err = spendTx.Serialize(&serializedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := wallet.internal.PublishTransaction(ctx, spendTx, serializedTx.Bytes(), n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return txHash, nil
+}
+
+// AtomicSwaps returns the json-encoded list of the atomic swaps of the wallet
+// with ID `walletID`, newest first.
+func (mw *MultiWallet) AtomicSwaps(walletID int) (string, error) {
+	swaps, err := mw.AtomicSwapsRaw(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedSwaps, err := json.Marshal(swaps)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedSwaps), nil
+}
+
+func (mw *MultiWallet) AtomicSwapsRaw(walletID int) ([]AtomicSwap, error) {
+	swaps := make([]AtomicSwap, 0)
+	err := mw.db.Select(q.Eq("WalletID", walletID)).OrderBy("CreatedAt").Reverse().Find(&swaps)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return swaps, nil
+}
+
+// atomicSwapActionsFn returns the function called to get the actions of the
+// transactions of the atomic swaps of the specified wallet, by transaction
+// hash. The swaps are read once for every list of transactions read.
+func (mw *MultiWallet) atomicSwapActionsFn(walletID int) func() map[string]string {
+	return func() map[string]string {
+		var swaps []AtomicSwap
+		err := mw.db.Find("WalletID", walletID, &swaps)
+		if err != nil {
+			if err != storm.ErrNotFound {
+				log.Errorf("[%d] Error reading atomic swaps: %v", walletID, err)
+			}
+			return nil
+		}
+
+		actions := make(map[string]string, len(swaps))
+		for _, swap := range swaps {
+			actions[swap.ContractTxHash] = AtomicSwapActionFund
+			if swap.RedeemTxHash != "" {
+				actions[swap.RedeemTxHash] = AtomicSwapActionRedeem
+			}
+			if swap.RefundTxHash != "" {
+				actions[swap.RefundTxHash] = AtomicSwapActionRefund
+			}
+		}
+		return actions
+	}
+}
+
+// atomicSwapActions returns the actions of the transactions of the atomic
+// swaps of the wallet by transaction hash, nil if the wallet has no swaps.
+func (wallet *Wallet) atomicSwapActions() map[string]string {
+	if wallet.atomicSwapActionsFn == nil {
+		return nil
+	}
+	return wallet.atomicSwapActionsFn()
+}
+
+// setAtomicSwapAction sets the atomic swap action of `tx`, from the actions
+// returned by `atomicSwapActions`, if the transaction is part of an atomic
+// swap of the wallet.
+func (wallet *Wallet) setAtomicSwapAction(tx *Transaction, swapActions map[string]string) {
+	tx.AtomicSwapAction = swapActions[tx.Hash]
+}
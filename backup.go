@@ -0,0 +1,336 @@
+package dcrlibwallet
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"strconv"
+	"unicode"
+
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/wallet/v3/udb"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// BackupVersion is the current version of the backup blob format.
+	// Increment this version number if the structure of the backup
+	// or the encryption scheme changes.
+	BackupVersion byte = 1
+
+	backupSaltSize  = 16
+	backupNonceSize = 24
+	backupKeySize   = 32
+
+	backupScryptN = 1 << 15
+	backupScryptR = 8
+	backupScryptP = 1
+)
+
+// WalletBackup holds the wallet data that is encrypted into a backup blob.
+type WalletBackup struct {
+	Version           byte                       `json:"version"`
+	Name              string                     `json:"name"`
+	Tag               string                     `json:"tag"`
+	Seed              string                     `json:"seed"`
+	HasSeedPassphrase bool                       `json:"has_seed_passphrase"`
+	Birthday          int64                      `json:"birthday"`
+	Accounts          []*WalletAccount           `json:"accounts"`
+	Config            map[string]json.RawMessage `json:"config"`
+	TxNotes           map[string]string          `json:"tx_notes,omitempty"`
+	TxTags            map[string][]string        `json:"tx_tags,omitempty"`
+	TxFiatRates       []*TxFiatRate              `json:"tx_fiat_rates,omitempty"`
+}
+
+// ExportBackup returns an encrypted, base64-encoded backup of the specified
+// wallet's seed, account names, config values and transaction notes, tags and
+// exchange rates. The backup is encrypted with a key derived from
+// `backupPassphrase`.
+// Since the seed is not stored once it has been verified by the user,
+// `seedMnemonic` must be provided if the wallet's seed has been backed up.
+// The seed passphrase of a wallet is never included in the backup.
+func (mw *MultiWallet) ExportBackup(walletID int, seedMnemonic string, backupPassphrase []byte) (string, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+
+	if seedMnemonic == "" {
+		seedMnemonic = wallet.Seed
+	}
+	if seedMnemonic == "" {
+		return "", errors.New(ErrEmptySeed)
+	}
+	if !VerifySeed(seedMnemonic) {
+		return "", errors.New(ErrInvalid)
+	}
+
+	accounts, err := wallet.GetAllAccountsRaw()
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	backup := &WalletBackup{
+		Version:           BackupVersion,
+		Name:              wallet.Name,
+		Tag:               wallet.Tag,
+		Seed:              seedMnemonic,
+		HasSeedPassphrase: wallet.HasSeedPassphrase,
+		Birthday:          birthdayUnix(wallet.Birthday),
+	}
+
+	for _, account := range accounts.Acc {
+		if uint32(account.Number) == udb.ImportedAddrAccount {
+			continue
+		}
+		backup.Accounts = append(backup.Accounts, &WalletAccount{
+			AccountNumber: account.Number,
+			AccountName:   account.Name,
+		})
+	}
+
+	backup.Config, err = mw.walletConfigValues(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	backup.TxNotes, err = mw.walletTxNotes(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	backup.TxTags, err = mw.walletTxTags(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	backup.TxFiatRates, err = mw.walletTxFiatRates(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	// failed unlock attempts are not carried over to restored wallets
+	delete(backup.Config, failedUnlockAttemptsConfigKey)
+	delete(backup.Config, lastFailedUnlockConfigKey)
+
+	serializedBackup, err := json.Marshal(backup)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedBackup, err := encryptBackup(serializedBackup, backupPassphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return EncodeBase64(encryptedBackup), nil
+}
+
+// ImportBackup decrypts a backup created with `ExportBackup` and restores the
+// wallet, its accounts, config values and transaction notes, tags and exchange
+// rates from the backup.
+// `seedPassphrase` is required if the backed up wallet was created with a
+// seed passphrase.
+func (mw *MultiWallet) ImportBackup(encodedBackup string, backupPassphrase []byte, privatePassphrase string,
+	privatePassphraseType int32, seedPassphrase string) (*Wallet, error) {
+
+	encryptedBackup, err := DecodeBase64(encodedBackup)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	serializedBackup, err := decryptBackup(encryptedBackup, backupPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup WalletBackup
+	err = json.Unmarshal(serializedBackup, &backup)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if backup.HasSeedPassphrase && seedPassphrase == "" {
+		return nil, errors.New(ErrInvalidPassphrase)
+	}
+
+	options := &WalletSetupOptions{
+		SeedPassphrase: seedPassphrase,
+		Birthday:       backup.Birthday,
+	}
+	if _, err := walletBirthday(backup.Birthday); err != nil {
+		// scan the whole chain rather than failing the restore
+		options.Birthday = 0
+	}
+
+	wallet, err := mw.RestoreWalletWithOptions(backup.Name, backup.Seed, privatePassphrase,
+		privatePassphraseType, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if backup.Tag != "" {
+		if err = mw.SetWalletTag(wallet.ID, backup.Tag); err != nil {
+			log.Errorf("[%d] Error restoring wallet tag: %v", wallet.ID, err)
+		}
+	}
+
+	// recreate the backed up accounts in order,
+	// so that the restored accounts have the same numbers and names.
+	for _, account := range backup.Accounts {
+		if account.AccountNumber == 0 {
+			if account.AccountName != "default" {
+				err = wallet.RenameAccount(0, account.AccountName)
+			}
+		} else {
+			_, err = wallet.NextAccount(account.AccountName, []byte(privatePassphrase))
+		}
+		if err != nil {
+			log.Errorf("[%d] Error restoring account %d: %v", wallet.ID, account.AccountNumber, err)
+			break
+		}
+	}
+
+	for key, value := range backup.Config {
+		wallet.SaveUserConfigValue(key, value)
+	}
+
+	for txHash, note := range backup.TxNotes {
+		if err = mw.saveTxNote(wallet.ID, txHash, note); err != nil {
+			log.Errorf("[%d] Error restoring note of transaction %s: %v", wallet.ID, txHash, err)
+		}
+	}
+
+	for txHash, tags := range backup.TxTags {
+		for _, tag := range tags {
+			if err = mw.saveTxTag(wallet.ID, txHash, tag); err != nil {
+				log.Errorf("[%d] Error restoring tag of transaction %s: %v", wallet.ID, txHash, err)
+			}
+		}
+	}
+
+	for _, txFiatRate := range backup.TxFiatRates {
+		txFiatRate.WalletID = wallet.ID
+		if err = mw.saveTxFiatRate(txFiatRate); err != nil {
+			log.Errorf("[%d] Error restoring exchange rate of transaction %s: %v", wallet.ID, txFiatRate.TxHash, err)
+		}
+	}
+
+	return wallet, nil
+}
+
+// walletConfigValues returns the raw values of all config keys saved for the
+// specified wallet.
+func (mw *MultiWallet) walletConfigValues(walletID int) (map[string]json.RawMessage, error) {
+	keyPrefix := strconv.Itoa(walletID)
+	config := make(map[string]json.RawMessage)
+
+	err := mw.db.Bolt.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(userConfigBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			key := string(k)
+			if len(key) <= len(keyPrefix) || key[:len(keyPrefix)] != keyPrefix {
+				return nil
+			}
+
+			// Wallet config keys are prefixed with the wallet id, config keys
+			// for wallet 1 and wallet 10 both begin with 1. Config keys are
+			// not expected to begin with a digit, use this to differentiate.
+			key = key[len(keyPrefix):]
+			if unicode.IsDigit(rune(key[0])) {
+				return nil
+			}
+
+			config[key] = append(json.RawMessage{}, v...)
+			return nil
+		})
+	})
+
+	return config, err
+}
+
+func backupEncryptionKey(passphrase, salt []byte) (*[backupKeySize]byte, error) {
+	derivedKey, err := scrypt.Key(passphrase, salt, backupScryptN, backupScryptR, backupScryptP, backupKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [backupKeySize]byte
+	copy(key[:], derivedKey)
+	for i := range derivedKey {
+		derivedKey[i] = 0
+	}
+
+	return &key, nil
+}
+
+// encryptBackup encrypts `data` with a key derived from `passphrase`.
+// The returned bytes are formatted as version || salt || nonce || ciphertext.
+func encryptBackup(data, passphrase []byte) ([]byte, error) {
+	var salt [backupSaltSize]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, err
+	}
+
+	var nonce [backupNonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+
+	key, err := backupEncryptionKey(passphrase, salt[:])
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+
+	out := make([]byte, 0, 1+backupSaltSize+backupNonceSize+len(data)+secretbox.Overhead)
+	out = append(out, BackupVersion)
+	out = append(out, salt[:]...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, data, &nonce, key), nil
+}
+
+func decryptBackup(encryptedData, passphrase []byte) ([]byte, error) {
+	headerSize := 1 + backupSaltSize + backupNonceSize
+	if len(encryptedData) < headerSize+secretbox.Overhead {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if encryptedData[0] != BackupVersion {
+		return nil, errors.Errorf("unsupported backup version %d", encryptedData[0])
+	}
+
+	salt := encryptedData[1 : 1+backupSaltSize]
+	var nonce [backupNonceSize]byte
+	copy(nonce[:], encryptedData[1+backupSaltSize:headerSize])
+
+	key, err := backupEncryptionKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+
+	data, ok := secretbox.Open(nil, encryptedData[headerSize:], &nonce, key)
+	if !ok {
+		return nil, errors.New(ErrInvalidPassphrase)
+	}
+
+	return data, nil
+}
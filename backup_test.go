@@ -0,0 +1,61 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBackupEncryption(t *testing.T) {
+	data := []byte(`{"version":1,"name":"wallet"}`)
+	passphrase := []byte("backup passphrase")
+
+	encrypted, err := encryptBackup(data, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 1
+
+	unsupportedVersion := append([]byte{}, encrypted...)
+	unsupportedVersion[0] = BackupVersion + 1
+
+	tests := []struct {
+		name       string
+		encrypted  []byte
+		passphrase []byte
+		wantErr    string
+	}{
+		{name: "correct passphrase", encrypted: encrypted, passphrase: passphrase},
+		{name: "wrong passphrase", encrypted: encrypted, passphrase: []byte("wrong"), wantErr: ErrInvalidPassphrase},
+		{name: "tampered ciphertext", encrypted: tampered, passphrase: passphrase, wantErr: ErrInvalidPassphrase},
+		{name: "truncated backup", encrypted: encrypted[:40], passphrase: passphrase, wantErr: ErrInvalid},
+		{name: "unsupported version", encrypted: unsupportedVersion, passphrase: passphrase,
+			wantErr: "unsupported backup version 2"},
+	}
+
+	for _, test := range tests {
+		decrypted, err := decryptBackup(test.encrypted, test.passphrase)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Fatalf("%s: error %v, want %s", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !bytes.Equal(decrypted, data) {
+			t.Fatalf("%s: decrypted %q, want %q", test.name, decrypted, data)
+		}
+	}
+
+	// every backup is encrypted with a new salt and nonce
+	reencrypted, err := encryptBackup(data, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(reencrypted, encrypted) {
+		t.Fatal("backup encrypted twice to the same bytes")
+	}
+}
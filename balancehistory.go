@@ -0,0 +1,133 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// Resolutions of the time series returned by `GetBalanceHistory`.
+const (
+	ResolutionDay   = "day"
+	ResolutionWeek  = "week"
+	ResolutionMonth = "month"
+)
+
+// BalancePoint is the balance, in atoms, at the end of the period starting at
+// the unix timestamp Timestamp.
+type BalancePoint struct {
+	Timestamp int64 `json:"timestamp"`
+	Balance   int64 `json:"balance"`
+}
+
+// periodStart returns the start of the period of `resolution` that `t` is in,
+// in local time. Weeks start on Monday.
+func periodStart(t time.Time, resolution string) (time.Time, error) {
+	year, month, day := t.Date()
+	switch resolution {
+	case ResolutionDay:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location()), nil
+	case ResolutionWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, t.Location()), nil
+	case ResolutionMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location()), nil
+	default:
+		return time.Time{}, errors.New(ErrInvalid)
+	}
+}
+
+// nextPeriodStart returns the start of the period after the period of
+// `resolution` starting at `start`.
+func nextPeriodStart(start time.Time, resolution string) time.Time {
+	switch resolution {
+	case ResolutionWeek:
+		return start.AddDate(0, 0, 7)
+	case ResolutionMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// accountBalanceChange returns the change of the balance of `account` caused
+// by `tx`, or of all accounts if `account` is negative.
+func accountBalanceChange(tx *Transaction, account int32) int64 {
+	var change int64
+	for _, input := range tx.Inputs {
+		if input.AccountNumber >= 0 && (account < 0 || input.AccountNumber == account) {
+			change -= input.Amount
+		}
+	}
+	for _, output := range tx.Outputs {
+		if output.AccountNumber >= 0 && (account < 0 || output.AccountNumber == account) {
+			change += output.Amount
+		}
+	}
+	return change
+}
+
+// GetBalanceHistory returns the json-encoded `BalancePoint`s of the balance of
+// `account` at the end of every day, week or month, as set by `resolution`,
+// from the period of the first transaction of the account to the current
+// period. The balance of all accounts is returned if `account` is negative.
+// Balances are derived from the transaction history, so unconfirmed
+// transactions are included.
+func (wallet *Wallet) GetBalanceHistory(account int32, resolution string) (string, error) {
+	points, err := wallet.GetBalanceHistoryRaw(account, resolution)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPoints, err := json.Marshal(points)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPoints), nil
+}
+
+func (wallet *Wallet) GetBalanceHistoryRaw(account int32, resolution string) ([]*BalancePoint, error) {
+	currentPeriod, err := periodStart(time.Now(), resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &TransactionFilter{}
+	if account >= 0 {
+		filter.Accounts = []int32{account}
+	}
+
+	page, err := wallet.FilterTransactionsRaw(0, 0, filter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*BalancePoint, 0)
+	if len(page.Transactions) == 0 {
+		return points, nil
+	}
+
+	var balance int64
+	period, _ := periodStart(time.Unix(page.Transactions[0].Timestamp, 0), resolution)
+	next := nextPeriodStart(period, resolution)
+	for i := range page.Transactions {
+		tx := &page.Transactions[i]
+
+		// add the points of the periods before the period of this transaction
+		for tx.Timestamp >= next.Unix() {
+			points = append(points, &BalancePoint{Timestamp: period.Unix(), Balance: balance})
+			period, next = next, nextPeriodStart(next, resolution)
+		}
+
+		balance += accountBalanceChange(tx, account)
+	}
+
+	for !period.After(currentPeriod) {
+		points = append(points, &BalancePoint{Timestamp: period.Unix(), Balance: balance})
+		period = nextPeriodStart(period, resolution)
+	}
+
+	return points, nil
+}
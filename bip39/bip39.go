@@ -0,0 +1,186 @@
+// Package bip39 implements the BIP-0039 mnemonic encoding of wallet seeds.
+// https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultEntropyBytes is the entropy size used to generate 24-word mnemonics.
+	DefaultEntropyBytes = 32
+
+	seedIterations = 2048
+	seedBytes      = 64
+	bitsPerWord    = 11
+
+	// japaneseSeparator is the ideographic space used to separate
+	// words of japanese mnemonics.
+	japaneseSeparator = "\u3000"
+)
+
+// Languages lists the names of the available word lists in the order that
+// they are checked when detecting the language of a mnemonic.
+var Languages = []string{
+	"english",
+	"japanese",
+	"korean",
+	"spanish",
+	"chinese_simplified",
+	"chinese_traditional",
+	"french",
+	"italian",
+	"czech",
+}
+
+var wordLists = map[string][]string{
+	"english":             English,
+	"japanese":            Japanese,
+	"korean":              Korean,
+	"spanish":             Spanish,
+	"chinese_simplified":  ChineseSimplified,
+	"chinese_traditional": ChineseTraditional,
+	"french":              French,
+	"italian":             Italian,
+	"czech":               Czech,
+}
+
+var (
+	ErrInvalidEntropySize = errors.New("entropy size must be a multiple of 4 bytes between 16 and 32 bytes")
+	ErrInvalidWordCount   = errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+	ErrInvalidWord        = errors.New("mnemonic contains a word that is not in the word list")
+	ErrChecksumMismatch   = errors.New("mnemonic checksum mismatch")
+	ErrUnknownLanguage    = errors.New("unknown word list language")
+)
+
+// WordList returns the word list for `language`.
+func WordList(language string) ([]string, error) {
+	wordList, ok := wordLists[language]
+	if !ok {
+		return nil, ErrUnknownLanguage
+	}
+	return wordList, nil
+}
+
+// DetectLanguage returns the language of the first word list in `Languages`
+// that the mnemonic can be decoded with.
+func DetectLanguage(mnemonic string) (string, error) {
+	for _, language := range Languages {
+		if IsMnemonicValid(mnemonic, wordLists[language]) {
+			return language, nil
+		}
+	}
+	return "", ErrUnknownLanguage
+}
+
+// NewEntropy returns `size` bytes of random entropy for generating a mnemonic.
+func NewEntropy(size int) ([]byte, error) {
+	if !validEntropySize(size) {
+		return nil, ErrInvalidEntropySize
+	}
+
+	entropy := make([]byte, size)
+	_, err := rand.Read(entropy)
+	if err != nil {
+		return nil, err
+	}
+
+	return entropy, nil
+}
+
+func validEntropySize(size int) bool {
+	return size >= 16 && size <= 32 && size%4 == 0
+}
+
+// EntropyToMnemonic encodes the entropy and its checksum as words from `wordList`.
+func EntropyToMnemonic(entropy []byte, wordList []string) (string, error) {
+	if !validEntropySize(len(entropy)) {
+		return "", ErrInvalidEntropySize
+	}
+
+	// append the first len(entropy)/4 bits of the entropy hash as a checksum
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	totalBits := len(entropy)*8 + len(entropy)/4
+
+	words := make([]string, totalBits/bitsPerWord)
+	for i := range words {
+		var index int
+		for bit := i * bitsPerWord; bit < (i+1)*bitsPerWord; bit++ {
+			index = index<<1 | int(data[bit/8]>>(7-uint(bit%8))&1)
+		}
+		words[i] = wordList[index]
+	}
+
+	separator := " "
+	if isJapanese(wordList) {
+		separator = japaneseSeparator
+	}
+
+	return strings.Join(words, separator), nil
+}
+
+func isJapanese(wordList []string) bool {
+	return len(wordList) > 0 && &wordList[0] == &Japanese[0]
+}
+
+// MnemonicToEntropy decodes the mnemonic using `wordList` and verifies its checksum.
+func MnemonicToEntropy(mnemonic string, wordList []string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidWordCount
+	}
+
+	wordIndexes := make(map[string]int, len(wordList))
+	for i, word := range wordList {
+		wordIndexes[norm.NFKD.String(word)] = i
+	}
+
+	totalBits := len(words) * bitsPerWord
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := wordIndexes[word]
+		if !ok {
+			return nil, ErrInvalidWord
+		}
+
+		for b := 0; b < bitsPerWord; b++ {
+			if index&(1<<uint(bitsPerWord-1-b)) != 0 {
+				bit := i*bitsPerWord + b
+				data[bit/8] |= 1 << (7 - uint(bit%8))
+			}
+		}
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	checksum := sha256.Sum256(entropy)
+	mask := byte(0xff) << uint(8-checksumBits)
+	if data[len(entropy)]&mask != checksum[0]&mask {
+		return nil, ErrChecksumMismatch
+	}
+
+	return entropy, nil
+}
+
+// IsMnemonicValid returns true if the mnemonic can be decoded using `wordList`.
+func IsMnemonicValid(mnemonic string, wordList []string) bool {
+	_, err := MnemonicToEntropy(mnemonic, wordList)
+	return err == nil
+}
+
+// NewSeed derives the 64-byte wallet seed for a mnemonic as specified by BIP-0039.
+// The mnemonic is not validated by this function, use `MnemonicToEntropy` or
+// `IsMnemonicValid` to validate the mnemonic first.
+func NewSeed(mnemonic, passphrase string) []byte {
+	normalizedMnemonic := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(normalizedMnemonic), []byte(salt), seedIterations, seedBytes, sha512.New)
+}
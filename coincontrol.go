@@ -0,0 +1,153 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/decred/dcrwallet/wallet/v3/txauthor"
+	"github.com/decred/dcrwallet/wallet/v3/txsizes"
+)
+
+// SetInputs sets the json-encoded array of outpoints, formatted as hash:index,
+// as the inputs of this transaction. Automatic input selection is bypassed and
+// every set input is spent, any excess is returned as change. The outpoints
+// must be spendable outputs of the accounts that may fund this transaction.
+func (tx *TxAuthor) SetInputs(jsonEncodedOutpoints string) error {
+	var outpoints []string
+	err := json.Unmarshal([]byte(jsonEncodedOutpoints), &outpoints)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return tx.SetInputsRaw(outpoints)
+}
+
+func (tx *TxAuthor) SetInputsRaw(outpoints []string) error {
+	if len(outpoints) == 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	inputs := make([]string, 0, len(outpoints))
+	seen := make(map[string]bool, len(outpoints))
+	for _, outpoint := range outpoints {
+		outpoint = strings.TrimSpace(outpoint)
+		if strings.Count(outpoint, ":") != 1 {
+			return errors.New(ErrInvalid)
+		}
+		if seen[outpoint] {
+			continue
+		}
+		seen[outpoint] = true
+		inputs = append(inputs, outpoint)
+	}
+
+	tx.inputs = inputs
+	return nil
+}
+
+// ClearInputs removes the inputs set with `SetInputs`, inputs are selected
+// automatically afterwards.
+func (tx *TxAuthor) ClearInputs() {
+	tx.inputs = nil
+}
+
+// Inputs returns the json-encoded array of the outpoints set with `SetInputs`.
+func (tx *TxAuthor) Inputs() (string, error) {
+	inputs := tx.inputs
+	if inputs == nil {
+		inputs = []string{}
+	}
+
+	jsonEncodedInputs, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedInputs), nil
+}
+
+// selectedInputs returns the details of the inputs set with `SetInputs`.
+// Returns `ErrNotExist` if an outpoint is not an unspent output of an account
+// that may fund this transaction and `ErrInvalid` if an output is not yet
+// spendable or is not a P2PKH output.
+func (tx *TxAuthor) selectedInputs(ctx context.Context) (*txauthor.InputDetail, error) {
+	outputs, err := tx.unspentOutputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unspentOutputs := make(map[string]*w.TransactionOutput, len(outputs))
+	for _, output := range outputs {
+		unspentOutputs[output.OutPoint.String()] = output
+	}
+
+	bestBlock := tx.sourceWallet.GetBestBlock()
+	requiredConfirmations := tx.sourceWallet.RequiredConfirmations()
+
+	selected := make([]*w.TransactionOutput, 0, len(tx.inputs))
+	for _, outpoint := range tx.inputs {
+		output, ok := unspentOutputs[outpoint]
+		if !ok {
+			return nil, errors.New(ErrNotExist)
+		}
+
+		spendable := tx.sourceWallet.unspentOutput(output, bestBlock, requiredConfirmations).IsSpendable ||
+			(tx.spendsUnconfirmedChange() && tx.sourceWallet.isUnconfirmedChange(ctx, output))
+		if !spendable {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		// only (stake tagged) P2PKH outputs can be signed by the wallet,
+		// outputs paid to imported redeem scripts are receive-only
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.Output.Version, output.Output.PkScript,
+			tx.sourceWallet.chainParams)
+		if err != nil || len(addrs) != 1 {
+			return nil, errors.New(ErrInvalid)
+		}
+		if _, ok := addrs[0].(*dcrutil.AddressPubKeyHash); !ok {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		selected = append(selected, output)
+	}
+
+	return inputDetailFor(selected), nil
+}
+
+// inputDetailFor returns the input details of a transaction that spends the
+// P2PKH `outputs`.
+func inputDetailFor(outputs []*w.TransactionOutput) *txauthor.InputDetail {
+	inputDetail := &txauthor.InputDetail{}
+	for _, output := range outputs {
+		op := output.OutPoint
+		inputDetail.Amount += dcrutil.Amount(output.Output.Value)
+		inputDetail.Inputs = append(inputDetail.Inputs, wire.NewTxIn(&op, output.Output.Value, nil))
+		inputDetail.Scripts = append(inputDetail.Scripts, output.Output.PkScript)
+		inputDetail.RedeemScriptSizes = append(inputDetail.RedeemScriptSizes, txsizes.RedeemP2PKHSigScriptSize)
+	}
+	return inputDetail
+}
+
+// constructTransactionWithInputs creates an unsigned transaction that spends
+// the inputs set with `SetInputs`. Returns `ErrInsufficientBalance` if the
+// inputs do not cover the outputs and fee.
+func (tx *TxAuthor) constructTransactionWithInputs(ctx context.Context, outputs []*wire.TxOut,
+	changeSource txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
+
+	inputDetail, err := tx.selectedInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputSource := func(target dcrutil.Amount) (*txauthor.InputDetail, error) {
+		return inputDetail, nil
+	}
+
+	return txauthor.NewUnsignedTransaction(outputs, tx.relayFeePerKb(), inputSource, changeSource)
+}
@@ -0,0 +1,331 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"sort"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/decred/dcrwallet/wallet/v3/txauthor"
+	"github.com/decred/dcrwallet/wallet/v3/txsizes"
+)
+
+// Input selection strategies that may be set with `TxAuthor.SetCoinSelection`.
+const (
+	// CoinSelectionDefault uses the wallet's own input selection.
+	CoinSelectionDefault int32 = iota
+
+	// CoinSelectionLargestFirst spends the largest outputs first, to spend
+	// as few inputs as possible.
+	CoinSelectionLargestFirst
+
+	// CoinSelectionOldestFirst spends the outputs mined longest ago first.
+	CoinSelectionOldestFirst
+
+	// CoinSelectionBranchAndBound searches for a set of outputs that pays
+	// the outputs and fee without creating a change output, and falls back
+	// to CoinSelectionLargestFirst if there is no such set.
+	CoinSelectionBranchAndBound
+
+	// CoinSelectionPrivacyWeighted spends all outputs paid to an address
+	// together, preferring outputs of as few addresses as possible, so that
+	// fewer addresses are linked by the transaction.
+	CoinSelectionPrivacyWeighted
+)
+
+const (
+	// p2pkhInputSize is the worst case serialized size of an input that
+	// redeems a P2PKH output.
+	p2pkhInputSize = txsizes.RedeemP2PKHInputSize
+
+	// p2pkhOutputSize is the serialized size of a P2PKH output.
+	p2pkhOutputSize = txsizes.P2PKHOutputSize
+
+	// branchAndBoundMaxTries bounds the number of branches visited when
+	// searching for a changeless set of inputs.
+	branchAndBoundMaxTries = 100000
+)
+
+// SetCoinSelection sets the strategy used to select the inputs of this
+// transaction to one of the CoinSelection constants. The strategy is not used
+// if inputs are set with `SetInputs` or if the transaction sends all funds of
+// the source account.
+func (tx *TxAuthor) SetCoinSelection(strategy int32) error {
+	if strategy < CoinSelectionDefault || strategy > CoinSelectionPrivacyWeighted {
+		return errors.New(ErrInvalid)
+	}
+
+	tx.coinSelection = strategy
+	return nil
+}
+
+// CoinSelection returns the input selection strategy of this transaction.
+func (tx *TxAuthor) CoinSelection() int32 {
+	return tx.coinSelection
+}
+
+// constructTransactionWithStrategy creates an unsigned transaction whose
+// inputs are selected with the strategy set with `SetCoinSelection`, from the
+// outputs of every account that may fund the transaction. Every candidate
+// output is spent if `selectAll` is true.
+func (tx *TxAuthor) constructTransactionWithStrategy(ctx context.Context, outputs []*wire.TxOut,
+	changeSource txauthor.ChangeSource, selectAll bool) (*txauthor.AuthoredTx, error) {
+
+	candidates, err := tx.candidateInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	relayFeePerKb := tx.relayFeePerKb()
+	var changeless bool
+	inputSource := func(target dcrutil.Amount) (*txauthor.InputDetail, error) {
+		var selected []*w.TransactionOutput
+		changeless = false
+		switch {
+		case selectAll:
+			selected = candidates
+		case tx.coinSelection == CoinSelectionOldestFirst:
+			selected = selectOldestFirst(candidates, target)
+		case tx.coinSelection == CoinSelectionBranchAndBound:
+			selected, changeless = selectBranchAndBound(candidates, target, relayFeePerKb)
+		case tx.coinSelection == CoinSelectionPrivacyWeighted:
+			selected = selectPrivacyWeighted(candidates, target)
+		default:
+			selected = selectLargestFirst(candidates, target)
+		}
+		return inputDetailFor(selected), nil
+	}
+
+	unsignedTx, err := txauthor.NewUnsignedTransaction(outputs, relayFeePerKb, inputSource, changeSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// the change of a changeless set of inputs is added to the fee instead of
+	// creating a change output that costs more to spend than its amount
+	if changeless {
+		dropChangeBelow(unsignedTx, branchAndBoundCostOfChange(relayFeePerKb))
+	}
+	return unsignedTx, nil
+}
+
+// dropChangeBelow removes the change output of `unsignedTx` if its amount is
+// less than `costOfChange`, adding the amount to the fee.
+func dropChangeBelow(unsignedTx *txauthor.AuthoredTx, costOfChange int64) {
+	if unsignedTx.ChangeIndex < 0 {
+		return
+	}
+
+	changeOutput := unsignedTx.Tx.TxOut[unsignedTx.ChangeIndex]
+	if changeOutput.Value >= costOfChange {
+		return
+	}
+
+	txOut := unsignedTx.Tx.TxOut
+	unsignedTx.Tx.TxOut = append(txOut[:unsignedTx.ChangeIndex:unsignedTx.ChangeIndex], txOut[unsignedTx.ChangeIndex+1:]...)
+	unsignedTx.EstimatedSignedSerializeSize -= changeOutput.SerializeSize()
+	unsignedTx.ChangeIndex = -1
+}
+
+// candidateInputs returns the unspent outputs of the accounts that may fund
+// this transaction that may be selected as inputs: spendable, unlocked P2PKH
+// outputs, including unconfirmed change if allowed for this transaction.
+func (tx *TxAuthor) candidateInputs(ctx context.Context) ([]*w.TransactionOutput, error) {
+	outputs, err := tx.unspentOutputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlock := tx.sourceWallet.GetBestBlock()
+	requiredConfirmations := tx.sourceWallet.RequiredConfirmations()
+
+	spendUnconfirmedChange := tx.spendsUnconfirmedChange()
+
+	candidates := make([]*w.TransactionOutput, 0, len(outputs))
+	for _, output := range outputs {
+		unspentOutput := tx.sourceWallet.unspentOutput(output, bestBlock, requiredConfirmations)
+		spendable := unspentOutput.IsSpendable ||
+			(spendUnconfirmedChange && tx.sourceWallet.isUnconfirmedChange(ctx, output))
+		if spendable && !unspentOutput.IsLocked && tx.sourceWallet.isP2PKHOutput(unspentOutput) {
+			candidates = append(candidates, output)
+		}
+	}
+
+	return candidates, nil
+}
+
+// accumulateInputs returns the outputs, in order, until their total amount
+// reaches `target`. All outputs are returned if their total is less than
+// `target`.
+func accumulateInputs(outputs []*w.TransactionOutput, target dcrutil.Amount) []*w.TransactionOutput {
+	var total dcrutil.Amount
+	for i, output := range outputs {
+		total += dcrutil.Amount(output.Output.Value)
+		if total >= target {
+			return outputs[:i+1]
+		}
+	}
+	return outputs
+}
+
+func selectLargestFirst(candidates []*w.TransactionOutput, target dcrutil.Amount) []*w.TransactionOutput {
+	outputs := make([]*w.TransactionOutput, len(candidates))
+	copy(outputs, candidates)
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].Output.Value > outputs[j].Output.Value
+	})
+
+	return accumulateInputs(outputs, target)
+}
+
+func selectOldestFirst(candidates []*w.TransactionOutput, target dcrutil.Amount) []*w.TransactionOutput {
+	outputs := make([]*w.TransactionOutput, len(candidates))
+	copy(outputs, candidates)
+
+	// unmined outputs are the newest
+	height := func(output *w.TransactionOutput) int32 {
+		if output.ContainingBlock.Height <= 0 {
+			return int32(^uint32(0) >> 1)
+		}
+		return output.ContainingBlock.Height
+	}
+	sort.SliceStable(outputs, func(i, j int) bool {
+		heightI, heightJ := height(outputs[i]), height(outputs[j])
+		if heightI != heightJ {
+			return heightI < heightJ
+		}
+		return outputs[i].ReceiveTime.Before(outputs[j].ReceiveTime)
+	})
+
+	return accumulateInputs(outputs, target)
+}
+
+// selectBranchAndBound searches for the set of outputs whose amounts, less
+// the fee to spend them, exceed `target` by the least amount, but by less
+// than the smallest change output that is not dust, so that no change output
+// is created. The largest outputs are selected first if there is no such set.
+// The returned bool is true if a changeless set was found.
+func selectBranchAndBound(candidates []*w.TransactionOutput, target, relayFeePerKb dcrutil.Amount) ([]*w.TransactionOutput, bool) {
+	inputFee := int64(relayFeePerKb) * p2pkhInputSize / 1000
+	costOfChange := branchAndBoundCostOfChange(relayFeePerKb)
+
+	// outputs that cost more to spend than their amount are never selected
+	outputs := make([]*w.TransactionOutput, 0, len(candidates))
+	for _, output := range candidates {
+		if output.Output.Value > inputFee {
+			outputs = append(outputs, output)
+		}
+	}
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].Output.Value > outputs[j].Output.Value
+	})
+
+	values := make([]int64, len(outputs))
+	remaining := make([]int64, len(outputs)+1)
+	for i := len(outputs) - 1; i >= 0; i-- {
+		values[i] = outputs[i].Output.Value - inputFee
+		remaining[i] = remaining[i+1] + values[i]
+	}
+
+	var best []int
+	var bestWaste int64
+	var selected []int
+	var tries int
+
+	var search func(i int, total int64)
+	search = func(i int, total int64) {
+		if tries >= branchAndBoundMaxTries || (best != nil && bestWaste == 0) {
+			return
+		}
+		tries++
+
+		if total > int64(target)+costOfChange {
+			return
+		}
+		if total >= int64(target) {
+			waste := total - int64(target)
+			if best == nil || waste < bestWaste {
+				best = append(best[:0], selected...)
+				bestWaste = waste
+			}
+			return
+		}
+		if i == len(values) || total+remaining[i] < int64(target) {
+			return
+		}
+
+		// include the output, then try without it
+		selected = append(selected, i)
+		search(i+1, total+values[i])
+		selected = selected[:len(selected)-1]
+		search(i+1, total)
+	}
+	search(0, 0)
+
+	if best == nil {
+		return accumulateInputs(outputs, target), false
+	}
+
+	selectedOutputs := make([]*w.TransactionOutput, len(best))
+	for i, index := range best {
+		selectedOutputs[i] = outputs[index]
+	}
+	return selectedOutputs, true
+}
+
+// branchAndBoundCostOfChange returns the cost of creating a change output and
+// later spending it. Change outputs smaller than this are added to the fee of
+// changeless transactions.
+func branchAndBoundCostOfChange(relayFeePerKb dcrutil.Amount) int64 {
+	return 3 * int64(relayFeePerKb) * (p2pkhOutputSize + p2pkhInputSize) / 1000
+}
+
+// selectPrivacyWeighted selects the outputs of a single address if any address
+// received enough to pay `target`, the smallest such address is preferred.
+// Otherwise, the outputs of the addresses that received the most are
+// selected until `target` is reached. Outputs of an address are always
+// selected together.
+func selectPrivacyWeighted(candidates []*w.TransactionOutput, target dcrutil.Amount) []*w.TransactionOutput {
+	type addressOutputs struct {
+		outputs []*w.TransactionOutput
+		total   dcrutil.Amount
+	}
+
+	groupIndex := make(map[string]int)
+	groups := make([]*addressOutputs, 0)
+	for _, output := range candidates {
+		// outputs are grouped by script, a P2PKH script identifies an address
+		script := string(output.Output.PkScript)
+		index, ok := groupIndex[script]
+		if !ok {
+			index = len(groups)
+			groupIndex[script] = index
+			groups = append(groups, &addressOutputs{})
+		}
+		groups[index].outputs = append(groups[index].outputs, output)
+		groups[index].total += dcrutil.Amount(output.Output.Value)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].total > groups[j].total
+	})
+
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].total >= target {
+			return groups[i].outputs
+		}
+	}
+
+	var total dcrutil.Amount
+	selected := make([]*w.TransactionOutput, 0)
+	for _, group := range groups {
+		selected = append(selected, group.outputs...)
+		total += group.total
+		if total >= target {
+			break
+		}
+	}
+	return selected
+}
@@ -0,0 +1,254 @@
+package dcrlibwallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/wire"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/decred/dcrwallet/wallet/v3/txauthor"
+	"github.com/decred/dcrwallet/wallet/v3/txsizes"
+)
+
+// testOutput is an unspent output of the coin selection tests, identified by
+// its output index.
+type testOutput struct {
+	index  uint32
+	value  int64
+	height int32
+	script byte
+}
+
+func testOutputs(outputs []testOutput) []*w.TransactionOutput {
+	receiveTime := time.Unix(1577836800, 0)
+
+	candidates := make([]*w.TransactionOutput, len(outputs))
+	for i, output := range outputs {
+		candidate := &w.TransactionOutput{}
+		candidate.OutPoint.Index = output.index
+		candidate.Output.Value = output.value
+		candidate.Output.PkScript = []byte{output.script}
+		candidate.ContainingBlock.Height = output.height
+		candidate.ReceiveTime = receiveTime.Add(time.Duration(i) * time.Minute)
+		candidates[i] = candidate
+	}
+	return candidates
+}
+
+func checkSelected(t *testing.T, name string, selected []*w.TransactionOutput, want []uint32) {
+	t.Helper()
+
+	indexes := make([]uint32, len(selected))
+	for i, output := range selected {
+		indexes[i] = output.OutPoint.Index
+	}
+
+	if len(indexes) != len(want) {
+		t.Fatalf("%s: selected %v, want %v", name, indexes, want)
+	}
+	for i := range want {
+		if indexes[i] != want[i] {
+			t.Fatalf("%s: selected %v, want %v", name, indexes, want)
+		}
+	}
+}
+
+func TestSelectLargestFirst(t *testing.T) {
+	candidates := testOutputs([]testOutput{
+		{index: 0, value: 1e8},
+		{index: 1, value: 5e8},
+		{index: 2, value: 3e8},
+		{index: 3, value: 8e8},
+	})
+
+	tests := []struct {
+		name   string
+		target dcrutil.Amount
+		want   []uint32
+	}{
+		{name: "largest output", target: 8e8, want: []uint32{3}},
+		{name: "two largest outputs", target: 9e8, want: []uint32{3, 1}},
+		{name: "all outputs", target: 17e8, want: []uint32{3, 1, 2, 0}},
+		{name: "insufficient outputs", target: 20e8, want: []uint32{3, 1, 2, 0}},
+	}
+
+	for _, test := range tests {
+		checkSelected(t, test.name, selectLargestFirst(candidates, test.target), test.want)
+	}
+}
+
+func TestSelectOldestFirst(t *testing.T) {
+	candidates := testOutputs([]testOutput{
+		{index: 0, value: 1e8, height: 10},
+		{index: 1, value: 1e8, height: 5},
+		{index: 2, value: 1e8, height: 0},
+		{index: 3, value: 1e8, height: 7},
+		{index: 4, value: 1e8, height: 7},
+	})
+
+	tests := []struct {
+		name   string
+		target dcrutil.Amount
+		want   []uint32
+	}{
+		{name: "oldest output", target: 1e8, want: []uint32{1}},
+		{name: "same height by receive time", target: 3e8, want: []uint32{1, 3, 4}},
+		{name: "unmined output last", target: 5e8, want: []uint32{1, 3, 4, 0, 2}},
+	}
+
+	for _, test := range tests {
+		checkSelected(t, test.name, selectOldestFirst(candidates, test.target), test.want)
+	}
+}
+
+func TestSelectBranchAndBound(t *testing.T) {
+	relayFeePerKb := dcrutil.Amount(1e4)
+	inputFee := int64(relayFeePerKb) * txsizes.RedeemP2PKHInputSize / 1000
+
+	tests := []struct {
+		name       string
+		candidates []testOutput
+		target     dcrutil.Amount
+		want       []uint32
+		changeless bool
+	}{
+		{
+			name: "changeless set over largest output",
+			candidates: []testOutput{
+				{index: 0, value: 5e8},
+				{index: 1, value: 3e8},
+				{index: 2, value: 2e8},
+			},
+			target:     dcrutil.Amount(5e8 - 2*inputFee),
+			want:       []uint32{1, 2},
+			changeless: true,
+		},
+		{
+			name: "single changeless output",
+			candidates: []testOutput{
+				{index: 0, value: 1e8},
+				{index: 1, value: 4e8},
+			},
+			target:     dcrutil.Amount(4e8 - inputFee),
+			want:       []uint32{1},
+			changeless: true,
+		},
+		{
+			name: "no changeless set",
+			candidates: []testOutput{
+				{index: 0, value: 3e8},
+				{index: 1, value: 5e8},
+			},
+			target: 1e8,
+			want:   []uint32{1},
+		},
+		{
+			name: "outputs below the input fee skipped",
+			candidates: []testOutput{
+				{index: 0, value: inputFee},
+				{index: 1, value: 2e8},
+			},
+			target: 3e8,
+			want:   []uint32{1},
+		},
+	}
+
+	for _, test := range tests {
+		selected, changeless := selectBranchAndBound(testOutputs(test.candidates), test.target, relayFeePerKb)
+		checkSelected(t, test.name, selected, test.want)
+		if changeless != test.changeless {
+			t.Fatalf("%s: changeless %v, want %v", test.name, changeless, test.changeless)
+		}
+	}
+}
+
+func TestDropChangeBelow(t *testing.T) {
+	const costOfChange = 6000
+
+	tests := []struct {
+		name            string
+		changeIndex     int
+		values          []int64
+		wantChangeIndex int
+		wantValues      []int64
+	}{
+		{
+			name:            "no change output",
+			changeIndex:     -1,
+			values:          []int64{1e8},
+			wantChangeIndex: -1,
+			wantValues:      []int64{1e8},
+		},
+		{
+			name:            "change below the cost of change",
+			changeIndex:     1,
+			values:          []int64{1e8, costOfChange - 1, 2e8},
+			wantChangeIndex: -1,
+			wantValues:      []int64{1e8, 2e8},
+		},
+		{
+			name:            "change at the cost of change",
+			changeIndex:     1,
+			values:          []int64{1e8, costOfChange},
+			wantChangeIndex: 1,
+			wantValues:      []int64{1e8, costOfChange},
+		},
+	}
+
+	for _, test := range tests {
+		unsignedTx := &txauthor.AuthoredTx{
+			Tx:                           wire.NewMsgTx(),
+			ChangeIndex:                  test.changeIndex,
+			EstimatedSignedSerializeSize: 1000,
+		}
+		for _, value := range test.values {
+			unsignedTx.Tx.AddTxOut(wire.NewTxOut(value, make([]byte, 25)))
+		}
+
+		dropChangeBelow(unsignedTx, costOfChange)
+
+		if unsignedTx.ChangeIndex != test.wantChangeIndex {
+			t.Fatalf("%s: change index %d, want %d", test.name, unsignedTx.ChangeIndex, test.wantChangeIndex)
+		}
+		if len(unsignedTx.Tx.TxOut) != len(test.wantValues) {
+			t.Fatalf("%s: %d outputs, want %d", test.name, len(unsignedTx.Tx.TxOut), len(test.wantValues))
+		}
+		for i, value := range test.wantValues {
+			if unsignedTx.Tx.TxOut[i].Value != value {
+				t.Fatalf("%s: output %d value %d, want %d", test.name, i, unsignedTx.Tx.TxOut[i].Value, value)
+			}
+		}
+		wantSize := 1000
+		if len(test.wantValues) < len(test.values) {
+			wantSize -= p2pkhOutputSize
+		}
+		if unsignedTx.EstimatedSignedSerializeSize != wantSize {
+			t.Fatalf("%s: estimated size %d, want %d", test.name, unsignedTx.EstimatedSignedSerializeSize, wantSize)
+		}
+	}
+}
+
+func TestSelectPrivacyWeighted(t *testing.T) {
+	candidates := testOutputs([]testOutput{
+		{index: 0, value: 1e8, script: 'a'},
+		{index: 1, value: 3e8, script: 'b'},
+		{index: 2, value: 1e8, script: 'a'},
+		{index: 3, value: 5e8, script: 'c'},
+	})
+
+	tests := []struct {
+		name   string
+		target dcrutil.Amount
+		want   []uint32
+	}{
+		{name: "smallest sufficient address", target: 2e8, want: []uint32{0, 2}},
+		{name: "larger sufficient address", target: 4e8, want: []uint32{3}},
+		{name: "largest addresses first", target: 7e8, want: []uint32{3, 1}},
+		{name: "all addresses", target: 9e8, want: []uint32{3, 1, 0, 2}},
+	}
+
+	for _, test := range tests {
+		checkSelected(t, test.name, selectPrivacyWeighted(candidates, test.target), test.want)
+	}
+}
@@ -0,0 +1,140 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// ConsolidateUTXOs sends up to `maxInputs` of the smallest spendable outputs
+// of `account` to a new change address of the same account in a single
+// transaction paying `feeRate` atoms/kB, so that later transactions spend
+// fewer inputs and pay lower fees. Locked outputs, and outputs of the mixed
+// account that were not received from a mix, are not consolidated.
+// Returns the json-encoded `ConsolidationResult`.
+func (wallet *Wallet) ConsolidateUTXOs(account, maxInputs int32, feeRate int64, privPass []byte) (string, error) {
+	result, err := wallet.ConsolidateUTXOsRaw(account, maxInputs, feeRate, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedResult, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedResult), nil
+}
+
+func (wallet *Wallet) ConsolidateUTXOsRaw(account, maxInputs int32, feeRate int64, privPass []byte) (*ConsolidationResult, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	// consolidating a single output only costs fees
+	if maxInputs < 2 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	unspentOutputs, err := wallet.ListUnspentRaw(account)
+	if err != nil {
+		return nil, err
+	}
+
+	// smallest outputs first, they cost the most to spend relative to their
+	// amount.
+	sort.Slice(unspentOutputs, func(i, j int) bool {
+		return unspentOutputs[i].Amount < unspentOutputs[j].Amount
+	})
+
+	inputs := make([]string, 0, maxInputs)
+	for _, output := range unspentOutputs {
+		if len(inputs) == int(maxInputs) {
+			break
+		}
+		if output.IsSpendable && !output.IsLocked && wallet.isP2PKHOutput(output) &&
+			wallet.spendableFromAccount(uint32(account), output) {
+			inputs = append(inputs, output.OutPoint)
+		}
+	}
+	if len(inputs) < 2 {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	destinationAddress, err := wallet.internal.NewInternalAddress(wallet.shutdownContext(), uint32(account),
+		w.WithGapPolicyWrap())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	tx := wallet.newUnsignedTx(account)
+	tx.SendAll(destinationAddress.Address())
+
+	err = tx.SetInputsRaw(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.SetFeeRate(feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := tx.PreviewRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := tx.Broadcast(privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConsolidationResult{
+		TxHash:           hash.String(),
+		InputCount:       int32(len(inputs)),
+		TotalInputAmount: preview.TotalInputAmount,
+		OutputAmount:     preview.TotalInputAmount - preview.Fee,
+		Fee:              preview.Fee,
+	}
+
+	log.Infof("[%d] Consolidated %d outputs of account %d, paying a fee of %s", wallet.ID, result.InputCount,
+		account, dcrutil.Amount(result.Fee))
+	return result, nil
+}
+
+// spendableFromAccount returns false if `account` is the mixed account and the
+// unspent output was not received from a mix.
+func (wallet *Wallet) spendableFromAccount(account uint32, output *UnspentOutput) bool {
+	if !wallet.isMixedAccount(account) {
+		return true
+	}
+
+	txHash, err := chainhash.NewHashFromStr(output.TxHash)
+	if err != nil {
+		return false
+	}
+	return wallet.isMixedOutput(wallet.shutdownContext(), txHash, output.Index)
+}
+
+// isP2PKHOutput returns true if the unspent output is paid to a P2PKH address,
+// the only outputs that can be set as inputs with `TxAuthor.SetInputs`.
+func (wallet *Wallet) isP2PKHOutput(output *UnspentOutput) bool {
+	addr, err := dcrutil.DecodeAddress(output.Address, wallet.chainParams)
+	if err != nil {
+		return false
+	}
+	_, ok := addr.(*dcrutil.AddressPubKeyHash)
+	return ok
+}
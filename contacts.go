@@ -0,0 +1,169 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// Contact is an address book entry. Contacts are saved in the multiwallet
+// database and the contact name is shown for transaction outputs that pay to
+// the contact's address.
+type Contact struct {
+	ID        int       `storm:"id,increment" json:"id"`
+	Name      string    `storm:"index" json:"name"`
+	Address   string    `storm:"unique" json:"address"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddContact saves a new contact and returns the ID of the contact.
+// Contact names and addresses must be unique, `ErrExist` is returned if
+// another contact has the same name or address.
+func (mw *MultiWallet) AddContact(name, address, note string) (int, error) {
+	contact := &Contact{
+		Name:      strings.TrimSpace(name),
+		Address:   strings.TrimSpace(address),
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+
+	err := mw.validateContact(contact)
+	if err != nil {
+		return 0, err
+	}
+
+	err = mw.db.Save(contact)
+	if err != nil {
+		if err == storm.ErrAlreadyExists {
+			return 0, errors.New(ErrExist)
+		}
+		return 0, err
+	}
+
+	return contact.ID, nil
+}
+
+// UpdateContact changes the name, address and note of the specified contact.
+func (mw *MultiWallet) UpdateContact(contactID int, name, address, note string) error {
+	contact := &Contact{}
+	err := mw.db.One("ID", contactID, contact)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return errors.New(ErrNotExist)
+		}
+		return err
+	}
+
+	contact.Name = strings.TrimSpace(name)
+	contact.Address = strings.TrimSpace(address)
+	contact.Note = note
+
+	err = mw.validateContact(contact)
+	if err != nil {
+		return err
+	}
+
+	return mw.db.Update(contact)
+}
+
+// DeleteContact deletes the specified contact.
+func (mw *MultiWallet) DeleteContact(contactID int) error {
+	err := mw.db.DeleteStruct(&Contact{ID: contactID})
+	if err == storm.ErrNotFound {
+		return errors.New(ErrNotExist)
+	}
+	return err
+}
+
+// validateContact checks that the contact has a name and a valid address and
+// that no other contact has the same name or address.
+func (mw *MultiWallet) validateContact(contact *Contact) error {
+	if contact.Name == "" {
+		return errors.New(ErrInvalid)
+	}
+
+	if _, err := dcrutil.DecodeAddress(contact.Address, mw.chainParams); err != nil {
+		return errors.New(ErrInvalidAddress)
+	}
+
+	var existing []Contact
+	query := mw.db.Select(q.Or(q.Eq("Name", contact.Name), q.Eq("Address", contact.Address)))
+	err := query.Find(&existing)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	for _, existingContact := range existing {
+		if existingContact.ID != contact.ID {
+			return errors.New(ErrExist)
+		}
+	}
+
+	return nil
+}
+
+// Contacts returns the json-encoded list of saved contacts, sorted by name.
+func (mw *MultiWallet) Contacts() (string, error) {
+	contacts, err := mw.ContactsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedContacts, err := json.Marshal(contacts)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedContacts), nil
+}
+
+func (mw *MultiWallet) ContactsRaw() ([]Contact, error) {
+	contacts := make([]Contact, 0)
+	err := mw.db.Select().OrderBy("Name").Find(&contacts)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return contacts, nil
+}
+
+// ContactWithAddress returns the contact with the specified address, or nil
+// if no contact has the address.
+func (mw *MultiWallet) ContactWithAddress(address string) *Contact {
+	contact := &Contact{}
+	err := mw.db.One("Address", address, contact)
+	if err != nil {
+		return nil
+	}
+
+	return contact
+}
+
+// contactName returns the name of the contact with the specified address, or
+// an empty string if no contact has the address.
+func (mw *MultiWallet) contactName(address string) string {
+	if contact := mw.ContactWithAddress(address); contact != nil {
+		return contact.Name
+	}
+	return ""
+}
+
+// setContactNames sets the contact names of the outputs of `tx` that pay to
+// addresses that do not belong to the wallet.
+func (wallet *Wallet) setContactNames(tx *Transaction) {
+	if wallet.contactName == nil {
+		return
+	}
+
+	for _, output := range tx.Outputs {
+		if output.AccountNumber == -1 {
+			output.ContactName = wallet.contactName(output.Address)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/wallet/v3/txrules"
+	"github.com/decred/dcrwallet/wallet/v3/txsizes"
+	"github.com/raedahgroup/dcrlibwallet/spv"
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// BumpUnconfirmedTransaction accelerates the unconfirmed transaction with hash
+// `txHash` by broadcasting a child transaction that spends the parent's change
+// output, or another output paid to the wallet if the parent has no change,
+// back to the wallet. The child pays enough fee for the parent and child
+// together to pay `feeRate` atoms/kB, so miners are incentivized to mine both.
+// Returns the hash of the child transaction.
+func (wallet *Wallet) BumpUnconfirmedTransaction(txHash string, feeRate int64, privPass []byte) ([]byte, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
+	if feeRate < MinFeeRate || feeRate > MaxFeeRate {
+		return nil, errors.New(ErrInvalidAmount)
+	}
+
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	ctx := wallet.shutdownContext()
+	txDetails, err := wallet.internal.TxDetails(ctx, hash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// only unmined transactions can be bumped
+	if txDetails.Block.Height != -1 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	// spend the change output if the parent has one, any unspent output paid
+	// to the wallet otherwise.
+	creditIndex := -1
+	for i, credit := range txDetails.Credits {
+		if credit.Spent {
+			continue
+		}
+		if creditIndex == -1 || credit.Change {
+			creditIndex = i
+		}
+		if credit.Change {
+			break
+		}
+	}
+	if creditIndex == -1 {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	credit := txDetails.Credits[creditIndex]
+	parentTx := &txDetails.MsgTx
+	parentOutput := parentTx.TxOut[credit.Index]
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(parentOutput.Version, parentOutput.PkScript, wallet.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil, errors.New(ErrInvalid)
+	}
+	if _, ok := addrs[0].(*dcrutil.AddressPubKeyHash); !ok {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	addressInfo, err := wallet.AddressInfo(addrs[0].Address())
+	if err != nil {
+		return nil, err
+	}
+
+	changeAccount := wallet.changeAccount(addressInfo.AccountNumber)
+	changeAddress, err := wallet.internal.NewChangeAddress(ctx, changeAccount)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	output, err := txhelper.MakeTxOutput(changeAddress.String(), int64(credit.Amount), wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	parentOutPoint := wire.NewOutPoint(hash, credit.Index, spv.TxTree(parentTx))
+	childTx := wire.NewMsgTx()
+	childTx.AddTxIn(wire.NewTxIn(parentOutPoint, int64(credit.Amount), nil))
+	childTx.AddTxOut(output)
+
+	childFee := cpfpChildFee(parentTx, childTx.SerializeSize()+txsizes.RedeemP2PKHSigScriptSize, dcrutil.Amount(feeRate))
+	output.Value -= int64(childFee)
+	if output.Value <= 0 || txrules.IsDustOutput(output, txrules.DefaultRelayFeePerKb) {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	lock := make(chan time.Time, 1)
+	defer func() {
+		lock <- time.Time{}
+	}()
+
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	invalidSigs, err := wallet.internal.SignTransaction(ctx, childTx, txscript.SigHashAll, nil, nil, nil)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	if len(invalidSigs) > 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	var serializedTx bytes.Buffer
+	serializedTx.Grow(childTx.SerializeSize())
+	err = childTx.Serialize(&serializedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	childHash, err := wallet.internal.PublishTransaction(ctx, childTx, serializedTx.Bytes(), n)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	log.Infof("[%d] Bumped fee of transaction %s with child transaction %s", wallet.ID, txHash, childHash)
+	return childHash[:], nil
+}
+
+// cpfpChildFee returns the fee that a child transaction of `childSize` bytes
+// must pay for the child and `parentTx` together to pay `feeRate`. The child
+// pays at least the minimum relay fee for its own size.
+func cpfpChildFee(parentTx *wire.MsgTx, childSize int, feeRate dcrutil.Amount) dcrutil.Amount {
+	var parentFee int64
+	for _, txIn := range parentTx.TxIn {
+		parentFee += txIn.ValueIn
+	}
+	for _, txOut := range parentTx.TxOut {
+		parentFee -= txOut.Value
+	}
+
+	packageFee := txrules.FeeForSerializeSize(feeRate, parentTx.SerializeSize()+childSize)
+	childFee := packageFee - dcrutil.Amount(parentFee)
+
+	minChildFee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, childSize)
+	if childFee < minChildFee {
+		childFee = minChildFee
+	}
+
+	return childFee
+}
@@ -0,0 +1,211 @@
+package dcrlibwallet
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// SnapshotWalletDB writes a copy of the specified wallet's database to
+// `destPath` as a tar archive and returns the hex-encoded sha256 checksum of
+// the archive, which must be provided to `RestoreWalletDB`.
+// The database of an opened wallet is copied within a database read
+// transaction, so the copy is consistent while the wallet remains open and
+// syncing.
+func (mw *MultiWallet) SnapshotWalletDB(walletID int, destPath string) (string, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	if !wallet.WalletOpened() {
+		return writeWalletDBSnapshot(wallet.dataDir, destPath)
+	}
+
+	tempDir, err := ioutil.TempDir(mw.rootDir, "snapshot")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = copyWalletDB(wallet, filepath.Join(tempDir, walletDbName))
+	if err != nil {
+		log.Errorf("[%d] Error copying wallet db for snapshot: %v", walletID, err)
+		return "", translateError(err)
+	}
+
+	return writeWalletDBSnapshot(tempDir, destPath)
+}
+
+// copyWalletDB writes a copy of the database of the opened wallet to the file
+// at `destPath`.
+func copyWalletDB(wallet *Wallet, destPath string) error {
+	dbFile, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dbFile.Close()
+
+	err = wallet.loader.CopyDB(dbFile)
+	if err != nil {
+		return err
+	}
+
+	return dbFile.Sync()
+}
+
+// writeWalletDBSnapshot archives the wallet.db file or directory in
+// `walletDataDir` to `destPath` and returns the checksum of the archive.
+func writeWalletDBSnapshot(walletDataDir, destPath string) (string, error) {
+	snapshotFile, err := os.Create(destPath)
+	if err != nil {
+		return "", err
+	}
+	defer snapshotFile.Close()
+
+	hasher := sha256.New()
+	tarWriter := tar.NewWriter(io.MultiWriter(snapshotFile, hasher))
+
+	walletDbPath := filepath.Join(walletDataDir, walletDbName)
+	err = filepath.Walk(walletDbPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		header.Name, err = filepath.Rel(walletDataDir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(header.Name)
+
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		os.Remove(destPath)
+		return "", err
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		os.Remove(destPath)
+		return "", err
+	}
+
+	if err = snapshotFile.Sync(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// RestoreWalletDB verifies the checksum of a snapshot created with
+// `SnapshotWalletDB` and adds the wallet in the snapshot as a new wallet.
+// The restored wallet keeps the sync state of the snapshot, so only blocks
+// mined after the snapshot was created need to be synced.
+func (mw *MultiWallet) RestoreWalletDB(walletName, snapshotPath, checksum, publicPassphrase string,
+	privatePassphraseType int32) (*Wallet, error) {
+
+	snapshotFile, err := os.Open(snapshotPath)
+	if err != nil {
+		return nil, errors.New(ErrNotExist)
+	}
+	defer snapshotFile.Close()
+
+	hasher := sha256.New()
+	if _, err = io.Copy(hasher, snapshotFile); err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(hex.EncodeToString(hasher.Sum(nil)), checksum) {
+		log.Errorf("Wallet db snapshot checksum mismatch: %s", snapshotPath)
+		return nil, errors.New(ErrInvalid)
+	}
+
+	tempDir, err := ioutil.TempDir(mw.rootDir, "snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err = snapshotFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err = extractWalletDBSnapshot(snapshotFile, tempDir); err != nil {
+		return nil, err
+	}
+
+	return mw.LinkExistingWallet(walletName, tempDir, publicPassphrase, privatePassphraseType)
+}
+
+func extractWalletDBSnapshot(snapshot io.Reader, destDir string) error {
+	tarReader := tar.NewReader(snapshot)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.New(ErrInvalid)
+		}
+
+		// only the wallet.db file or directory is expected in the snapshot
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if name != walletDbName && !strings.HasPrefix(name, walletDbName+string(filepath.Separator)) {
+			return errors.New(ErrInvalid)
+		}
+		path := filepath.Join(destDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, os.ModePerm)
+		case tar.TypeReg:
+			err = extractSnapshotFile(tarReader, path, os.FileMode(header.Mode))
+		default:
+			err = errors.New(ErrInvalid)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractSnapshotFile(src io.Reader, path string, mode os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, src); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
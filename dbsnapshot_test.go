@@ -0,0 +1,125 @@
+package dcrlibwallet
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSnapshotEntry is an entry of a wallet database snapshot of the tar
+// header type `typeflag`.
+type testSnapshotEntry struct {
+	name     string
+	data     []byte
+	typeflag byte
+}
+
+func testSnapshot(t *testing.T, entries []testSnapshotEntry) *bytes.Buffer {
+	t.Helper()
+
+	snapshot := new(bytes.Buffer)
+	tarWriter := tar.NewWriter(snapshot)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Mode:     0600,
+			Size:     int64(len(entry.data)),
+			Typeflag: entry.typeflag,
+		}
+		if entry.typeflag == tar.TypeSymlink {
+			header.Linkname = "/etc/passwd"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return snapshot
+}
+
+func TestExtractWalletDBSnapshot(t *testing.T) {
+	data := []byte("wallet database")
+
+	tests := []struct {
+		name      string
+		entries   []testSnapshotEntry
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "wallet database file",
+			entries:   []testSnapshotEntry{{name: walletDbName, data: data, typeflag: tar.TypeReg}},
+			wantFiles: []string{walletDbName},
+		},
+		{
+			name: "wallet database directory",
+			entries: []testSnapshotEntry{
+				{name: walletDbName + "/", typeflag: tar.TypeDir},
+				{name: walletDbName + "/000001.vlog", data: data, typeflag: tar.TypeReg},
+			},
+			wantFiles: []string{filepath.Join(walletDbName, "000001.vlog")},
+		},
+		{
+			name:    "parent directory",
+			entries: []testSnapshotEntry{{name: "../" + walletDbName, data: data, typeflag: tar.TypeReg}},
+			wantErr: true,
+		},
+		{
+			name:    "parent directory within the wallet database",
+			entries: []testSnapshotEntry{{name: walletDbName + "/../../x", data: data, typeflag: tar.TypeReg}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			entries: []testSnapshotEntry{{name: "/" + walletDbName, data: data, typeflag: tar.TypeReg}},
+			wantErr: true,
+		},
+		{
+			name:    "other file",
+			entries: []testSnapshotEntry{{name: "wallet.dbx", data: data, typeflag: tar.TypeReg}},
+			wantErr: true,
+		},
+		{
+			name:    "symlink",
+			entries: []testSnapshotEntry{{name: walletDbName, typeflag: tar.TypeSymlink}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		destDir, err := ioutil.TempDir("", "snapshot")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = extractWalletDBSnapshot(testSnapshot(t, test.entries), destDir)
+		if test.wantErr {
+			if err == nil || err.Error() != ErrInvalid {
+				t.Fatalf("%s: error %v, want %s", test.name, err, ErrInvalid)
+			}
+		} else if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		for _, file := range test.wantFiles {
+			extracted, err := ioutil.ReadFile(filepath.Join(destDir, file))
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if !bytes.Equal(extracted, data) {
+				t.Fatalf("%s: extracted %q, want %q", test.name, extracted, data)
+			}
+		}
+
+		os.RemoveAll(destDir)
+	}
+}
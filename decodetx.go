@@ -3,8 +3,10 @@ package dcrlibwallet
 import (
 	"encoding/binary"
 	"fmt"
+	"sort"
 
 	"github.com/decred/dcrd/blockchain/stake/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrd/txscript/v2"
 	"github.com/decred/dcrd/wire"
@@ -36,6 +38,7 @@ func DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chaincfg.Params) (
 	outputs := decodeTxOutputs(msgTx, netParams, walletTx.Outputs)
 
 	ssGenVersion, lastBlockValid, voteBits := voteInfo(msgTx)
+	ticketHash, ticketPrice := ticketInfo(msgTx, txType)
 
 	return &Transaction{
 		WalletID:    walletTx.WalletID,
@@ -57,9 +60,17 @@ func DecodeTransaction(walletTx *TxInfoFromWallet, netParams *chaincfg.Params) (
 		Inputs:    inputs,
 		Outputs:   outputs,
 
+		AccountDebits: accountDebits(walletTx.Inputs),
+
+		AccountDeltas:  accountDeltas(inputs, outputs),
+		Classification: classifyTransaction(inputs, outputs),
+
 		VoteVersion:    int32(ssGenVersion),
 		LastBlockValid: lastBlockValid,
 		VoteBits:       voteBits,
+
+		TicketHash:  ticketHash,
+		TicketPrice: ticketPrice,
 	}, nil
 }
 
@@ -81,6 +92,7 @@ func decodeTxInputs(mtx *wire.MsgTx, walletInputs []*WalletInput) (inputs []*TxI
 			if walletInput.Index == int32(i) {
 				input.AccountName = walletInput.AccountName
 				input.AccountNumber = walletInput.AccountNumber
+				input.IsMine = true
 				break
 			}
 		}
@@ -91,6 +103,107 @@ func decodeTxInputs(mtx *wire.MsgTx, walletInputs []*WalletInput) (inputs []*TxI
 	return
 }
 
+// accountDebits returns the amounts spent from each wallet account by the
+// wallet inputs, ordered by account number.
+func accountDebits(walletInputs []*WalletInput) []*AccountDebit {
+	debits := make([]*AccountDebit, 0)
+	for _, walletInput := range walletInputs {
+		var debit *AccountDebit
+		for _, accountDebit := range debits {
+			if accountDebit.AccountNumber == walletInput.AccountNumber {
+				debit = accountDebit
+				break
+			}
+		}
+		if debit == nil {
+			debit = &AccountDebit{
+				AccountNumber: walletInput.AccountNumber,
+				AccountName:   walletInput.AccountName,
+			}
+			debits = append(debits, debit)
+		}
+		debit.Amount += walletInput.AmountIn
+	}
+
+	sort.Slice(debits, func(i, j int) bool {
+		return debits[i].AccountNumber < debits[j].AccountNumber
+	})
+	return debits
+}
+
+// accountDeltas returns the change of the balance of each wallet account
+// caused by a transaction with the decoded `inputs` and `outputs`, ordered by
+// account number.
+func accountDeltas(inputs []*TxInput, outputs []*TxOutput) []*AccountDelta {
+	deltas := make([]*AccountDelta, 0)
+	accountDelta := func(accountNumber int32, accountName string) *AccountDelta {
+		for _, delta := range deltas {
+			if delta.AccountNumber == accountNumber {
+				return delta
+			}
+		}
+		delta := &AccountDelta{AccountNumber: accountNumber, AccountName: accountName}
+		deltas = append(deltas, delta)
+		return delta
+	}
+
+	for _, input := range inputs {
+		if input.IsMine {
+			accountDelta(input.AccountNumber, input.AccountName).Amount -= input.Amount
+		}
+	}
+	for _, output := range outputs {
+		if output.IsMine {
+			accountDelta(output.AccountNumber, output.AccountName).Amount += output.Amount
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].AccountNumber < deltas[j].AccountNumber
+	})
+	return deltas
+}
+
+// classifyTransaction returns the TxClassification of a transaction with the
+// decoded `inputs` and `outputs`.
+func classifyTransaction(inputs []*TxInput, outputs []*TxOutput) string {
+	// coinbase and stakebase inputs do not spend a previous output and are
+	// neither wallet nor external inputs.
+	nullHash := chainhash.Hash{}.String()
+
+	var hasWalletInput, hasExternalInput, hasExternalOutput bool
+	var walletDelta int64
+	for _, input := range inputs {
+		switch {
+		case input.PreviousTransactionHash == nullHash:
+		case input.IsMine:
+			hasWalletInput = true
+			walletDelta -= input.Amount
+		default:
+			hasExternalInput = true
+		}
+	}
+	for _, output := range outputs {
+		if output.IsMine {
+			walletDelta += output.Amount
+		} else if output.Amount > 0 {
+			// zero value outputs, such as ticket commitments, pay no one
+			hasExternalOutput = true
+		}
+	}
+
+	switch {
+	case hasWalletInput && hasExternalInput:
+		return TxClassificationMixed
+	case hasWalletInput && hasExternalOutput:
+		return TxClassificationSent
+	case !hasWalletInput || walletDelta > 0:
+		return TxClassificationReceived
+	default:
+		return TxClassificationSelf
+	}
+}
+
 func decodeTxOutputs(mtx *wire.MsgTx, netParams *chaincfg.Params, walletOutputs []*WalletOutput) (outputs []*TxOutput) {
 	outputs = make([]*TxOutput, len(mtx.TxOut))
 	txType := stake.DetermineTxType(mtx)
@@ -132,6 +245,7 @@ func decodeTxOutputs(mtx *wire.MsgTx, netParams *chaincfg.Params, walletOutputs
 				output.Address = walletOutput.Address
 				output.AccountName = walletOutput.AccountName
 				output.AccountNumber = walletOutput.AccountNumber
+				output.IsMine = true
 				break
 			}
 		}
@@ -159,3 +273,21 @@ func voteVersion(mtx *wire.MsgTx) uint32 {
 
 	return binary.LittleEndian.Uint32(mtx.TxOut[1].PkScript[4:8])
 }
+
+// ticketInfo returns the hash and price of the ticket purchased by a ticket
+// purchase or spent by a vote or revocation.
+func ticketInfo(msgTx *wire.MsgTx, txType wallet.TransactionType) (ticketHash string, ticketPrice int64) {
+	switch txType {
+	case wallet.TransactionTypeTicketPurchase:
+		return msgTx.TxHash().String(), msgTx.TxOut[0].Value
+	case wallet.TransactionTypeVote:
+		// the first input of a vote is the stakebase
+		ticketInput := msgTx.TxIn[1]
+		return ticketInput.PreviousOutPoint.Hash.String(), ticketInput.ValueIn
+	case wallet.TransactionTypeRevocation:
+		ticketInput := msgTx.TxIn[0]
+		return ticketInput.PreviousOutPoint.Hash.String(), ticketInput.ValueIn
+	default:
+		return "", 0
+	}
+}
@@ -0,0 +1,174 @@
+package dcrlibwallet
+
+import (
+	"github.com/asdine/storm"
+	"github.com/decred/dcrwallet/errors/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// A duress passphrase is an alternate passphrase that a coerced user can reveal
+// in place of the startup passphrase or of the spending passphrase of a wallet.
+// When the wallets are opened using the duress passphrase, only wallets marked
+// as duress wallets are loaded. When the duress passphrase is used to unlock a
+// wallet that is not a duress wallet, every wallet that is not a duress wallet
+// is removed from the multiwallet and the unlock fails with `ErrNotExist`, the
+// app should then reload the list of wallets. Either way, hidden wallets are
+// not opened, synced or returned by any method for the rest of the session, so
+// no listener is ever notified of their activity. Wallets that are not duress
+// wallets are not read from the database until the startup passphrase is
+// verified. Wallet names remain unique among all wallets in the database,
+// including hidden wallets.
+
+// SetDuressPassphrase sets the passphrase that opens only the duress wallets.
+// A startup passphrase must be set and provided to set the duress passphrase,
+// the duress passphrase must be different from the startup passphrase.
+func (mw *MultiWallet) SetDuressPassphrase(startupPassphrase, duressPassphrase []byte) error {
+	if mw.duressMode {
+		return errors.New(ErrInvalidPassphrase)
+	}
+
+	if !mw.IsStartupSecuritySet() || len(duressPassphrase) == 0 {
+		return errors.New(ErrFailedPrecondition)
+	}
+
+	err := mw.VerifyStartupPassphrase(startupPassphrase)
+	if err != nil {
+		return err
+	}
+
+	if mw.verifyStartupPassphrase(duressPassphrase) == nil {
+		return errors.New(ErrInvalid)
+	}
+
+	duressPassphraseHash, err := bcrypt.GenerateFromPassword(duressPassphrase, bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return mw.db.Set(walletsMetadataBucketName, walletDuressPassphraseField, duressPassphraseHash)
+}
+
+// RemoveDuressPassphrase removes the duress passphrase. Duress wallets
+// remain marked as duress wallets.
+func (mw *MultiWallet) RemoveDuressPassphrase(startupPassphrase []byte) error {
+	if mw.duressMode {
+		return errors.New(ErrInvalidPassphrase)
+	}
+
+	err := mw.VerifyStartupPassphrase(startupPassphrase)
+	if err != nil {
+		return err
+	}
+
+	return mw.removeDuressPassphrase()
+}
+
+func (mw *MultiWallet) removeDuressPassphrase() error {
+	err := mw.db.Delete(walletsMetadataBucketName, walletDuressPassphraseField)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// SetDuressWallet marks or unmarks the specified wallet as a duress wallet.
+// Duress wallets are the only wallets loaded when the duress passphrase is
+// used to open the wallets.
+func (mw *MultiWallet) SetDuressWallet(walletID int, startupPassphrase []byte, isDuressWallet bool) error {
+	if mw.duressMode {
+		return errors.New(ErrInvalidPassphrase)
+	}
+
+	err := mw.VerifyStartupPassphrase(startupPassphrase)
+	if err != nil {
+		return err
+	}
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	wallet.IsDuressWallet = isDuressWallet
+	return mw.db.Save(wallet)
+}
+
+// verifyDuressPassphrase returns true if a duress passphrase is set and
+// `passphrase` matches the duress passphrase.
+func (mw *MultiWallet) verifyDuressPassphrase(passphrase []byte) bool {
+	if len(passphrase) == 0 {
+		return false
+	}
+
+	var duressPassphraseHash []byte
+	err := mw.db.Get(walletsMetadataBucketName, walletDuressPassphraseField, &duressPassphraseHash)
+	if err != nil || duressPassphraseHash == nil {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword(duressPassphraseHash, passphrase) == nil
+}
+
+// duressUnlockFn returns the function called when a passphrase typed to unlock
+// the specified wallet is rejected. If the passphrase is the duress passphrase
+// and the wallet is not a duress wallet, the multiwallet enters duress mode and
+// the function returns true. The hidden wallets are shut down in the
+// background, as the function is called in the code path of the unlock.
+func (mw *MultiWallet) duressUnlockFn(walletID int) func(passphrase []byte) bool {
+	return func(passphrase []byte) bool {
+		wallet := mw.WalletWithID(walletID)
+		if wallet == nil || wallet.IsDuressWallet || !mw.verifyDuressPassphrase(passphrase) {
+			return false
+		}
+
+		hiddenWallets := mw.enterDuressMode()
+		go func() {
+			if mw.IsConnectedToDecredNetwork() {
+				mw.CancelSync()
+				defer func() {
+					if mw.OpenedWalletsCount() > 0 {
+						mw.SpvSync()
+					}
+				}()
+			}
+
+			for _, wallet := range hiddenWallets {
+				wallet.Shutdown()
+			}
+		}()
+
+		return true
+	}
+}
+
+// enterDuressMode removes every wallet that is not a duress wallet from the
+// multiwallet and returns the removed wallets that had been opened, which
+// must be shut down by the caller.
+func (mw *MultiWallet) enterDuressMode() []*Wallet {
+	mw.duressMode = true
+
+	mw.walletsMu.Lock()
+	var openedWallets []*Wallet
+	for walletID, wallet := range mw.wallets {
+		if wallet.IsDuressWallet {
+			continue
+		}
+
+		if wallet.WalletOpened() {
+			openedWallets = append(openedWallets, wallet)
+		}
+		delete(mw.wallets, walletID)
+	}
+	mw.walletsMu.Unlock()
+
+	log.Infof("Loaded %d wallets", mw.LoadedWalletsCount())
+
+	return openedWallets
+}
+
+// isDuressPassphraseSet returns true if a duress passphrase is set.
+func (mw *MultiWallet) isDuressPassphraseSet() bool {
+	var duressPassphraseHash []byte
+	err := mw.db.Get(walletsMetadataBucketName, walletDuressPassphraseField, &duressPassphraseHash)
+	return err == nil && duressPassphraseHash != nil
+}
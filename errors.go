@@ -31,6 +31,13 @@ const (
 	ErrLoggerAlreadyRegistered      = "logger_already_registered"
 	ErrLogRotatorAlreadyInitialized = "log_rotator_already_initialized"
 	ErrAddressDiscoveryNotDone      = "address_discovery_not_done"
+	ErrTooManyAttempts              = "too_many_attempts"
+	ErrInvalidAmount                = "invalid_amount"
+	ErrExpired                      = "expired"
+	ErrDoubleSpend                  = "double_spend"
+	ErrTxRejected                   = "tx_rejected"
+	ErrHardwareWalletFailure        = "hardware_wallet_failure"
+	ErrVSPFailure                   = "vsp_failure"
 )
 
 // todo, should update this method to translate more error kinds.
@@ -45,6 +52,8 @@ func translateError(err error) error {
 			return errors.New(ErrInvalidPassphrase)
 		case errors.NoPeers:
 			return errors.New(ErrNoPeers)
+		case errors.DoubleSpend:
+			return errors.New(ErrDoubleSpend)
 		}
 	}
 	return err
@@ -0,0 +1,181 @@
+package dcrlibwallet
+
+import (
+	"sort"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// Confirmation targets for the fee rate options offered to users.
+const (
+	FeeTargetPriority int32 = 1
+	FeeTargetNormal   int32 = 3
+	FeeTargetEconomy  int32 = 6
+)
+
+// feeEstimateBlocks is the number of recent blocks whose transactions are
+// used to estimate fee rates.
+const feeEstimateBlocks = 6
+
+// recentFeeRates are the fee rates, in atoms/kB, paid by the regular
+// transactions of the recent blocks ending at tip, sorted from lowest to
+// highest. The fee rates of each block are kept by block hash, so that only
+// the blocks connected since the last estimate are fetched.
+type recentFeeRates struct {
+	tip           chainhash.Hash
+	feeRates      []int64
+	blockFeeRates map[chainhash.Hash][]int64
+}
+
+// EstimateFeeRate returns a recommended fee rate, in atoms/kB, for a
+// transaction to be mined within `confTarget` blocks. The estimate is a
+// percentile of the fee rates paid by the transactions of recent blocks,
+// which are fetched from the connected peers, higher for lower confirmation
+// targets. SPV wallets do not see the mempool, so the estimate does not
+// account for the transactions waiting to be mined. MinFeeRate is returned
+// if recent blocks have no transactions that paid a fee.
+func (mw *MultiWallet) EstimateFeeRate(confTarget int32) (int64, error) {
+	if confTarget < 1 {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	if !mw.IsSynced() {
+		return 0, errors.New(ErrNotConnected)
+	}
+
+	recent, err := mw.recentFeeRates()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(recent.feeRates) == 0 {
+		return MinFeeRate, nil
+	}
+
+	// higher percentiles of recently paid fee rates for faster confirmation
+	var percentile float64
+	switch {
+	case confTarget <= FeeTargetPriority:
+		percentile = 0.9
+	case confTarget <= FeeTargetNormal:
+		percentile = 0.5
+	default:
+		percentile = 0.2
+	}
+
+	feeRate := recent.feeRates[int(percentile*float64(len(recent.feeRates)-1))]
+	if feeRate < MinFeeRate {
+		return MinFeeRate, nil
+	}
+	if feeRate > MaxFeeRate {
+		return MaxFeeRate, nil
+	}
+	return feeRate, nil
+}
+
+// recentFeeRates returns the fee rates paid in the recent blocks, fetching the
+// blocks that were not read yet if the chain tip changed since the fee rates
+// were last computed.
+func (mw *MultiWallet) recentFeeRates() (*recentFeeRates, error) {
+	mw.feeRatesMu.Lock()
+	defer mw.feeRatesMu.Unlock()
+
+	var wallet *Wallet
+	for _, openedWallet := range mw.AllWallets() {
+		if openedWallet.WalletOpened() && (wallet == nil || openedWallet.GetBestBlock() > wallet.GetBestBlock()) {
+			wallet = openedWallet
+		}
+	}
+	if wallet == nil {
+		return nil, errors.New(ErrWalletNotLoaded)
+	}
+
+	ctx := wallet.shutdownContext()
+	tipHash, tipHeight := wallet.internal.MainChainTip(ctx)
+	if mw.feeRates != nil && mw.feeRates.tip == tipHash {
+		return mw.feeRates, nil
+	}
+
+	var cached map[chainhash.Hash][]int64
+	if mw.feeRates != nil {
+		cached = mw.feeRates.blockFeeRates
+	}
+
+	recent := &recentFeeRates{
+		tip:           tipHash,
+		feeRates:      make([]int64, 0),
+		blockFeeRates: make(map[chainhash.Hash][]int64, feeEstimateBlocks),
+	}
+
+	var missingBlocks []*chainhash.Hash
+	for height := tipHeight; height > tipHeight-feeEstimateBlocks && height > 0; height-- {
+		blockInfo, err := wallet.internal.BlockInfo(ctx, w.NewBlockIdentifierFromHeight(height))
+		if err != nil {
+			return nil, translateError(err)
+		}
+		hash := blockInfo.Hash
+		if feeRates, ok := cached[hash]; ok {
+			recent.blockFeeRates[hash] = feeRates
+			continue
+		}
+		missingBlocks = append(missingBlocks, &hash)
+	}
+
+	if len(missingBlocks) > 0 {
+		n, err := wallet.internal.NetworkBackend()
+		if err != nil {
+			return nil, errors.New(ErrNotConnected)
+		}
+
+		blocks, err := n.Blocks(ctx, missingBlocks)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		for _, block := range blocks {
+			// the first regular transaction is the coinbase
+			feeRates := make([]int64, 0, len(block.Transactions))
+			for i, tx := range block.Transactions {
+				if i == 0 {
+					continue
+				}
+				if feeRate, ok := txFeeRate(tx); ok {
+					feeRates = append(feeRates, feeRate)
+				}
+			}
+			recent.blockFeeRates[block.BlockHash()] = feeRates
+		}
+	}
+
+	for _, feeRates := range recent.blockFeeRates {
+		recent.feeRates = append(recent.feeRates, feeRates...)
+	}
+	sort.Slice(recent.feeRates, func(i, j int) bool {
+		return recent.feeRates[i] < recent.feeRates[j]
+	})
+
+	mw.feeRates = recent
+	return recent, nil
+}
+
+// txFeeRate returns the fee rate, in atoms/kB, paid by a mined transaction.
+// The fee is computed from the input amounts committed to by the transaction.
+func txFeeRate(tx *wire.MsgTx) (int64, bool) {
+	var fee int64
+	for _, txIn := range tx.TxIn {
+		fee += txIn.ValueIn
+	}
+	for _, txOut := range tx.TxOut {
+		fee -= txOut.Value
+	}
+
+	size := tx.SerializeSize()
+	if fee <= 0 || size == 0 {
+		return 0, false
+	}
+
+	return fee * 1000 / int64(size), true
+}
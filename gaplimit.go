@@ -0,0 +1,163 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/raedahgroup/dcrlibwallet/spv"
+)
+
+const (
+	// DefaultGapLimit is the number of consecutive unused addresses after
+	// which address discovery stops, unless a different gap limit is set
+	// for the wallet.
+	DefaultGapLimit int32 = 20
+
+	// MaxGapLimit is the highest gap limit that can be set for a wallet.
+	MaxGapLimit int32 = 1000
+)
+
+// GapLimit returns the address discovery gap limit of the wallet.
+func (wallet *Wallet) GapLimit() int32 {
+	return wallet.ReadInt32ConfigValueForKey(GapLimitConfigKey, DefaultGapLimit)
+}
+
+// SetGapLimitForWallet sets the address discovery gap limit of the specified
+// wallet. An opened wallet is reloaded to use the new gap limit, syncing is
+// restarted if it was canceled for the reload. `publicPassphrase` is required
+// to reopen wallets with a custom public passphrase.
+// Call `RediscoverAddresses` after the wallet is synced to discover addresses
+// beyond the previous gap limit.
+func (mw *MultiWallet) SetGapLimitForWallet(walletID int, gapLimit int32, publicPassphrase []byte) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if gapLimit < DefaultGapLimit || gapLimit > MaxGapLimit {
+		return errors.New(ErrInvalid)
+	}
+
+	if gapLimit == wallet.GapLimit() {
+		return nil
+	}
+
+	if !wallet.WalletOpened() {
+		wallet.SaveUserConfigValue(GapLimitConfigKey, gapLimit)
+		wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver, gapLimit)
+		return nil
+	}
+
+	if wallet.HasPublicPassphrase && len(publicPassphrase) == 0 {
+		return errors.New(ErrPassphraseRequired)
+	}
+	if !wallet.HasPublicPassphrase {
+		publicPassphrase = nil
+	}
+
+	if mw.IsRescanning() {
+		return errors.New(ErrInvalid)
+	}
+
+	if mw.IsConnectedToDecredNetwork() {
+		mw.CancelSync()
+		defer func() {
+			if mw.OpenedWalletsCount() > 0 {
+				mw.SpvSync()
+			}
+		}()
+	}
+
+	previousGapLimit := wallet.GapLimit()
+	previousLoader := wallet.loader
+
+	// unloading the wallet locks it, end the unlock session first so that
+	// the wallet lock listener is notified
+	wallet.LockWallet()
+
+	err := wallet.loader.UnloadWallet()
+	if err != nil {
+		return translateError(err)
+	}
+	wallet.internal = nil
+
+	wallet.SaveUserConfigValue(GapLimitConfigKey, gapLimit)
+	wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver, gapLimit)
+
+	err = wallet.openWallet(publicPassphrase)
+	if err != nil {
+		log.Errorf("[%d] Error reopening wallet after setting gap limit: %v", walletID, err)
+
+		// restore the previous gap limit and loader, which may be reused
+		// after the wallet is unloaded, so the wallet remains usable
+		wallet.SaveUserConfigValue(GapLimitConfigKey, previousGapLimit)
+		wallet.loader = previousLoader
+		if reopenErr := wallet.openWallet(publicPassphrase); reopenErr != nil {
+			log.Errorf("[%d] Error reopening wallet with the previous gap limit: %v", walletID, reopenErr)
+			return err
+		}
+
+		mw.startWalletListeners(walletID)
+		return err
+	}
+
+	mw.startWalletListeners(walletID)
+	return nil
+}
+
+// RediscoverAddresses discovers the addresses used by the specified wallet
+// using the wallet's gap limit, starting from the wallet's birthday, and then
+// rescans blocks for transactions involving the discovered addresses. Progress
+// of the rescan is reported to the blocks rescan progress listener, which is
+// also notified if discovery fails. Wallets must be synced.
+func (mw *MultiWallet) RediscoverAddresses(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	netBackend, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return errors.New(ErrNotConnected)
+	}
+
+	if mw.IsRescanning() || !mw.IsSynced() {
+		return errors.New(ErrInvalid)
+	}
+
+	go func() {
+		ctx := wallet.shutdownContext()
+
+		err := func() error {
+			startHeight, err := spv.BirthdayHeight(ctx, wallet.internal, wallet.Birthday)
+			if err != nil {
+				return err
+			}
+
+			startBlock, err := wallet.internal.BlockInfo(ctx, w.NewBlockIdentifierFromHeight(startHeight))
+			if err != nil {
+				return err
+			}
+
+			log.Infof("[%d] Discovering addresses with gap limit %d", walletID, wallet.GapLimit())
+			err = wallet.internal.DiscoverActiveAddresses(ctx, netBackend, &startBlock.Hash, false)
+			if err != nil {
+				return err
+			}
+
+			return mw.RescanBlocks(walletID)
+		}()
+
+		if err != nil {
+			log.Errorf("[%d] Error rediscovering addresses: %v", walletID, err)
+			if mw.blocksRescanProgressListener != nil {
+				mw.blocksRescanProgressListener.OnBlocksRescanEnded(walletID, translateError(err))
+			}
+		}
+	}()
+
+	return nil
+}
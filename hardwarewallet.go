@@ -0,0 +1,285 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/hdkeychain/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// Hardware wallet types supported by `CreateHardwareWallet`.
+const (
+	HardwareWalletTrezor = "trezor"
+)
+
+// HardwareWalletTransport is implemented by the host app to exchange messages
+// with a hardware wallet over USB, bluetooth or any other transport, so that
+// the signing flows in this library do not depend on a transport.
+type HardwareWalletTransport interface {
+	// Exchange writes a framed message to the device and returns the framed
+	// response of the device.
+	Exchange(message []byte) ([]byte, error)
+
+	// RequestPin asks the user for the device PIN, entered as the positions
+	// of the digits on the scrambled keypad shown by the device.
+	RequestPin() (string, error)
+
+	// RequestPassphrase asks the user for the device passphrase.
+	RequestPassphrase() (string, error)
+}
+
+// hardwareSigner is implemented for every supported hardware wallet type.
+type hardwareSigner interface {
+	publicKey(path []uint32) (string, error)
+	address(path []uint32, display bool) (string, error)
+	signTx(unsignedTx *wire.MsgTx, request *hardwareSignRequest) (*wire.MsgTx, error)
+}
+
+// hardwareSignRequest holds what a hardware signer needs to sign a
+// transaction besides the transaction: the derivation path of the key of each
+// input, the transactions that created the spent outputs and the index and
+// path of the change output, if any.
+type hardwareSignRequest struct {
+	inputPaths  [][]uint32
+	prevTxs     map[chainhash.Hash]*wire.MsgTx
+	changeIndex int
+	changePath  []uint32
+	chainParams dcrutil.AddressParams
+}
+
+func (mw *MultiWallet) hardwareSigner(walletType string, transport HardwareWalletTransport) (hardwareSigner, error) {
+	if transport == nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	switch walletType {
+	case HardwareWalletTrezor:
+		coinName := "Decred"
+		if mw.chainParams.Name != "mainnet" {
+			coinName = "Decred Testnet"
+		}
+		return &trezorDevice{transport: transport, coinName: coinName}, nil
+	default:
+		return nil, errors.New(ErrInvalid)
+	}
+}
+
+// hardwareAccountPath returns the BIP0044 derivation path of the specified
+// device account.
+func (mw *MultiWallet) hardwareAccountPath(account uint32) []uint32 {
+	return []uint32{
+		44 + hdkeychain.HardenedKeyStart,
+		mw.chainParams.SLIP0044CoinType + hdkeychain.HardenedKeyStart,
+		account + hdkeychain.HardenedKeyStart,
+	}
+}
+
+// CreateHardwareWallet creates a watching only wallet for the device account
+// `account` of a hardware wallet of type `walletType`, connected through
+// `transport`. Other accounts of the same device are added as other wallets.
+// Transactions of the wallet are signed by the device with
+// `BroadcastWithHardwareWallet`.
+func (mw *MultiWallet) CreateHardwareWallet(walletName, walletType string, transport HardwareWalletTransport,
+	account int32) (*Wallet, error) {
+
+	if account < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	signer, err := mw.hardwareSigner(walletType, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	extendedPublicKey, err := signer.publicKey(mw.hardwareAccountPath(uint32(account)))
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := mw.CreateWatchOnlyWallet(walletName, extendedPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.SetStringConfigValueForKey(HardwareWalletTypeConfigKey, walletType)
+	wallet.SetInt32ConfigValueForKey(HardwareWalletAccountConfigKey, account)
+
+	return wallet, nil
+}
+
+// IsHardwareWallet returns true if this wallet was created with
+// `CreateHardwareWallet`.
+func (wallet *Wallet) IsHardwareWallet() bool {
+	return wallet.HardwareWalletType() != ""
+}
+
+// HardwareWalletType returns the type of the hardware wallet of this wallet,
+// empty if this is not a hardware wallet.
+func (wallet *Wallet) HardwareWalletType() string {
+	return wallet.ReadStringConfigValueForKey(HardwareWalletTypeConfigKey, "")
+}
+
+// hardwareKeyPath returns the derivation path of the device key of an address
+// of a hardware wallet. The wallet watches a single device account, which may
+// be any account of the device, as wallet account 0; addresses of the other
+// wallet accounts, such as the imported account, have no device key.
+func (mw *MultiWallet) hardwareKeyPath(wallet *Wallet, walletAccount, branch, index uint32) ([]uint32, error) {
+	if walletAccount != 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	deviceAccount := wallet.ReadInt32ConfigValueForKey(HardwareWalletAccountConfigKey, -1)
+	if deviceAccount < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return append(mw.hardwareAccountPath(uint32(deviceAccount)), branch, index), nil
+}
+
+// VerifyAddressOnHardwareWallet shows `address` of this hardware wallet on the
+// device so the user can confirm that it belongs to the device before
+// receiving funds. Returns `ErrInvalidAddress` if the device derives a
+// different address.
+func (mw *MultiWallet) VerifyAddressOnHardwareWallet(walletID int, address string,
+	transport HardwareWalletTransport) error {
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil || !wallet.IsHardwareWallet() {
+		return errors.New(ErrNotExist)
+	}
+
+	signer, err := mw.hardwareSigner(wallet.HardwareWalletType(), transport)
+	if err != nil {
+		return err
+	}
+
+	addressInfo, err := wallet.AddressInfo(address)
+	if err != nil || !addressInfo.IsMine {
+		return errors.New(ErrInvalidAddress)
+	}
+
+	path, err := mw.hardwareKeyPath(wallet, addressInfo.AccountNumber, addressInfo.Branch, addressInfo.Index)
+	if err != nil {
+		return err
+	}
+
+	deviceAddress, err := signer.address(path, true)
+	if err != nil {
+		return err
+	}
+
+	if deviceAddress != address {
+		log.Errorf("[%d] Hardware wallet derived %s instead of %s", wallet.ID, deviceAddress, address)
+		return errors.New(ErrInvalidAddress)
+	}
+
+	return nil
+}
+
+// BroadcastWithHardwareWallet constructs `tx`, has it signed by the hardware
+// wallet of its source wallet and publishes the signed transaction. Returns
+// the hash of the published transaction.
+func (mw *MultiWallet) BroadcastWithHardwareWallet(tx *TxAuthor, transport HardwareWalletTransport) ([]byte, error) {
+	wallet := tx.sourceWallet
+	if !wallet.IsHardwareWallet() {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	signer, err := mw.hardwareSigner(wallet.HardwareWalletType(), transport)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTx, err := tx.constructTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if unsignedTx.ChangeIndex >= 0 {
+		unsignedTx.RandomizeChangePosition()
+	}
+
+	pkg, err := wallet.unsignedTxPackage(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &hardwareSignRequest{
+		inputPaths:  make([][]uint32, len(pkg.Inputs)),
+		changeIndex: unsignedTx.ChangeIndex,
+		chainParams: wallet.chainParams,
+	}
+
+	prevTxHashes := make([]*chainhash.Hash, 0, len(unsignedTx.Tx.TxIn))
+	for i, input := range pkg.Inputs {
+		if input.Address == "" {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		request.inputPaths[i], err = mw.hardwareKeyPath(wallet, input.Account, input.Branch, input.Index)
+		if err != nil {
+			return nil, err
+		}
+
+		prevTxHashes = append(prevTxHashes, &unsignedTx.Tx.TxIn[i].PreviousOutPoint.Hash)
+	}
+
+	prevTxs, _, err := wallet.internal.GetTransactionsByHashes(wallet.shutdownContext(), prevTxHashes)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	request.prevTxs = make(map[chainhash.Hash]*wire.MsgTx, len(prevTxs))
+	for _, prevTx := range prevTxs {
+		request.prevTxs[prevTx.TxHash()] = prevTx
+	}
+
+	if unsignedTx.ChangeIndex >= 0 {
+		request.changePath, err = mw.hardwareChangePath(wallet, unsignedTx.Tx.TxOut[unsignedTx.ChangeIndex])
+		if err != nil {
+			log.Warnf("[%d] Change output will be confirmed on the hardware wallet: %v", wallet.ID, err)
+		}
+	}
+
+	signedTx, err := signer.signTx(unsignedTx.Tx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var signedTxBuf bytes.Buffer
+	signedTxBuf.Grow(signedTx.SerializeSize())
+	err = signedTx.Serialize(&signedTxBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedPackage, err := EncodeUnsignedTxPackage(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.PublishSignedTransaction(encodedPackage, hex.EncodeToString(signedTxBuf.Bytes()))
+}
+
+// hardwareChangePath returns the derivation path of the device key of the
+// change output of a transaction of a hardware wallet.
+func (mw *MultiWallet) hardwareChangePath(wallet *Wallet, changeOutput *wire.TxOut) ([]uint32, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(changeOutput.Version, changeOutput.PkScript, wallet.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	addressInfo, err := wallet.AddressInfo(addrs[0].Address())
+	if err != nil {
+		return nil, err
+	}
+	if !addressInfo.IsMine {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	return mw.hardwareKeyPath(wallet, addressInfo.AccountNumber, addressInfo.Branch, addressInfo.Index)
+}
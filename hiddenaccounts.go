@@ -0,0 +1,90 @@
+package dcrlibwallet
+
+import (
+	"sort"
+)
+
+// hiddenAccountsConfigKey is the wallet config key for the numbers of the
+// accounts that are hidden.
+const hiddenAccountsConfigKey = "hidden_accounts"
+
+// SetAccountHidden hides or unhides the specified account. Hidden accounts
+// are excluded from `GetAccounts` and `TotalBalance` but are still synced,
+// and their transactions are still listed.
+func (wallet *Wallet) SetAccountHidden(accountNumber int32, hidden bool) error {
+	_, err := wallet.internal.AccountProperties(wallet.shutdownContext(), uint32(accountNumber))
+	if err != nil {
+		return translateError(err)
+	}
+
+	hiddenAccounts := wallet.hiddenAccounts()
+	if hiddenAccounts[accountNumber] == hidden {
+		return nil
+	}
+
+	if hidden {
+		hiddenAccounts[accountNumber] = true
+	} else {
+		delete(hiddenAccounts, accountNumber)
+	}
+
+	accountNumbers := make([]int32, 0, len(hiddenAccounts))
+	for hiddenAccount := range hiddenAccounts {
+		accountNumbers = append(accountNumbers, hiddenAccount)
+	}
+	sort.Slice(accountNumbers, func(i, j int) bool { return accountNumbers[i] < accountNumbers[j] })
+
+	wallet.SaveUserConfigValue(hiddenAccountsConfigKey, accountNumbers)
+	return nil
+}
+
+// IsAccountHidden returns true if the specified account is hidden.
+func (wallet *Wallet) IsAccountHidden(accountNumber int32) bool {
+	return wallet.hiddenAccounts()[accountNumber]
+}
+
+func (wallet *Wallet) hiddenAccounts() map[int32]bool {
+	var accountNumbers []int32
+	wallet.ReadUserConfigValue(hiddenAccountsConfigKey, &accountNumbers)
+
+	hiddenAccounts := make(map[int32]bool, len(accountNumbers))
+	for _, accountNumber := range accountNumbers {
+		hiddenAccounts[accountNumber] = true
+	}
+	return hiddenAccounts
+}
+
+// TotalBalance returns the total balance of the accounts of the wallet that
+// are not hidden.
+func (wallet *Wallet) TotalBalance() (int64, error) {
+	accounts, err := wallet.GetAccountsRaw()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var totalBalance int64
+	for _, account := range accounts.Acc {
+		totalBalance += account.TotalBalance
+	}
+
+	return totalBalance, nil
+}
+
+// TotalBalance returns the total balance of the opened wallets, excluding the
+// balances of hidden accounts.
+func (mw *MultiWallet) TotalBalance() (int64, error) {
+	var totalBalance int64
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		walletBalance, err := wallet.TotalBalance()
+		if err != nil {
+			return 0, err
+		}
+		totalBalance += walletBalance
+	}
+
+	return totalBalance, nil
+}
@@ -0,0 +1,282 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// Formats of the transaction history exported with
+// `ExportTransactionHistory`.
+const (
+	HistoryExportFormatJSON = "json"
+	HistoryExportFormatOFX  = "ofx"
+)
+
+// HistoryExportVersion is the version of the json transaction history
+// exported by this library. It is increased whenever fields are removed or
+// their meaning changes, so that importers can tell exports apart.
+const HistoryExportVersion = 1
+
+// HistoryExport is the json-encoded transaction history of a wallet, oldest
+// transaction first. From and To are the unix timestamps the history was
+// exported for, zero if not limited.
+type HistoryExport struct {
+	Version      int32                  `json:"version"`
+	Network      string                 `json:"network"`
+	WalletName   string                 `json:"wallet_name"`
+	ExportedAt   int64                  `json:"exported_at"`
+	From         int64                  `json:"from"`
+	To           int64                  `json:"to"`
+	Transactions []*ExportedTransaction `json:"transactions"`
+}
+
+// ExportedTransaction is a transaction in a `HistoryExport`. Amount and Fee
+// are in atoms, NetAmount is the signed change of the wallet balance caused
+// by the transaction, fee included. StakeType is "ticket", "vote" or
+// "revocation" for stake transactions and empty otherwise. Accounts are the
+// names of the wallet accounts the transaction spends from or pays to.
+// FiatValue is NetAmount in FiatCurrency at the FiatRate recorded when the
+// transaction was confirmed, the fiat fields are empty if no rate was
+// recorded.
+type ExportedTransaction struct {
+	Hash        string   `json:"hash"`
+	Timestamp   int64    `json:"timestamp"`
+	BlockHeight int32    `json:"block_height"`
+	Direction   string   `json:"direction"`
+	Type        string   `json:"type"`
+	StakeType   string   `json:"stake_type,omitempty"`
+	Amount      int64    `json:"amount"`
+	Fee         int64    `json:"fee"`
+	NetAmount   int64    `json:"net_amount"`
+	Accounts    []string `json:"accounts"`
+	Note        string   `json:"note,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	FiatCurrency string  `json:"fiat_currency,omitempty"`
+	FiatRate     float64 `json:"fiat_rate,omitempty"`
+	FiatValue    float64 `json:"fiat_value,omitempty"`
+}
+
+// ExportTransactionHistory returns the transaction history of this wallet
+// within the unix timestamps `from` and `to`, inclusive, in `format`: a
+// json-encoded `HistoryExport` for HistoryExportFormatJSON or an OFX 2.2
+// statement for HistoryExportFormatOFX, for accounting software. Zero
+// timestamps do not limit the history.
+func (wallet *Wallet) ExportTransactionHistory(format string, from, to int64) (string, error) {
+	history, err := wallet.ExportTransactionHistoryRaw(from, to)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case HistoryExportFormatJSON:
+		jsonEncodedHistory, err := json.Marshal(history)
+		if err != nil {
+			return "", err
+		}
+		return string(jsonEncodedHistory), nil
+
+	case HistoryExportFormatOFX:
+		return history.ofx()
+
+	default:
+		return "", errors.New(ErrInvalid)
+	}
+}
+
+func (wallet *Wallet) ExportTransactionHistoryRaw(from, to int64) (*HistoryExport, error) {
+	page, err := wallet.FilterTransactionsRaw(0, 0, &TransactionFilter{From: from, To: to}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &HistoryExport{
+		Version:      HistoryExportVersion,
+		Network:      wallet.chainParams.Name,
+		WalletName:   wallet.Name,
+		ExportedAt:   time.Now().Unix(),
+		From:         from,
+		To:           to,
+		Transactions: make([]*ExportedTransaction, len(page.Transactions)),
+	}
+
+	for i := range page.Transactions {
+		history.Transactions[i] = newExportedTransaction(&page.Transactions[i])
+	}
+
+	return history, nil
+}
+
+func newExportedTransaction(tx *Transaction) *ExportedTransaction {
+	exportedTx := &ExportedTransaction{
+		Hash:        tx.Hash,
+		Timestamp:   tx.Timestamp,
+		BlockHeight: tx.BlockHeight,
+		Type:        tx.Type,
+		Amount:      tx.Amount,
+		Fee:         tx.Fee,
+		Accounts:    transactionAccountNames(tx),
+		Note:        tx.Note,
+		Tags:        tx.Tags,
+	}
+
+	switch tx.Direction {
+	case txhelper.TxDirectionReceived:
+		exportedTx.Direction = "received"
+		exportedTx.NetAmount = tx.Amount
+	case txhelper.TxDirectionSent:
+		exportedTx.Direction = "sent"
+		exportedTx.NetAmount = -(tx.Amount + tx.Fee)
+	case txhelper.TxDirectionTransferred:
+		exportedTx.Direction = "transferred"
+		exportedTx.NetAmount = -tx.Fee
+	}
+
+	if tx.FiatRate > 0 {
+		exportedTx.FiatCurrency = tx.FiatCurrency
+		exportedTx.FiatRate = tx.FiatRate
+		exportedTx.FiatValue = dcrutil.Amount(exportedTx.NetAmount).ToCoin() * tx.FiatRate
+	}
+
+	switch tx.Type {
+	case txhelper.TxTypeTicketPurchase:
+		exportedTx.StakeType = "ticket"
+	case txhelper.TxTypeVote:
+		exportedTx.StakeType = "vote"
+	case txhelper.TxTypeRevocation:
+		exportedTx.StakeType = "revocation"
+	}
+
+	return exportedTx
+}
+
+// transactionAccountNames returns the names of the wallet accounts spent from
+// or paid to by `tx`, in the order they first appear.
+func transactionAccountNames(tx *Transaction) []string {
+	names := make([]string, 0)
+	seen := make(map[int32]bool)
+	addAccount := func(accountNumber int32, accountName string) {
+		if accountNumber < 0 || seen[accountNumber] {
+			return
+		}
+		seen[accountNumber] = true
+		names = append(names, accountName)
+	}
+
+	for _, input := range tx.Inputs {
+		addAccount(input.AccountNumber, input.AccountName)
+	}
+	for _, output := range tx.Outputs {
+		addAccount(output.AccountNumber, output.AccountName)
+	}
+
+	return names
+}
+
+// ofxTime formats a unix timestamp as an OFX datetime in UTC.
+func ofxTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// ofxAmount formats an amount in atoms as a signed DCR amount.
+func ofxAmount(atoms int64) string {
+	return strconv.FormatFloat(dcrutil.Amount(atoms).ToCoin(), 'f', -1, 64)
+}
+
+// ofx returns this history as an OFX 2.2 bank statement in DCR, with a
+// statement transaction for each transaction whose FITID is the transaction
+// hash so re-imported transactions are not duplicated.
+func (history *HistoryExport) ofx() (string, error) {
+	var buf bytes.Buffer
+	element := func(name, value string) {
+		buf.WriteString("<" + name + ">")
+		xml.EscapeText(&buf, []byte(value))
+		buf.WriteString("</" + name + ">\n")
+	}
+
+	start, end := history.From, history.To
+	if end == 0 {
+		end = history.ExportedAt
+	}
+	if start == 0 {
+		start = end
+		if len(history.Transactions) > 0 {
+			start = history.Transactions[0].Timestamp
+		}
+	}
+
+	// the ledger balance is the total change of the wallet balance caused by
+	// the exported transactions, which is the wallet balance only if the
+	// history is not limited.
+	var balance int64
+	for _, tx := range history.Transactions {
+		balance += tx.NetAmount
+	}
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	buf.WriteString("<OFX>\n<SIGNONMSGSRSV1>\n<SONRS>\n<STATUS>\n")
+	element("CODE", "0")
+	element("SEVERITY", "INFO")
+	buf.WriteString("</STATUS>\n")
+	element("DTSERVER", ofxTime(history.ExportedAt))
+	element("LANGUAGE", "ENG")
+	buf.WriteString("</SONRS>\n</SIGNONMSGSRSV1>\n")
+
+	buf.WriteString("<BANKMSGSRSV1>\n<STMTTRNRS>\n")
+	element("TRNUID", "0")
+	buf.WriteString("<STATUS>\n")
+	element("CODE", "0")
+	element("SEVERITY", "INFO")
+	buf.WriteString("</STATUS>\n<STMTRS>\n")
+	element("CURDEF", "DCR")
+	buf.WriteString("<BANKACCTFROM>\n")
+	element("BANKID", "decred-"+history.Network)
+	element("ACCTID", history.WalletName)
+	element("ACCTTYPE", "CHECKING")
+	buf.WriteString("</BANKACCTFROM>\n<BANKTRANLIST>\n")
+	element("DTSTART", ofxTime(start))
+	element("DTEND", ofxTime(end))
+
+	for _, tx := range history.Transactions {
+		trnType := "DEBIT"
+		if tx.NetAmount > 0 {
+			trnType = "CREDIT"
+		} else if tx.Direction == "transferred" {
+			trnType = "FEE"
+		}
+
+		memo := tx.Type
+		if tx.Note != "" {
+			memo = fmt.Sprintf("%s: %s", memo, tx.Note)
+		}
+		if len(tx.Tags) > 0 {
+			memo = fmt.Sprintf("%s [%s]", memo, strings.Join(tx.Tags, ", "))
+		}
+
+		buf.WriteString("<STMTTRN>\n")
+		element("TRNTYPE", trnType)
+		element("DTPOSTED", ofxTime(tx.Timestamp))
+		element("TRNAMT", ofxAmount(tx.NetAmount))
+		element("FITID", tx.Hash)
+		element("NAME", strings.Join(tx.Accounts, ", "))
+		element("MEMO", memo)
+		buf.WriteString("</STMTTRN>\n")
+	}
+
+	buf.WriteString("</BANKTRANLIST>\n<LEDGERBAL>\n")
+	element("BALAMT", ofxAmount(balance))
+	element("DTASOF", ofxTime(end))
+	buf.WriteString("</LEDGERBAL>\n</STMTRS>\n</STMTTRNRS>\n</BANKMSGSRSV1>\n</OFX>\n")
+
+	return buf.String(), nil
+}
@@ -0,0 +1,46 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/decred/dcrwallet/wallet/v3/udb"
+)
+
+// ImportedAccountNumber is the number of the account that holds imported
+// private keys and scripts. Funds of the imported account are only spent by
+// transactions created with the imported account as the source account.
+const ImportedAccountNumber = int32(udb.ImportedAddrAccount)
+
+// SweepImportedAccount sends all spendable funds of the imported account to
+// a new change address of `destinationAccount` and returns the hash of the
+// sweep transaction.
+func (wallet *Wallet) SweepImportedAccount(destinationAccount int32, privPass []byte) ([]byte, error) {
+	if destinationAccount == ImportedAccountNumber {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	spendable, err := wallet.SpendableForAccount(ImportedAccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if spendable <= 0 {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	destinationAddress, err := wallet.internal.NewInternalAddress(wallet.shutdownContext(), uint32(destinationAccount),
+		w.WithGapPolicyWrap())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	tx := wallet.newUnsignedTx(ImportedAccountNumber)
+	tx.AddSendDestination(destinationAddress.Address(), 0, true)
+
+	txHash, err := tx.Broadcast(privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("[%d] Swept imported account into account %d", wallet.ID, destinationAccount)
+	return txHash, nil
+}
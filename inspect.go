@@ -0,0 +1,156 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/raedahgroup/dcrlibwallet/utils"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keys of the address manager data read by `InspectWallet`.
+var (
+	addrmgrBucketKey     = []byte("waddrmgr")
+	addrmgrMainBucketKey = []byte("main")
+	addrmgrVersionKey    = []byte("mgrver")
+	addrmgrWatchOnlyKey  = []byte("watchonly")
+	addrmgrMasterPubKey  = []byte("mpub")
+)
+
+// WalletInspection describes a wallet database that has not been opened.
+// DbVersion, IsWatchingOnly and HasPublicPassphrase are only read from
+// bdb wallet databases.
+type WalletInspection struct {
+	Exists              bool   `json:"exists"`
+	DbDriver            string `json:"db_driver"`
+	DbVersion           uint32 `json:"db_version"`
+	NetType             string `json:"net_type"`
+	IsWatchingOnly      bool   `json:"is_watching_only"`
+	HasPublicPassphrase bool   `json:"has_public_passphrase"`
+}
+
+// InspectWallet returns the json-encoded `WalletInspection` of the wallet at
+// `dbPath`, which may be the path to a wallet.db file or to the directory that
+// contains the wallet.db file. The wallet database is read without opening the
+// wallet, so it fails with `ErrWalletDatabaseInUse` if the wallet is opened.
+func InspectWallet(dbPath string) (string, error) {
+	inspection, err := InspectWalletRaw(dbPath)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedInspection, err := json.Marshal(inspection)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedInspection), nil
+}
+
+func InspectWalletRaw(dbPath string) (*WalletInspection, error) {
+	if filepath.Base(dbPath) != walletDbName {
+		dbPath = filepath.Join(dbPath, walletDbName)
+	}
+
+	inspection := &WalletInspection{
+		NetType: netTypeFromPath(dbPath),
+	}
+
+	info, err := os.Stat(dbPath)
+	if os.IsNotExist(err) {
+		return inspection, nil
+	} else if err != nil {
+		return nil, err
+	}
+	inspection.Exists = true
+
+	// badger wallet databases are directories, the content of badger
+	// databases is not inspected.
+	if info.IsDir() {
+		inspection.DbDriver = "badgerdb"
+		return inspection, nil
+	}
+	inspection.DbDriver = dcrwalletDbDriver
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return nil, errors.New(ErrWalletDatabaseInUse)
+		}
+		return nil, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		addrmgrBucket := tx.Bucket(addrmgrBucketKey)
+		if addrmgrBucket == nil {
+			return errors.New(ErrInvalid)
+		}
+		mainBucket := addrmgrBucket.Bucket(addrmgrMainBucketKey)
+		if mainBucket == nil {
+			return errors.New(ErrInvalid)
+		}
+
+		if version := mainBucket.Get(addrmgrVersionKey); len(version) == 4 {
+			inspection.DbVersion = binary.LittleEndian.Uint32(version)
+		}
+
+		watchOnly := mainBucket.Get(addrmgrWatchOnlyKey)
+		inspection.IsWatchingOnly = len(watchOnly) == 1 && watchOnly[0] != 0
+
+		masterPubParams := mainBucket.Get(addrmgrMasterPubKey)
+		inspection.HasPublicPassphrase = !isDefaultPublicPassphrase(masterPubParams)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inspection, nil
+}
+
+// isDefaultPublicPassphrase checks if the master public key parameters were
+// derived from the default public passphrase. The parameters are serialized as
+// salt (32 bytes) || sha256 digest of key (32 bytes) || N || r || p, with the
+// scrypt parameters encoded as 8-byte little endian integers.
+func isDefaultPublicPassphrase(masterPubParams []byte) bool {
+	const keySize = 32
+	if len(masterPubParams) != keySize+sha256.Size+24 {
+		return false
+	}
+
+	salt := masterPubParams[:keySize]
+	digest := masterPubParams[keySize : keySize+sha256.Size]
+	scryptParams := masterPubParams[keySize+sha256.Size:]
+	n := int(binary.LittleEndian.Uint64(scryptParams[:8]))
+	r := int(binary.LittleEndian.Uint64(scryptParams[8:16]))
+	p := int(binary.LittleEndian.Uint64(scryptParams[16:]))
+
+	key, err := scrypt.Key([]byte(w.InsecurePubPassphrase), salt, n, r, p, keySize)
+	if err != nil {
+		return false
+	}
+
+	keyDigest := sha256.Sum256(key)
+	return bytes.Equal(keyDigest[:], digest)
+}
+
+// netTypeFromPath returns the name of the network of the first parent
+// directory of `dbPath` named after a network. Wallets created by
+// dcrlibwallet and dcrwallet are kept in network directories.
+func netTypeFromPath(dbPath string) string {
+	for dir := filepath.Dir(dbPath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if chainParams, err := utils.ChainParams(filepath.Base(dir)); err == nil {
+			return chainParams.Name
+		}
+	}
+	return ""
+}
@@ -7,6 +7,7 @@ package loader
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -402,6 +403,31 @@ func (l *Loader) UnloadWallet() error {
 	return nil
 }
 
+// CopyDB writes a copy of the database of the loaded wallet to w within a
+// database read transaction, so the copy is consistent while the wallet
+// remains open.  The database cannot be closed until the copy completes.
+func (l *Loader) CopyDB(w io.Writer) error {
+	const op errors.Op = "loader.CopyDB"
+
+	defer l.mu.Unlock()
+	l.mu.Lock()
+
+	if l.wallet == nil {
+		return errors.E(op, errors.Invalid, "wallet is unopened")
+	}
+
+	copier, ok := l.db.(interface{ Copy(io.Writer) error })
+	if !ok {
+		return errors.E(op, errors.Invalid, "database does not support copying")
+	}
+
+	err := copier.Copy(w)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	return nil
+}
+
 // NetworkBackend returns the associated wallet network backend, if any, and a
 // bool describing whether a non-nil network backend was set.
 func (l *Loader) NetworkBackend() (n wallet.NetworkBackend, ok bool) {
@@ -0,0 +1,112 @@
+package dcrlibwallet
+
+import (
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// importPrivateKey adds the WIF-encoded private key to the imported account
+// of this wallet and returns the address for the imported key.
+func (wallet *Wallet) importPrivateKey(privPass []byte, wif string) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+		lock <- time.Time{} // send matters, not the value
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+
+	decodedWIF, err := dcrutil.DecodeWIF(wif, wallet.chainParams.PrivateKeyID)
+	if err != nil {
+		log.Error(err)
+		return "", errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := wallet.internal.ImportPrivateKey(ctx, decodedWIF)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	log.Infof("[%d] Imported private key for address %s", wallet.ID, address)
+	return address, nil
+}
+
+// ImportPrivateKeyForWallet imports the WIF-encoded private key into the
+// imported account of the specified wallet. If `rescan` is true, the blocks
+// from `rescanHeight`, such as the height the key was first used at, are
+// rescanned to find the history of the imported key. Blocks from the wallet's
+// birthday height are rescanned if `rescanHeight` is 0. Rescanning requires
+// the multiwallet to be synced.
+func (mw *MultiWallet) ImportPrivateKeyForWallet(walletID int, privPass []byte, wif string, rescan bool,
+	rescanHeight int32) error {
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if rescanHeight < 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	_, err := wallet.importPrivateKey(privPass, wif)
+	if err != nil {
+		return err
+	}
+
+	if rescan && rescanHeight > 0 {
+		return mw.RescanBlocksFromHeight(walletID, rescanHeight)
+	}
+	if rescan {
+		return mw.RescanBlocks(walletID)
+	}
+
+	return nil
+}
+
+// DumpPrivateKey returns the WIF-encoded private key for the provided address
+// if the address is owned by this wallet. The wallet is only unlocked long
+// enough to derive the private key.
+func (wallet *Wallet) DumpPrivateKey(address string, privPass []byte) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+		lock <- time.Time{} // send matters, not the value
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+
+	addr, err := dcrutil.DecodeAddress(address, wallet.chainParams)
+	if err != nil {
+		return "", errors.New(ErrInvalidAddress)
+	}
+
+	ctx := wallet.shutdownContext()
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return "", err
+	}
+
+	wif, err := wallet.internal.DumpWIFPrivateKey(ctx, addr)
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return wif, nil
+}
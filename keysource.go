@@ -0,0 +1,48 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// KeySource is implemented by the host app to supply wallet private
+// passphrases from a secure key store, such as the Android Keystore or the
+// iOS Secure Enclave. When a key source is set, methods that require a
+// private passphrase may be called with an empty passphrase and the
+// passphrase is requested from the key source instead.
+type KeySource interface {
+	// PrivatePassphrase returns the private passphrase of the specified
+	// wallet, or nil if the key store holds no passphrase for the wallet.
+	// An error should be returned if the user declined to authorize
+	// access to the key store.
+	PrivatePassphrase(walletID int) ([]byte, error)
+}
+
+// SetKeySource sets the key source used to get wallet private passphrases
+// when an empty passphrase is provided. Pass nil to remove the key source.
+func (mw *MultiWallet) SetKeySource(keySource KeySource) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+	mw.keySource = keySource
+}
+
+// keySourcePassphraseFn returns the function called to get the private
+// passphrase of the specified wallet from the key source.
+func (mw *MultiWallet) keySourcePassphraseFn(walletID int) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		mw.notificationListenersMu.RLock()
+		keySource := mw.keySource
+		mw.notificationListenersMu.RUnlock()
+
+		if keySource == nil {
+			return nil, nil
+		}
+
+		privPass, err := keySource.PrivatePassphrase(walletID)
+		if err != nil {
+			log.Errorf("[%d] Error getting private passphrase from key source: %v", walletID, err)
+			return nil, errors.New(ErrPassphraseRequired)
+		}
+
+		return privPass, nil
+	}
+}
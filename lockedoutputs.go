@@ -0,0 +1,150 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/raedahgroup/dcrlibwallet/spv"
+)
+
+// lockedOutputsConfigKey is the wallet config key for the outputs locked with
+// `LockOutput`, saved as a map of outpoint to the tree of the outpoint.
+const lockedOutputsConfigKey = "locked_outputs"
+
+// LockOutput excludes the output at `outpoint`, formatted as hash:index, from
+// automatic input selection until it is unlocked with `UnlockOutput`.
+// Locked outputs are still included in the account balance and may still be
+// spent by setting them as inputs with `TxAuthor.SetInputs`. Locks are kept
+// when the wallet is reopened.
+func (wallet *Wallet) LockOutput(outpoint string) error {
+	op, err := wallet.walletOutPoint(outpoint)
+	if err != nil {
+		return err
+	}
+
+	wallet.internal.LockOutpoint(*op)
+
+	lockedOutputs := wallet.lockedOutputs()
+	lockedOutputs[outpoint] = op.Tree
+	wallet.SaveUserConfigValue(lockedOutputsConfigKey, lockedOutputs)
+	return nil
+}
+
+// UnlockOutput makes an output locked with `LockOutput` available for
+// automatic input selection again.
+func (wallet *Wallet) UnlockOutput(outpoint string) error {
+	lockedOutputs := wallet.lockedOutputs()
+	tree, ok := lockedOutputs[outpoint]
+	if !ok {
+		return errors.New(ErrNotExist)
+	}
+
+	op, err := parseOutPoint(outpoint, tree)
+	if err != nil {
+		return err
+	}
+
+	wallet.internal.UnlockOutpoint(*op)
+
+	delete(lockedOutputs, outpoint)
+	wallet.SaveUserConfigValue(lockedOutputsConfigKey, lockedOutputs)
+	return nil
+}
+
+// IsOutputLocked returns true if the output at `outpoint` was locked with
+// `LockOutput`.
+func (wallet *Wallet) IsOutputLocked(outpoint string) bool {
+	_, ok := wallet.lockedOutputs()[outpoint]
+	return ok
+}
+
+// ListLockedOutputs returns the json-encoded list of the outpoints locked with
+// `LockOutput`.
+func (wallet *Wallet) ListLockedOutputs() (string, error) {
+	lockedOutputs := wallet.lockedOutputs()
+	outpoints := make([]string, 0, len(lockedOutputs))
+	for outpoint := range lockedOutputs {
+		outpoints = append(outpoints, outpoint)
+	}
+	sort.Strings(outpoints)
+
+	jsonEncodedOutpoints, err := json.Marshal(outpoints)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedOutpoints), nil
+}
+
+func (wallet *Wallet) lockedOutputs() map[string]int8 {
+	lockedOutputs := make(map[string]int8)
+	wallet.ReadUserConfigValue(lockedOutputsConfigKey, &lockedOutputs)
+	return lockedOutputs
+}
+
+// restoreLockedOutputs locks the outputs saved with `LockOutput` and the
+// inputs of unconfirmed VSP fee transactions in the opened wallet. Output
+// locks are not persisted by the wallet itself.
+func (wallet *Wallet) restoreLockedOutputs() {
+	lockedOutputs := wallet.lockedOutputs()
+	if wallet.vspFeeInputs != nil {
+		for outpoint, tree := range wallet.vspFeeInputs() {
+			lockedOutputs[outpoint] = tree
+		}
+	}
+
+	for outpoint, tree := range lockedOutputs {
+		op, err := parseOutPoint(outpoint, tree)
+		if err != nil {
+			log.Errorf("[%d] Invalid locked output %s: %v", wallet.ID, outpoint, err)
+			continue
+		}
+		wallet.internal.LockOutpoint(*op)
+	}
+}
+
+// walletOutPoint returns the outpoint of the output at `outpoint`, formatted
+// as hash:index, if the output belongs to a transaction of the wallet.
+func (wallet *Wallet) walletOutPoint(outpoint string) (*wire.OutPoint, error) {
+	op, err := parseOutPoint(outpoint, wire.TxTreeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	txDetails, err := wallet.internal.TxDetails(wallet.shutdownContext(), &op.Hash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if int(op.Index) >= len(txDetails.MsgTx.TxOut) {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	op.Tree = spv.TxTree(&txDetails.MsgTx)
+	return op, nil
+}
+
+// parseOutPoint parses an outpoint formatted as hash:index.
+func parseOutPoint(outpoint string, tree int8) (*wire.OutPoint, error) {
+	parts := strings.Split(outpoint, ":")
+	if len(parts) != 2 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	hash, err := chainhash.NewHashFromStr(parts[0])
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return wire.NewOutPoint(hash, uint32(index), tree), nil
+}
@@ -10,15 +10,19 @@ import (
 )
 
 func (wallet *Wallet) SignMessage(passphrase []byte, address string, message string) ([]byte, error) {
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
 	lock := make(chan time.Time, 1)
 	defer func() {
 		lock <- time.Time{}
 	}()
 
 	ctx := wallet.shutdownContext()
-	err := wallet.internal.Unlock(ctx, passphrase, lock)
+	err := wallet.unlock(ctx, passphrase, lock)
 	if err != nil {
-		return nil, translateError(err)
+		return nil, err
 	}
 
 	addr, err := dcrutil.DecodeAddress(address, wallet.chainParams)
@@ -0,0 +1,57 @@
+package dcrlibwallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// dcrwalletDbDriver is the database driver used by dcrwallet desktop wallets.
+const dcrwalletDbDriver = "bdb"
+
+// MigrateFromDcrwallet adds the wallet in a dcrwallet data directory as a new
+// wallet. `dataDir` may be the dcrwallet app data directory, in which case the
+// wallet for the multiwallet's network is used, or the network directory that
+// contains the wallet.db file. The wallet.db file is copied, so the desktop
+// wallet remains usable. The wallet database is upgraded to the latest version
+// when it is opened, accounts and sync state are kept so the seed need not be
+// entered and address discovery need not be repeated.
+func (mw *MultiWallet) MigrateFromDcrwallet(walletName, dataDir, publicPassphrase string, privatePassphraseType int32) (*Wallet, error) {
+	walletDataDir := dataDir
+	if !WalletExistsAt(walletDataDir) {
+		// dcrwallet keeps the wallet for each network in a sub directory
+		// named after the network.
+		walletDataDir = filepath.Join(dataDir, mw.chainParams.Name)
+		if !WalletExistsAt(walletDataDir) {
+			return nil, errors.New(ErrNotExist)
+		}
+	}
+
+	tempDir, err := ioutil.TempDir(mw.rootDir, "migrate")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = copyFile(filepath.Join(walletDataDir, walletDbName), filepath.Join(tempDir, walletDbName))
+	if err != nil {
+		log.Errorf("Error copying dcrwallet db: %v", err)
+		return nil, err
+	}
+
+	wallet, err := mw.linkExistingWallet(walletName, tempDir, publicPassphrase, privatePassphraseType, dcrwalletDbDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	// accounts have already been discovered by dcrwallet, the wallet need not
+	// be unlocked for account discovery.
+	if err = mw.markWalletAsDiscoveredAccounts(wallet.ID); err != nil {
+		log.Errorf("[%d] Error marking migrated wallet accounts as discovered: %v", wallet.ID, err)
+	}
+
+	log.Infof("[%d] Migrated dcrwallet wallet from %s", wallet.ID, walletDataDir)
+	return wallet, nil
+}
@@ -0,0 +1,136 @@
+package dcrlibwallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// MultisigAddress is a P2SH address paying to an m-of-n multisig script.
+type MultisigAddress struct {
+	Address      string `json:"address"`
+	RedeemScript string `json:"redeem_script"`
+}
+
+// CreateMultisigAddress returns the json-encoded `MultisigAddress` that
+// requires `requiredSigs` signatures of the keys in `pubKeys`, a comma
+// separated list of hex-encoded compressed secp256k1 public keys. The redeem
+// script must be imported with `ImportRedeemScript` for the wallet to watch
+// funds sent to the address.
+func (wallet *Wallet) CreateMultisigAddress(requiredSigs int32, pubKeys string) (string, error) {
+	multisigAddress, err := wallet.CreateMultisigAddressRaw(requiredSigs, pubKeys)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedAddress, err := json.Marshal(multisigAddress)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedAddress), nil
+}
+
+func (wallet *Wallet) CreateMultisigAddressRaw(requiredSigs int32, pubKeys string) (*MultisigAddress, error) {
+	var keys []*dcrutil.AddressSecpPubKey
+	for _, pubKey := range strings.Split(pubKeys, ",") {
+		pubKeyBytes, err := hex.DecodeString(strings.TrimSpace(pubKey))
+		if err != nil {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		key, err := dcrutil.NewAddressSecpPubKey(pubKeyBytes, wallet.chainParams)
+		if err != nil {
+			return nil, errors.New(ErrInvalid)
+		}
+		keys = append(keys, key)
+	}
+
+	if requiredSigs < 1 || int(requiredSigs) > len(keys) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	redeemScript, err := txscript.MultiSigScript(keys, int(requiredSigs))
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	address, err := dcrutil.NewAddressScriptHash(redeemScript, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultisigAddress{
+		Address:      address.Address(),
+		RedeemScript: hex.EncodeToString(redeemScript),
+	}, nil
+}
+
+// ImportRedeemScript adds the hex-encoded redeem script to the imported
+// account of this wallet and returns the P2SH address of the script. Funds
+// sent to the address are watched by the wallet and counted in the balance of
+// the imported account, but are receive-only: the wallet does not sign P2SH
+// inputs, so they are never selected to fund transactions and cannot be set
+// as inputs with `SetInputs`.
+func (wallet *Wallet) ImportRedeemScript(privPass []byte, redeemScript string) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+		lock <- time.Time{} // send matters, not the value
+	}()
+
+	script, err := hex.DecodeString(redeemScript)
+	if err != nil || len(script) == 0 {
+		return "", errors.New(ErrInvalid)
+	}
+
+	address, err := dcrutil.NewAddressScriptHash(script, wallet.chainParams)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	if !wallet.IsWatchingOnlyWallet() {
+		err = wallet.unlock(ctx, privPass, lock)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = wallet.internal.ImportScript(ctx, script)
+	if err != nil && !errors.Is(errors.Exist, err) {
+		return "", translateError(err)
+	}
+
+	log.Infof("[%d] Imported redeem script for address %s", wallet.ID, address.Address())
+	return address.Address(), nil
+}
+
+// ImportRedeemScriptForWallet imports the hex-encoded redeem script into the
+// imported account of the specified wallet. If `rescan` is true, the blocks
+// from the wallet's birthday height are rescanned to find the history of the
+// script's address. Rescanning requires the multiwallet to be synced.
+func (mw *MultiWallet) ImportRedeemScriptForWallet(walletID int, privPass []byte, redeemScript string, rescan bool) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	_, err := wallet.ImportRedeemScript(privPass, redeemScript)
+	if err != nil {
+		return err
+	}
+
+	if rescan {
+		return mw.RescanBlocks(walletID)
+	}
+
+	return nil
+}
@@ -28,12 +28,56 @@ type MultiWallet struct {
 	db       *storm.DB
 
 	chainParams *chaincfg.Params
-	wallets     map[int]*Wallet
 	syncData    *syncData
 
+	// walletsMu guards wallets, which is also updated from the unlock path
+	// when the duress passphrase hides wallets.
+	walletsMu sync.RWMutex
+	wallets   map[int]*Wallet
+
 	notificationListenersMu         sync.RWMutex
 	txAndBlockNotificationListeners map[string]TxAndBlockNotificationListener
+	txConflictListeners             map[string]TxConflictListener
 	blocksRescanProgressListener    BlocksRescanProgressListener
+	walletDeletionListener          WalletDeletionListener
+	walletLockListener              WalletLockListener
+	walletWipeListener              WalletWipeListener
+	configChangeListeners           map[string]ConfigChangeListener
+	accountNotificationListeners    map[string]AccountNotificationListener
+	watchedAddressListeners         map[string]WatchedAddressListener
+	scheduledPaymentListener        ScheduledPaymentListener
+	ticketBuyerListener             TicketBuyerListener
+	ticketListener                  TicketListener
+
+	// watchedAddressesMu serializes updates to watched addresses.
+	watchedAddressesMu sync.Mutex
+
+	// walletWipeMu serializes wipes of wallets that exceeded the maximum
+	// number of failed unlock attempts.
+	walletWipeMu sync.Mutex
+
+	// scheduledPaymentsMu serializes runs of due scheduled payments.
+	scheduledPaymentsMu sync.Mutex
+
+	// ticketBuyers are the running ticket buyers by wallet ID.
+	ticketBuyersMu sync.Mutex
+	ticketBuyers   map[int]*ticketBuyer
+
+	// feeRates caches the fee rates paid in recent blocks for fee estimation.
+	feeRatesMu sync.Mutex
+	feeRates   *recentFeeRates
+
+	// keySource supplies wallet private passphrases from the host app's
+	// secure key store, if set.
+	keySource KeySource
+
+	// exchangeRateSource supplies the exchange rates recorded for confirmed
+	// transactions, if set.
+	exchangeRateSource ExchangeRateSource
+
+	// duressMode is true if the wallets were opened using the duress
+	// passphrase, only duress wallets are loaded in duress mode.
+	duressMode bool
 
 	shuttingDown chan bool
 	cancelFuncs  []context.CancelFunc
@@ -86,23 +130,23 @@ func NewMultiWallet(rootDir, dbDriver, netType string) (*MultiWallet, error) {
 			syncProgressListeners: make(map[string]SyncProgressListener),
 		},
 		txAndBlockNotificationListeners: make(map[string]TxAndBlockNotificationListener),
+		txConflictListeners:             make(map[string]TxConflictListener),
+		configChangeListeners:           make(map[string]ConfigChangeListener),
+		accountNotificationListeners:    make(map[string]AccountNotificationListener),
+		watchedAddressListeners:         make(map[string]WatchedAddressListener),
+		ticketBuyers:                    make(map[int]*ticketBuyer),
 	}
 
-	// read saved wallets info from db and initialize wallets
-	query := mw.db.Select(q.True()).OrderBy("ID")
-	var wallets []*Wallet
-	err = query.Find(&wallets)
-	if err != nil && err != storm.ErrNotFound {
-		return nil, err
+	// read saved wallets info from db and initialize wallets. If a duress
+	// passphrase is set, the wallets that are not duress wallets are only
+	// read once the startup passphrase is verified, see `OpenWallets`.
+	if mw.isDuressPassphraseSet() {
+		err = mw.loadWallets(mw.db.Select(q.Eq("IsDuressWallet", true)).OrderBy("ID"))
+	} else {
+		err = mw.loadWallets(mw.db.Select(q.True()).OrderBy("ID"))
 	}
-
-	// prepare the wallets loaded from db for use
-	for _, wallet := range wallets {
-		err = wallet.prepare(rootDir, chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
-		if err != nil {
-			return nil, err
-		}
-		mw.wallets[wallet.ID] = wallet
+	if err != nil {
+		return nil, err
 	}
 
 	mw.listenForShutdown()
@@ -115,6 +159,33 @@ func NewMultiWallet(rootDir, dbDriver, netType string) (*MultiWallet, error) {
 	return mw, nil
 }
 
+// loadWallets reads the wallets selected by `query` from the db and prepares
+// them for use. Wallets that are already loaded are skipped.
+func (mw *MultiWallet) loadWallets(query storm.Query) error {
+	var wallets []*Wallet
+	err := query.Find(&wallets)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	for _, wallet := range wallets {
+		if mw.WalletWithID(wallet.ID) != nil {
+			continue
+		}
+
+		err = wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID),
+			mw.walletHooks(wallet.ID))
+		if err != nil {
+			return err
+		}
+		mw.walletsMu.Lock()
+		mw.wallets[wallet.ID] = wallet
+		mw.walletsMu.Unlock()
+	}
+
+	return nil
+}
+
 func (mw *MultiWallet) Shutdown() {
 	log.Info("Shutting down dcrlibwallet")
 
@@ -124,7 +195,7 @@ func (mw *MultiWallet) Shutdown() {
 	mw.CancelRescan()
 	mw.CancelSync()
 
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		wallet.Shutdown()
 	}
 
@@ -146,7 +217,57 @@ func (mw *MultiWallet) SetStartupPassphrase(passphrase []byte, passphraseType in
 	return mw.ChangeStartupPassphrase([]byte(""), passphrase, passphraseType)
 }
 
+// VerifyStartupPassphrase checks the startup passphrase. Failed attempts are
+// counted and further attempts are rejected with `ErrTooManyAttempts` until
+// the backoff delay for the number of consecutive failed attempts elapses.
 func (mw *MultiWallet) VerifyStartupPassphrase(startupPassphrase []byte) error {
+	if mw.StartupPassphraseBackoffRemaining() > 0 {
+		return errors.New(ErrTooManyAttempts)
+	}
+
+	err := mw.verifyStartupPassphrase(startupPassphrase)
+	if err != nil {
+		// the duress passphrase is not counted as a failed attempt
+		if !mw.verifyDuressPassphrase(startupPassphrase) {
+			mw.recordFailedStartupPassphraseAttempt()
+		}
+		return err
+	}
+
+	if mw.StartupPassphraseFailedAttempts() > 0 {
+		mw.db.Delete(walletsMetadataBucketName, startupPassphraseFailedAttemptsField)
+	}
+
+	return nil
+}
+
+func (mw *MultiWallet) recordFailedStartupPassphraseAttempt() {
+	err := mw.db.Set(walletsMetadataBucketName, startupPassphraseFailedAttemptsField, mw.StartupPassphraseFailedAttempts()+1)
+	if err == nil {
+		err = mw.db.Set(walletsMetadataBucketName, startupPassphraseLastFailedField, time.Now().Unix())
+	}
+	if err != nil {
+		log.Errorf("error recording failed startup passphrase attempt: %v", err)
+	}
+}
+
+// StartupPassphraseFailedAttempts returns the number of consecutive failed
+// attempts to verify the startup passphrase.
+func (mw *MultiWallet) StartupPassphraseFailedAttempts() int32 {
+	var failedAttempts int32
+	mw.db.Get(walletsMetadataBucketName, startupPassphraseFailedAttemptsField, &failedAttempts)
+	return failedAttempts
+}
+
+// StartupPassphraseBackoffRemaining returns the number of seconds before the
+// next attempt to verify the startup passphrase is allowed.
+func (mw *MultiWallet) StartupPassphraseBackoffRemaining() int64 {
+	var lastFailedAttempt int64
+	mw.db.Get(walletsMetadataBucketName, startupPassphraseLastFailedField, &lastFailedAttempt)
+	return unlockBackoffRemaining(mw.StartupPassphraseFailedAttempts(), lastFailedAttempt)
+}
+
+func (mw *MultiWallet) verifyStartupPassphrase(startupPassphrase []byte) error {
 	var startupPassphraseHash []byte
 	err := mw.db.Get(walletsMetadataBucketName, walletstartupPassphraseField, &startupPassphraseHash)
 	if err != nil && err != storm.ErrNotFound {
@@ -180,6 +301,10 @@ func (mw *MultiWallet) ChangeStartupPassphrase(oldPassphrase, newPassphrase []by
 		return err
 	}
 
+	if mw.verifyDuressPassphrase(newPassphrase) {
+		return errors.New(ErrInvalid)
+	}
+
 	startupPassphraseHash, err := bcrypt.GenerateFromPassword(newPassphrase, bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -207,6 +332,12 @@ func (mw *MultiWallet) RemoveStartupPassphrase(oldPassphrase []byte) error {
 		return err
 	}
 
+	// the duress passphrase cannot be used without a startup passphrase
+	err = mw.removeDuressPassphrase()
+	if err != nil {
+		return err
+	}
+
 	mw.SaveUserConfigValue(IsStartupSecuritySetConfigKey, false)
 	mw.DeleteUserConfigValueForKey(StartupSecurityTypeConfigKey)
 
@@ -228,30 +359,80 @@ func (mw *MultiWallet) OpenWallets(startupPassphrase []byte) error {
 
 	err := mw.VerifyStartupPassphrase(startupPassphrase)
 	if err != nil {
-		return err
+		if !mw.verifyDuressPassphrase(startupPassphrase) {
+			return err
+		}
+		for _, wallet := range mw.enterDuressMode() {
+			wallet.Shutdown()
+		}
+	} else if !mw.duressMode {
+		// the wallets that are not duress wallets are not loaded until the
+		// startup passphrase is verified
+		err = mw.loadWallets(mw.db.Select(q.True()).OrderBy("ID"))
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, wallet := range mw.wallets {
-		err = wallet.openWallet()
+	for _, wallet := range mw.AllWallets() {
+		// wallets with a custom public passphrase
+		// must be opened individually using `OpenWallet`.
+		if wallet.WalletOpened() || wallet.HasPublicPassphrase {
+			continue
+		}
+
+		err = wallet.openWallet(nil)
 		if err != nil {
 			return err
 		}
 
-		go mw.listenForTransactions(wallet.ID)
+		mw.startWalletListeners(wallet.ID)
+	}
+
+	return nil
+}
+
+// OpenWallet opens the wallet with the specified ID using the provided public
+// passphrase. The default public passphrase is used if `publicPassphrase` is empty.
+func (mw *MultiWallet) OpenWallet(walletID int, publicPassphrase []byte) error {
+	if mw.IsSyncing() {
+		return errors.New(ErrSyncAlreadyInProgress)
+	}
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if wallet.WalletOpened() {
+		return nil
+	}
+
+	err := wallet.openWallet(publicPassphrase)
+	if err != nil {
+		return err
 	}
 
+	mw.startWalletListeners(wallet.ID)
 	return nil
 }
 
 func (mw *MultiWallet) CreateWatchOnlyWallet(walletName, extendedPublicKey string) (*Wallet, error) {
+	err := mw.ValidateExtPubKey(extendedPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
 	wallet := &Wallet{
 		Name:                  walletName,
+		CreatedAt:             time.Now(),
 		IsRestored:            true,
 		HasDiscoveredAccounts: true,
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID),
+			mw.walletHooks(wallet.ID))
 		if err != nil {
 			return err
 		}
@@ -261,48 +442,177 @@ func (mw *MultiWallet) CreateWatchOnlyWallet(walletName, extendedPublicKey strin
 }
 
 func (mw *MultiWallet) CreateNewWallet(walletName, privatePassphrase string, privatePassphraseType int32) (*Wallet, error) {
+	return mw.CreateNewWalletWithSeedPassphrase(walletName, privatePassphrase, privatePassphraseType, "")
+}
+
+// CreateNewWalletWithSeedPassphrase creates a new wallet whose keys are derived
+// from the generated seed and `seedPassphrase`. The same seed passphrase must
+// be provided when restoring the wallet from its seed, a different passphrase
+// restores a different wallet.
+func (mw *MultiWallet) CreateNewWalletWithSeedPassphrase(walletName, privatePassphrase string, privatePassphraseType int32,
+	seedPassphrase string) (*Wallet, error) {
+
+	return mw.CreateNewWalletWithCoinType(walletName, privatePassphrase, privatePassphraseType, seedPassphrase,
+		int32(mw.chainParams.LegacyCoinType))
+}
+
+// CreateNewWalletWithCoinType creates a new wallet like
+// `CreateNewWalletWithSeedPassphrase`, whose accounts are derived with the
+// BIP0044 coin type `coinType`, either the legacy or the SLIP0044 coin type of
+// the network. Other coin types are not supported by the wallet.
+func (mw *MultiWallet) CreateNewWalletWithCoinType(walletName, privatePassphrase string, privatePassphraseType int32,
+	seedPassphrase string, coinType int32) (*Wallet, error) {
+
+	options := &WalletSetupOptions{
+		SeedPassphrase: seedPassphrase,
+		CoinType:       coinType,
+	}
+	return mw.CreateNewWalletWithOptions(walletName, privatePassphrase, privatePassphraseType, options)
+}
+
+// CreateNewWalletWithOptions creates a new wallet with the settings of
+// `options`, which may be nil to use the default settings.
+func (mw *MultiWallet) CreateNewWalletWithOptions(walletName, privatePassphrase string, privatePassphraseType int32,
+	options *WalletSetupOptions) (*Wallet, error) {
+
+	coinType, err := mw.walletSetupCoinType(options)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &WalletSetupOptions{}
+	}
+
 	seed, err := GenerateSeed()
 	if err != nil {
 		return nil, err
 	}
 
+	createdAt := time.Now()
 	wallet := &Wallet{
 		Name:                  walletName,
-		CreatedAt:             time.Now(),
+		CreatedAt:             createdAt,
+		Birthday:              createdAt,
 		Seed:                  seed,
 		PrivatePassphraseType: privatePassphraseType,
 		HasDiscoveredAccounts: true,
+		HasSeedPassphrase:     options.SeedPassphrase != "",
+		HasPublicPassphrase:   isCustomPublicPassphrase(options.PublicPassphrase),
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID),
+			mw.walletHooks(wallet.ID))
 		if err != nil {
 			return err
 		}
 
-		return wallet.createWallet(privatePassphrase, seed)
+		return wallet.createWallet(options.PublicPassphrase, privatePassphrase, seed, options.SeedPassphrase, coinType)
 	})
 }
 
 func (mw *MultiWallet) RestoreWallet(walletName, seedMnemonic, privatePassphrase string, privatePassphraseType int32) (*Wallet, error) {
+	return mw.RestoreWalletWithSeedPassphrase(walletName, seedMnemonic, privatePassphrase, privatePassphraseType, "")
+}
+
+// RestoreWalletWithSeedPassphrase restores a wallet from `seedMnemonic` and the
+// `seedPassphrase` that was used when the wallet was created.
+func (mw *MultiWallet) RestoreWalletWithSeedPassphrase(walletName, seedMnemonic, privatePassphrase string,
+	privatePassphraseType int32, seedPassphrase string) (*Wallet, error) {
+
+	return mw.RestoreWalletWithCoinType(walletName, seedMnemonic, privatePassphrase, privatePassphraseType,
+		seedPassphrase, int32(mw.chainParams.LegacyCoinType))
+}
+
+// RestoreWalletWithCoinType restores a wallet like
+// `RestoreWalletWithSeedPassphrase`, deriving its accounts with the BIP0044
+// coin type `coinType`, either the legacy or the SLIP0044 coin type of the
+// network, to recover the funds of wallets that used the SLIP0044 coin type.
+// Other coin types are not supported by the wallet.
+func (mw *MultiWallet) RestoreWalletWithCoinType(walletName, seedMnemonic, privatePassphrase string,
+	privatePassphraseType int32, seedPassphrase string, coinType int32) (*Wallet, error) {
+
+	options := &WalletSetupOptions{
+		SeedPassphrase: seedPassphrase,
+		CoinType:       coinType,
+	}
+	return mw.RestoreWalletWithOptions(walletName, seedMnemonic, privatePassphrase, privatePassphraseType, options)
+}
+
+// RestoreWalletWithOptions restores a wallet from `seedMnemonic` with the
+// settings of `options`, which may be nil to use the default settings.
+func (mw *MultiWallet) RestoreWalletWithOptions(walletName, seedMnemonic, privatePassphrase string,
+	privatePassphraseType int32, options *WalletSetupOptions) (*Wallet, error) {
+
+	coinType, err := mw.walletSetupCoinType(options)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &WalletSetupOptions{}
+	}
+
+	birthday, err := walletBirthday(options.Birthday)
+	if err != nil {
+		return nil, err
+	}
+
+	// the birthday is saved with the wallet, before the sync that discovers
+	// the restored wallet's addresses is started.
 	wallet := &Wallet{
 		Name:                  walletName,
+		CreatedAt:             time.Now(),
+		Birthday:              birthday,
 		PrivatePassphraseType: privatePassphraseType,
 		IsRestored:            true,
 		HasDiscoveredAccounts: false,
+		HasSeedPassphrase:     options.SeedPassphrase != "",
+		HasPublicPassphrase:   isCustomPublicPassphrase(options.PublicPassphrase),
 	}
 
 	return mw.saveNewWallet(wallet, func() error {
-		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+		err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID),
+			mw.walletHooks(wallet.ID))
 		if err != nil {
 			return err
 		}
 
-		return wallet.createWallet(privatePassphrase, seedMnemonic)
+		return wallet.createWallet(options.PublicPassphrase, privatePassphrase, seedMnemonic, options.SeedPassphrase, coinType)
 	})
 }
 
+// walletSetupCoinType returns the coin type set in `options`, or the legacy
+// coin type if `options` is nil or sets no coin type. Returns `ErrInvalid` if
+// wallets cannot derive their accounts with the coin type.
+func (mw *MultiWallet) walletSetupCoinType(options *WalletSetupOptions) (uint32, error) {
+	if options == nil || options.CoinType == 0 {
+		return mw.chainParams.LegacyCoinType, nil
+	}
+
+	coinType := options.CoinType
+	if coinType < 0 || (uint32(coinType) != mw.chainParams.LegacyCoinType &&
+		uint32(coinType) != mw.chainParams.SLIP0044CoinType) {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	return uint32(coinType), nil
+}
+
+// isCustomPublicPassphrase returns true if `publicPassphrase` is neither
+// empty nor the default public passphrase.
+func isCustomPublicPassphrase(publicPassphrase string) bool {
+	return publicPassphrase != "" && publicPassphrase != w.InsecurePubPassphrase
+}
+
 func (mw *MultiWallet) LinkExistingWallet(walletName, walletDataDir, originalPubPass string, privatePassphraseType int32) (*Wallet, error) {
+	return mw.linkExistingWallet(walletName, walletDataDir, originalPubPass, privatePassphraseType, mw.dbDriver)
+}
+
+// linkExistingWallet moves the wallet.db file or directory in `walletDataDir`
+// into a new wallet's data dir. `dbDriver` is the driver of the wallet db.
+func (mw *MultiWallet) linkExistingWallet(walletName, walletDataDir, originalPubPass string, privatePassphraseType int32,
+	dbDriver string) (*Wallet, error) {
+
 	// check if `walletDataDir` contains wallet.db
 	if !WalletExistsAt(walletDataDir) {
 		return nil, errors.New(ErrNotExist)
@@ -311,12 +621,14 @@ func (mw *MultiWallet) LinkExistingWallet(walletName, walletDataDir, originalPub
 	ctx, _ := mw.contextWithShutdownCancel()
 
 	// verify the public passphrase for the wallet being linked before proceeding
-	if err := mw.loadWalletTemporarily(ctx, walletDataDir, originalPubPass, nil); err != nil {
+	if err := mw.loadWalletTemporarily(ctx, walletDataDir, dbDriver, originalPubPass, nil); err != nil {
 		return nil, err
 	}
 
 	wallet := &Wallet{
 		Name:                  walletName,
+		CreatedAt:             time.Now(),
+		DbDriver:              dbDriver,
 		PrivatePassphraseType: privatePassphraseType,
 		IsRestored:            true,
 		HasDiscoveredAccounts: false, // assume that account discovery hasn't been done
@@ -338,23 +650,24 @@ func (mw *MultiWallet) LinkExistingWallet(walletName, walletDataDir, originalPub
 
 		// prepare the wallet for use and open it
 		err := (func() error {
-			err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID))
+			err := wallet.prepare(mw.rootDir, mw.chainParams, mw.walletConfigSetFn(wallet.ID), mw.walletConfigReadFn(wallet.ID),
+				mw.walletHooks(wallet.ID))
 			if err != nil {
 				return err
 			}
 
 			if originalPubPass == "" || originalPubPass == w.InsecurePubPassphrase {
-				return wallet.openWallet()
+				return wallet.openWallet(nil)
 			}
 
-			err = mw.loadWalletTemporarily(ctx, wallet.dataDir, originalPubPass, func(tempWallet *w.Wallet) error {
+			err = mw.loadWalletTemporarily(ctx, wallet.dataDir, wallet.DbDriver, originalPubPass, func(tempWallet *w.Wallet) error {
 				return tempWallet.ChangePublicPassphrase(ctx, []byte(originalPubPass), []byte(w.InsecurePubPassphrase))
 			})
 			if err != nil {
 				return err
 			}
 
-			return wallet.openWallet()
+			return wallet.openWallet(nil)
 		})()
 
 		// restore db files to their original location if there was an error
@@ -392,6 +705,11 @@ func (mw *MultiWallet) saveNewWallet(wallet *Wallet, setupWallet func() error) (
 		mw.CancelSync()
 		defer mw.SpvSync()
 	}
+	// wallets created while in duress mode must remain visible in duress mode
+	if mw.duressMode {
+		wallet.IsDuressWallet = true
+	}
+
 	// Perform database save operations in batch transaction
 	// for automatic rollback if error occurs at any point.
 	err = mw.batchDbTransaction(func(db storm.Node) error {
@@ -408,7 +726,9 @@ func (mw *MultiWallet) saveNewWallet(wallet *Wallet, setupWallet func() error) (
 			wallet.Name = "wallet-" + strconv.Itoa(wallet.ID) // wallet-#
 		}
 		wallet.dataDir = walletDataDir
-		wallet.DbDriver = mw.dbDriver
+		if wallet.DbDriver == "" {
+			wallet.DbDriver = mw.dbDriver
+		}
 
 		err = db.Save(wallet) // update database with complete wallet information
 		if err != nil {
@@ -422,8 +742,10 @@ func (mw *MultiWallet) saveNewWallet(wallet *Wallet, setupWallet func() error) (
 		return nil, translateError(err)
 	}
 
+	mw.walletsMu.Lock()
 	mw.wallets[wallet.ID] = wallet
-	go mw.listenForTransactions(wallet.ID)
+	mw.walletsMu.Unlock()
+	mw.startWalletListeners(wallet.ID)
 
 	return wallet, nil
 }
@@ -448,6 +770,51 @@ func (mw *MultiWallet) RenameWallet(walletID int, newName string) error {
 	return mw.db.Save(wallet) // update WalletName field
 }
 
+// SetWalletTag sets the icon or color tag used when displaying the specified wallet.
+func (mw *MultiWallet) SetWalletTag(walletID int, tag string) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrInvalid)
+	}
+
+	wallet.Tag = tag
+	return mw.db.Save(wallet)
+}
+
+// SetWalletBirthday sets the time before which the specified wallet is known
+// to have no transactions, as a unix timestamp. The birthday takes effect on
+// the next sync or rescan, set `WalletSetupOptions.Birthday` when restoring a
+// wallet so that the first sync does not scan blocks mined before the wallet
+// was created.
+func (mw *MultiWallet) SetWalletBirthday(walletID int, birthday int64) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	birthdayTime, err := walletBirthday(birthday)
+	if err != nil {
+		return err
+	}
+
+	wallet.Birthday = birthdayTime
+	return mw.db.Save(wallet)
+}
+
+// walletBirthday returns the wallet birthday for the unix time `birthday`,
+// the zero time if `birthday` is 0. Returns `ErrInvalid` if `birthday` is
+// negative or in the future.
+func walletBirthday(birthday int64) (time.Time, error) {
+	if birthday < 0 || birthday > time.Now().Unix() {
+		return time.Time{}, errors.New(ErrInvalid)
+	}
+
+	if birthday == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(birthday, 0), nil
+}
+
 func (mw *MultiWallet) DeleteWallet(walletID int, privPass []byte) error {
 
 	wallet := mw.WalletWithID(walletID)
@@ -469,17 +836,91 @@ func (mw *MultiWallet) DeleteWallet(walletID int, privPass []byte) error {
 		return translateError(err)
 	}
 
-	err = mw.db.DeleteStruct(wallet)
+	return mw.removeWallet(wallet)
+}
+
+// removeWallet deletes the record of a deleted wallet from the database
+// and notifies the wallet deletion listener.
+func (mw *MultiWallet) removeWallet(wallet *Wallet) error {
+	err := mw.db.DeleteStruct(wallet)
 	if err != nil {
 		return translateError(err)
 	}
 
-	delete(mw.wallets, walletID)
+	mw.walletsMu.Lock()
+	delete(mw.wallets, wallet.ID)
+	mw.walletsMu.Unlock()
+	mw.StopTicketBuyer(wallet.ID)
+
+	// config values saved for the deleted wallet are no longer needed
+	walletConfig, err := mw.walletConfigValues(wallet.ID)
+	if err != nil {
+		log.Errorf("[%d] Error reading config values of deleted wallet: %v", wallet.ID, err)
+	}
+	for key := range walletConfig {
+		mw.DeleteUserConfigValueForKey(WalletUniqueConfigKey(wallet.ID, key))
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&TxDraft{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting transaction drafts of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&ScheduledPayment{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting scheduled payments of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&TxNote{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting transaction notes of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&TxTag{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting transaction tags of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&TxFiatRate{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting transaction exchange rates of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&VSPTicket{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting VSP tickets of deleted wallet: %v", wallet.ID, err)
+	}
+
+	err = mw.db.Select(q.Eq("WalletID", wallet.ID)).Delete(&AtomicSwap{})
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error deleting atomic swaps of deleted wallet: %v", wallet.ID, err)
+	}
+
+	if walletDeletionListener := mw.getWalletDeletionListener(); walletDeletionListener != nil {
+		walletDeletionListener.OnWalletDeleted(wallet.ID)
+	}
 
 	return nil
 }
 
+func (mw *MultiWallet) SetWalletDeletionListener(walletDeletionListener WalletDeletionListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.walletDeletionListener = walletDeletionListener
+}
+
+func (mw *MultiWallet) getWalletDeletionListener() WalletDeletionListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.walletDeletionListener
+}
+
 func (mw *MultiWallet) WalletWithID(walletID int) *Wallet {
+	mw.walletsMu.RLock()
+	defer mw.walletsMu.RUnlock()
+
 	if wallet, ok := mw.wallets[walletID]; ok {
 		return wallet
 	}
@@ -502,7 +943,7 @@ func (mw *MultiWallet) VerifySeedForWallet(walletID int, seedMnemonic string) er
 
 func (mw *MultiWallet) NumWalletsNeedingSeedBackup() int32 {
 	var backupsNeeded int32
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if wallet.WalletOpened() && wallet.Seed != "" {
 			backupsNeeded++
 		}
@@ -512,12 +953,15 @@ func (mw *MultiWallet) NumWalletsNeedingSeedBackup() int32 {
 }
 
 func (mw *MultiWallet) LoadedWalletsCount() int32 {
+	mw.walletsMu.RLock()
+	defer mw.walletsMu.RUnlock()
+
 	return int32(len(mw.wallets))
 }
 
 func (mw *MultiWallet) OpenedWalletIDsRaw() []int {
 	walletIDs := make([]int, 0)
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if wallet.WalletOpened() {
 			walletIDs = append(walletIDs, wallet.ID)
 		}
@@ -537,7 +981,7 @@ func (mw *MultiWallet) OpenedWalletsCount() int32 {
 
 func (mw *MultiWallet) SyncedWalletsCount() int32 {
 	var syncedWallets int32
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if wallet.WalletOpened() && wallet.synced {
 			syncedWallets++
 		}
@@ -562,12 +1006,18 @@ func (mw *MultiWallet) WalletNameExists(walletName string) (bool, error) {
 }
 
 func (mw *MultiWallet) UnlockWallet(walletID int, privPass []byte) error {
+	return mw.UnlockWalletWithTimeout(walletID, privPass, 0)
+}
+
+// UnlockWalletWithTimeout unlocks the specified wallet for a session that
+// ends after `timeoutSeconds`, see `Wallet.UnlockWalletWithTimeout`.
+func (mw *MultiWallet) UnlockWalletWithTimeout(walletID int, privPass []byte, timeoutSeconds int64) error {
 	wallet := mw.WalletWithID(walletID)
 	if wallet == nil {
 		return errors.New(ErrNotExist)
 	}
 
-	return wallet.UnlockWallet(privPass)
+	return wallet.UnlockWalletWithTimeout(privPass, timeoutSeconds)
 }
 
 func (mw *MultiWallet) ChangePrivatePassphraseForWallet(walletID int, oldPrivatePassphrase, newPrivatePassphrase []byte, privatePassphraseType int32) error {
@@ -580,6 +1030,10 @@ func (mw *MultiWallet) ChangePrivatePassphraseForWallet(walletID int, oldPrivate
 		return errors.New(ErrInvalid)
 	}
 
+	if wallet.IsWatchingOnlyWallet() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
 	err := wallet.changePrivatePassphrase(oldPrivatePassphrase, newPrivatePassphrase)
 	if err != nil {
 		return translateError(err)
@@ -588,3 +1042,45 @@ func (mw *MultiWallet) ChangePrivatePassphraseForWallet(walletID int, oldPrivate
 	wallet.PrivatePassphraseType = privatePassphraseType
 	return mw.db.Save(wallet)
 }
+
+// ChangePublicPassphraseForWallet changes the passphrase required to open the
+// specified wallet. Setting an empty `newPublicPassphrase` restores the default
+// public passphrase, allowing the wallet to be opened with `OpenWallets`.
+func (mw *MultiWallet) ChangePublicPassphraseForWallet(walletID int, oldPublicPassphrase, newPublicPassphrase []byte) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrInvalid)
+	}
+
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	hasPublicPassphrase := isCustomPublicPassphrase(string(newPublicPassphrase))
+
+	err := wallet.changePublicPassphrase(oldPublicPassphrase, newPublicPassphrase)
+	if err != nil {
+		return translateError(err)
+	}
+
+	wallet.HasPublicPassphrase = hasPublicPassphrase
+	return mw.db.Save(wallet)
+}
+
+// walletHooks returns the functions through which the wallet with ID
+// `walletID` reads and updates the data kept for it by the multiwallet.
+func (mw *MultiWallet) walletHooks(walletID int) walletHooks {
+	return walletHooks{
+		deleteUserConfigValue:  mw.walletConfigDeleteFn(walletID),
+		unlockAttemptsExceeded: mw.walletWipeFn(walletID),
+		duressUnlock:           mw.duressUnlockFn(walletID),
+		walletLocked:           mw.walletLockedFn(walletID),
+		keySourcePassphrase:    mw.keySourcePassphraseFn(walletID),
+		contactName:            mw.contactName,
+		atomicSwapActionsFn:    mw.atomicSwapActionsFn(walletID),
+		txNote:                 mw.txNoteFn(walletID),
+		txTags:                 mw.txTagsFn(walletID),
+		txFiatRate:             mw.txFiatRateFn(walletID),
+		vspFeeInputs:           mw.vspFeeInputsFn(walletID),
+	}
+}
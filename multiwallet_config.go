@@ -2,6 +2,7 @@ package dcrlibwallet
 
 import (
 	"github.com/asdine/storm"
+	"github.com/decred/dcrwallet/errors/v2"
 )
 
 const (
@@ -28,12 +29,30 @@ const (
 
 	VSPHostConfigKey = "vsp_host"
 
+	// Wallet config keys, use with the config methods of a wallet.
+	DefaultAccountConfigKey = "default_account"
+	FiatCurrencyConfigKey   = "fiat_currency"
+	LastUsedVSPConfigKey    = "last_used_vsp"
+	GapLimitConfigKey       = "gap_limit"
+	MixedAccountConfigKey   = "mixed_account"
+	UnmixedAccountConfigKey = "unmixed_account"
+
+	SpendUnconfirmedChangeConfigKey = "spend_unconfirmed_change"
+
+	HardwareWalletTypeConfigKey    = "hardware_wallet_type"
+	HardwareWalletAccountConfigKey = "hardware_wallet_account"
+
+	PairedSignerConfigKey   = "paired_signer"
+	PairedAccountConfigKey  = "paired_account"
+	PairedCoinTypeConfigKey = "paired_coin_type"
+
 	PassphraseTypePin  int32 = 0
 	PassphraseTypePass int32 = 1
 )
 
 type configSaveFn = func(key string, value interface{}) error
 type configReadFn = func(multiwallet bool, key string, valueOut interface{}) error
+type configDeleteFn = func(key string) error
 
 func (mw *MultiWallet) walletConfigSetFn(walletID int) configSaveFn {
 	return func(key string, value interface{}) error {
@@ -51,11 +70,21 @@ func (mw *MultiWallet) walletConfigReadFn(walletID int) configReadFn {
 	}
 }
 
+func (mw *MultiWallet) walletConfigDeleteFn(walletID int) configDeleteFn {
+	return func(key string) error {
+		walletUniqueKey := WalletUniqueConfigKey(walletID, key)
+		return mw.db.Delete(userConfigBucketName, walletUniqueKey)
+	}
+}
+
 func (mw *MultiWallet) SaveUserConfigValue(key string, value interface{}) {
 	err := mw.db.Set(userConfigBucketName, key, value)
 	if err != nil {
 		log.Errorf("error setting config value for key: %s, error: %v", key, err)
+		return
 	}
+
+	mw.publishConfigValueChanged(key)
 }
 
 func (mw *MultiWallet) ReadUserConfigValue(key string, valueOut interface{}) error {
@@ -70,13 +99,47 @@ func (mw *MultiWallet) DeleteUserConfigValueForKey(key string) {
 	err := mw.db.Delete(userConfigBucketName, key)
 	if err != nil {
 		log.Errorf("error deleting config value for key: %s, error: %v", key, err)
+		return
 	}
+
+	mw.publishConfigValueChanged(key)
 }
 
 func (mw *MultiWallet) ClearConfig() {
 	err := mw.db.Drop(userConfigBucketName)
 	if err != nil {
 		log.Errorf("error deleting config bucket: %v", err)
+		return
+	}
+
+	mw.publishConfigValueChanged("")
+}
+
+func (mw *MultiWallet) AddConfigChangeListener(configChangeListener ConfigChangeListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	if _, ok := mw.configChangeListeners[uniqueIdentifier]; ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.configChangeListeners[uniqueIdentifier] = configChangeListener
+	return nil
+}
+
+func (mw *MultiWallet) RemoveConfigChangeListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.configChangeListeners, uniqueIdentifier)
+}
+
+func (mw *MultiWallet) publishConfigValueChanged(key string) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, configChangeListener := range mw.configChangeListeners {
+		configChangeListener.OnConfigValueChanged(key)
 	}
 }
 
@@ -17,6 +17,10 @@ const (
 
 	walletsMetadataBucketName    = "metadata"
 	walletstartupPassphraseField = "startup-passphrase"
+	walletDuressPassphraseField  = "duress-passphrase"
+
+	startupPassphraseFailedAttemptsField = "startup-passphrase-failed-attempts"
+	startupPassphraseLastFailedField     = "startup-passphrase-last-failed"
 )
 
 func (mw *MultiWallet) batchDbTransaction(dbOp func(node storm.Node) error) (err error) {
@@ -42,7 +46,7 @@ func (mw *MultiWallet) batchDbTransaction(dbOp func(node storm.Node) error) (err
 	return err
 }
 
-func (mw *MultiWallet) loadWalletTemporarily(ctx context.Context, walletDataDir, walletPublicPass string,
+func (mw *MultiWallet) loadWalletTemporarily(ctx context.Context, walletDataDir, walletDbDriver, walletPublicPass string,
 	onLoaded func(*w.Wallet) error) error {
 
 	if walletPublicPass == "" {
@@ -50,7 +54,7 @@ func (mw *MultiWallet) loadWalletTemporarily(ctx context.Context, walletDataDir,
 	}
 
 	// initialize the wallet loader
-	walletLoader := initWalletLoader(mw.chainParams, walletDataDir, mw.dbDriver)
+	walletLoader := initWalletLoader(mw.chainParams, walletDataDir, walletDbDriver, DefaultGapLimit)
 
 	// open the wallet to get ready for temporary use
 	wallet, err := walletLoader.OpenExistingWallet(ctx, []byte(walletPublicPass))
@@ -85,11 +89,11 @@ func (mw *MultiWallet) markWalletAsDiscoveredAccounts(walletID int) error {
 }
 
 func (mw *MultiWallet) setNetworkBackend(syncer *spv.Syncer) {
-	for walletID, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if wallet.WalletOpened() {
 			walletBackend := &spv.WalletBackend{
 				Syncer:   syncer,
-				WalletID: walletID,
+				WalletID: wallet.ID,
 			}
 			wallet.internal.SetNetworkBackend(walletBackend)
 		}
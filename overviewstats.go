@@ -0,0 +1,67 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// OverviewStats are the totals, in atoms, of all transactions of a wallet.
+// FirstTransactionTime is the unix timestamp of the oldest transaction, 0 if
+// the wallet has no transactions. TicketCount is the number of tickets
+// purchased and StakingRewards the total earned from votes.
+type OverviewStats struct {
+	TotalReceived        int64 `json:"total_received"`
+	TotalSent            int64 `json:"total_sent"`
+	TotalFees            int64 `json:"total_fees"`
+	TransactionCount     int   `json:"transaction_count"`
+	FirstTransactionTime int64 `json:"first_transaction_time"`
+	TicketCount          int   `json:"ticket_count"`
+	StakingRewards       int64 `json:"staking_rewards"`
+}
+
+// OverviewStats returns the json-encoded `OverviewStats` of this wallet.
+func (wallet *Wallet) OverviewStats() (string, error) {
+	stats, err := wallet.OverviewStatsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedStats, err := json.Marshal(stats)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedStats), nil
+}
+
+func (wallet *Wallet) OverviewStatsRaw() (*OverviewStats, error) {
+	page, err := wallet.FilterTransactionsRaw(0, 0, &TransactionFilter{}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the totals are the totals of a single report of all transactions
+	report := &SpendingReport{}
+	stats := &OverviewStats{}
+	for i := range page.Transactions {
+		tx := &page.Transactions[i]
+		report.addTransaction(tx, -1)
+
+		if tx.Type == txhelper.TxTypeTicketPurchase {
+			stats.TicketCount++
+		}
+	}
+
+	if len(page.Transactions) > 0 {
+		stats.FirstTransactionTime = page.Transactions[0].Timestamp
+	}
+
+	stats.TotalReceived = report.Received
+	stats.TotalSent = report.Sent
+	stats.TotalFees = report.Fees
+	stats.TransactionCount = report.TransactionCount
+	stats.StakingRewards = report.StakingRewards
+
+	return stats, nil
+}
@@ -0,0 +1,293 @@
+package dcrlibwallet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/hdkeychain/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/wallet/v3/udb"
+)
+
+// PairingPayloadVersion is the version of the pairing and signed transaction
+// payloads created by this library.
+const PairingPayloadVersion = 1
+
+// Pairing a watching only wallet with an offline signer:
+//  1. the signer exports the `PairingPayload` of an account with
+//     `ExportPairingPayload`, shown as a QR code;
+//  2. the watching only wallet is created from the payload with
+//     `CreateWatchOnlyWalletFromPairing`;
+//  3. the watching only wallet exports signing requests, the encoded
+//     `UnsignedTxPackage` of a transaction, with
+//     `TxAuthor.ExportUnsignedTransaction`;
+//  4. the signer signs the request with `SignUnsignedTxPackage` and shows the
+//     encoded `SignedTxPayload`;
+//  5. the watching only wallet publishes the signed transaction with
+//     `PublishSignedTxPayload`.
+//
+// PairingPayload identifies the account of an offline signer that a watching
+// only wallet is paired with. Fingerprint is the hex-encoded first 4 bytes of
+// the hash160 of the account public key, so signing requests can be matched
+// to the account that must sign them.
+type PairingPayload struct {
+	Version     int32  `json:"version"`
+	Network     string `json:"network"`
+	Fingerprint string `json:"fingerprint"`
+	CoinType    uint32 `json:"coin_type"`
+	Account     uint32 `json:"account"`
+	XPub        string `json:"xpub"`
+}
+
+// SignedTxPayload is the response of an offline signer to a signing request,
+// Tx is the hex-encoded signed transaction.
+type SignedTxPayload struct {
+	Version     int32  `json:"version"`
+	Network     string `json:"network"`
+	Fingerprint string `json:"fingerprint"`
+	TxHash      string `json:"tx_hash"`
+	Tx          string `json:"tx"`
+}
+
+// encodePayload returns the base64 encoding of the json-encoded payload,
+// suitable for QR codes.
+func encodePayload(payload interface{}) (string, error) {
+	jsonEncodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(jsonEncodedPayload), nil
+}
+
+func decodePayload(encodedPayload string, payload interface{}) error {
+	jsonEncodedPayload, err := base64.StdEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	err = json.Unmarshal(jsonEncodedPayload, payload)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return nil
+}
+
+// accountFingerprint returns the fingerprint of an account xpub.
+func accountFingerprint(xpub string, chainParams hdkeychain.NetworkParams) (string, error) {
+	key, err := hdkeychain.NewKeyFromString(xpub, chainParams)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(dcrutil.Hash160(pubKey.SerializeCompressed())[:4]), nil
+}
+
+// ExportPairingPayload returns the encoded `PairingPayload` of the specified
+// account, to pair a watching only wallet with this wallet as its offline
+// signer.
+func (wallet *Wallet) ExportPairingPayload(account int32) (string, error) {
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+	if account < 0 || uint32(account) == udb.ImportedAddrAccount {
+		return "", errors.New(ErrInvalid)
+	}
+
+	xpub, err := wallet.AccountXPub(account)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint, err := accountFingerprint(xpub, wallet.chainParams)
+	if err != nil {
+		return "", err
+	}
+
+	coinType, err := wallet.internal.CoinType(wallet.shutdownContext())
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return encodePayload(&PairingPayload{
+		Version:     PairingPayloadVersion,
+		Network:     wallet.chainParams.Name,
+		Fingerprint: fingerprint,
+		CoinType:    coinType,
+		Account:     uint32(account),
+		XPub:        xpub,
+	})
+}
+
+// CreateWatchOnlyWalletFromPairing creates a watching only wallet for the
+// signer account of the encoded `PairingPayload`. Signing requests exported
+// by the wallet identify the signer account and the derivation path of the
+// key of each input on the signer.
+func (mw *MultiWallet) CreateWatchOnlyWalletFromPairing(walletName, encodedPayload string) (*Wallet, error) {
+	payload := &PairingPayload{}
+	err := decodePayload(encodedPayload, payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Version != PairingPayloadVersion || payload.Network != mw.chainParams.Name {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	fingerprint, err := accountFingerprint(payload.XPub, mw.chainParams)
+	if err != nil || fingerprint != payload.Fingerprint {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	wallet, err := mw.CreateWatchOnlyWallet(walletName, payload.XPub)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.SetStringConfigValueForKey(PairedSignerConfigKey, payload.Fingerprint)
+	wallet.SetLongConfigValueForKey(PairedAccountConfigKey, int64(payload.Account))
+	wallet.SetLongConfigValueForKey(PairedCoinTypeConfigKey, int64(payload.CoinType))
+
+	return wallet, nil
+}
+
+// PairedSigner returns the fingerprint of the offline signer account this
+// wallet is paired with, empty if the wallet is not paired.
+func (wallet *Wallet) PairedSigner() string {
+	return wallet.ReadStringConfigValueForKey(PairedSignerConfigKey, "")
+}
+
+// SignUnsignedTxPackage signs the transaction of the encoded
+// `UnsignedTxPackage` exported by a paired watching only wallet and returns
+// the encoded `SignedTxPayload` of the signed transaction. Every input must
+// spend an output paid to an address of this wallet at the input's
+// derivation path.
+func (wallet *Wallet) SignUnsignedTxPackage(encodedPackage string, privPass []byte) (string, error) {
+	lock := make(chan time.Time, 1)
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+		lock <- time.Time{} // send matters, not the value
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return "", errors.New(ErrWalletIsWatchOnly)
+	}
+
+	pkg, err := DecodeUnsignedTxPackage(encodedPackage)
+	if err != nil {
+		return "", err
+	}
+	if pkg.Network != wallet.chainParams.Name {
+		return "", errors.New(ErrInvalid)
+	}
+
+	msgTx, err := pkg.MsgTx()
+	if err != nil {
+		return "", err
+	}
+	if len(pkg.Inputs) != len(msgTx.TxIn) {
+		return "", errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	prevScripts := make(map[wire.OutPoint][]byte, len(pkg.Inputs))
+	for i, input := range pkg.Inputs {
+		if input.Path == "" {
+			return "", errors.New(ErrInvalid)
+		}
+
+		if pkg.Signer != "" {
+			xpub, err := wallet.AccountXPub(int32(input.Account))
+			if err != nil {
+				return "", err
+			}
+			fingerprint, err := accountFingerprint(xpub, wallet.chainParams)
+			if err != nil || fingerprint != pkg.Signer {
+				log.Errorf("[%d] Signing request is for signer %s, not account %d", wallet.ID, pkg.Signer, input.Account)
+				return "", errors.New(ErrInvalid)
+			}
+		}
+
+		// the key at the input's path must pay to the spent output
+		address, err := wallet.AddressAtIndex(int32(input.Account), input.Branch, input.Index)
+		if err != nil {
+			return "", err
+		}
+		pkScript, err := hex.DecodeString(input.PkScript)
+		if err != nil {
+			return "", errors.New(ErrInvalid)
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(0, pkScript, wallet.chainParams)
+		if err != nil || len(addrs) != 1 || addrs[0].Address() != address {
+			return "", errors.New(ErrInvalidAddress)
+		}
+
+		// an offline signer may not have derived the address yet
+		err = wallet.internal.ExtendWatchedAddresses(ctx, input.Account, input.Branch, input.Index)
+		if err != nil {
+			return "", translateError(err)
+		}
+
+		prevScripts[msgTx.TxIn[i].PreviousOutPoint] = pkScript
+	}
+
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return "", err
+	}
+
+	invalidSigs, err := wallet.internal.SignTransaction(ctx, msgTx, txscript.SigHashAll, prevScripts, nil, nil)
+	if err != nil {
+		return "", translateError(err)
+	}
+	if len(invalidSigs) > 0 {
+		log.Errorf("[%d] Could not sign %d inputs of signing request", wallet.ID, len(invalidSigs))
+		return "", errors.New(ErrInvalid)
+	}
+
+	signedTx, err := msgTx.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	return encodePayload(&SignedTxPayload{
+		Version:     PairingPayloadVersion,
+		Network:     pkg.Network,
+		Fingerprint: pkg.Signer,
+		TxHash:      msgTx.TxHash().String(),
+		Tx:          hex.EncodeToString(signedTx),
+	})
+}
+
+// PublishSignedTxPayload publishes the transaction of the encoded
+// `SignedTxPayload` returned by the offline signer for the encoded
+// `UnsignedTxPackage` exported by this wallet. See
+// `PublishSignedTransaction`.
+func (wallet *Wallet) PublishSignedTxPayload(encodedPackage, encodedSignedPayload string) ([]byte, error) {
+	payload := &SignedTxPayload{}
+	err := decodePayload(encodedSignedPayload, payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Version != PairingPayloadVersion || payload.Network != wallet.chainParams.Name {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if signer := wallet.PairedSigner(); signer != "" && payload.Fingerprint != signer {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return wallet.PublishSignedTransaction(encodedPackage, payload.Tx)
+}
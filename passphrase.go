@@ -0,0 +1,122 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Passphrase strength categories returned by `EstimatePassphraseStrength`.
+const (
+	PassphraseVeryWeak   = "very_weak"
+	PassphraseWeak       = "weak"
+	PassphraseReasonable = "reasonable"
+	PassphraseStrong     = "strong"
+	PassphraseVeryStrong = "very_strong"
+)
+
+// commonPassphrases are rejected as very weak regardless of their length.
+var commonPassphrases = map[string]bool{
+	"password": true, "passw0rd": true, "password1": true, "123456": true,
+	"1234567": true, "12345678": true, "123456789": true, "1234567890": true,
+	"qwerty": true, "qwertyuiop": true, "abc123": true, "111111": true,
+	"000000": true, "123123": true, "iloveyou": true, "letmein": true,
+	"welcome": true, "monkey": true, "dragon": true, "admin": true,
+	"decred": true, "bitcoin": true,
+}
+
+// PassphraseStrength is the estimated strength of a passphrase. Entropy is the
+// estimated number of bits of entropy, Score ranges from 0 (very weak) to
+// 4 (very strong) and Category is the name of the score.
+type PassphraseStrength struct {
+	Entropy  float64 `json:"entropy"`
+	Score    int32   `json:"score"`
+	Category string  `json:"category"`
+}
+
+// EstimatePassphraseStrength returns the json-encoded `PassphraseStrength`
+// of `passphrase`.
+func EstimatePassphraseStrength(passphrase string) (string, error) {
+	strength := EstimatePassphraseStrengthRaw(passphrase)
+	jsonEncodedStrength, err := json.Marshal(strength)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedStrength), nil
+}
+
+// EstimatePassphraseStrengthRaw estimates the entropy of `passphrase` from the
+// size of the character classes it uses. Repeated characters and runs of
+// sequential characters, such as "aaaa" or "1234", add no entropy.
+func EstimatePassphraseStrengthRaw(passphrase string) *PassphraseStrength {
+	chars := []rune(passphrase)
+	if len(chars) == 0 || commonPassphrases[strings.ToLower(passphrase)] {
+		return passphraseStrengthForEntropy(0)
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, c := range chars {
+		switch {
+		case c > unicode.MaxASCII:
+			hasOther = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	var poolSize float64
+	if hasLower {
+		poolSize += 26
+	}
+	if hasUpper {
+		poolSize += 26
+	}
+	if hasDigit {
+		poolSize += 10
+	}
+	if hasSymbol {
+		poolSize += 33
+	}
+	if hasOther {
+		poolSize += 100
+	}
+
+	// count the characters that are neither repeats nor part of a sequence
+	effectiveLength := 1
+	for i := 1; i < len(chars); i++ {
+		delta := chars[i] - chars[i-1]
+		if delta == 0 || (i > 1 && delta == chars[i-1]-chars[i-2] && (delta == 1 || delta == -1)) {
+			continue
+		}
+		effectiveLength++
+	}
+
+	entropy := float64(effectiveLength) * math.Log2(poolSize)
+	return passphraseStrengthForEntropy(math.Round(entropy*100) / 100)
+}
+
+func passphraseStrengthForEntropy(entropy float64) *PassphraseStrength {
+	strength := &PassphraseStrength{Entropy: entropy}
+	switch {
+	case entropy < 28:
+		strength.Score, strength.Category = 0, PassphraseVeryWeak
+	case entropy < 36:
+		strength.Score, strength.Category = 1, PassphraseWeak
+	case entropy < 60:
+		strength.Score, strength.Category = 2, PassphraseReasonable
+	case entropy < 128:
+		strength.Score, strength.Category = 3, PassphraseStrong
+	default:
+		strength.Score, strength.Category = 4, PassphraseVeryStrong
+	}
+
+	return strength
+}
@@ -0,0 +1,216 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// PaymentURIScheme is the scheme of decred payment URIs.
+const PaymentURIScheme = "decred"
+
+// PaymentRequest is a payment request parsed from a decred payment URI.
+// Amount is in atoms and is 0 if the URI has no amount. Expiry is the unix
+// timestamp after which the request should not be paid, or 0.
+type PaymentRequest struct {
+	Address string `json:"address"`
+	Amount  int64  `json:"amount"`
+	Label   string `json:"label"`
+	Message string `json:"message"`
+	Expiry  int64  `json:"expiry"`
+}
+
+// GeneratePaymentURI returns a decred payment URI of the form
+// decred:<address>?amount=<amount>&label=<label>&message=<message> for
+// receive screens and QR codes. `amount` is in atoms and is written to the
+// URI in DCR. The amount, label and message are omitted if they are not set.
+func (mw *MultiWallet) GeneratePaymentURI(address string, amount int64, label, message string) (string, error) {
+	if _, err := dcrutil.DecodeAddress(address, mw.chainParams); err != nil {
+		return "", errors.New(ErrInvalidAddress)
+	}
+
+	if amount < 0 || amount > dcrutil.MaxAmount {
+		return "", errors.New(ErrInvalid)
+	}
+
+	var params []string
+	if amount > 0 {
+		params = append(params, "amount="+formatCoinAmount(amount))
+	}
+	if label != "" {
+		params = append(params, "label="+escapeURIParam(label))
+	}
+	if message != "" {
+		params = append(params, "message="+escapeURIParam(message))
+	}
+
+	uri := PaymentURIScheme + ":" + address
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+
+	return uri, nil
+}
+
+// formatCoinAmount formats an amount in atoms as DCR without trailing zeros
+// or rounding.
+func formatCoinAmount(atoms int64) string {
+	whole, fraction := atoms/dcrutil.AtomsPerCoin, atoms%dcrutil.AtomsPerCoin
+	if fraction == 0 {
+		return fmt.Sprintf("%d", whole)
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%d.%08d", whole, fraction), "0")
+}
+
+// escapeURIParam escapes a URI query parameter value, with spaces encoded as
+// %20 rather than +.
+func escapeURIParam(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
+
+// ParsePaymentURI returns the json-encoded `PaymentRequest` of a decred
+// payment URI, deep link or a bare address.
+func (mw *MultiWallet) ParsePaymentURI(uri string) (string, error) {
+	paymentRequest, err := mw.ParsePaymentURIRaw(uri)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPaymentRequest, err := json.Marshal(paymentRequest)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPaymentRequest), nil
+}
+
+// ParsePaymentURIRaw parses a decred payment URI, deep link or a bare address.
+// `ErrInvalidAddress` is returned if the address is invalid or belongs to a
+// different network, `ErrInvalidAmount` if the amount is invalid and
+// `ErrExpired` if the request has expired. Unknown required (req-) parameters
+// cause the URI to be rejected with `ErrInvalid`.
+func (mw *MultiWallet) ParsePaymentURIRaw(uri string) (*PaymentRequest, error) {
+	uri = strings.TrimSpace(uri)
+
+	// a bare address is accepted as a payment URI without parameters
+	if !strings.Contains(uri, ":") {
+		uri = PaymentURIScheme + ":" + uri
+	}
+
+	parsedURI, err := url.Parse(uri)
+	if err != nil || !strings.EqualFold(parsedURI.Scheme, PaymentURIScheme) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	// deep links may be of the form decred://<address>
+	address := parsedURI.Opaque
+	if address == "" {
+		address = parsedURI.Host + strings.TrimPrefix(parsedURI.Path, "/")
+	}
+	if _, err = dcrutil.DecodeAddress(address, mw.chainParams); err != nil {
+		return nil, errors.New(ErrInvalidAddress)
+	}
+
+	params, err := url.ParseQuery(parsedURI.RawQuery)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	paymentRequest := &PaymentRequest{
+		Address: address,
+		Label:   params.Get("label"),
+		Message: params.Get("message"),
+	}
+
+	for key := range params {
+		switch key {
+		case "amount", "label", "message", "expiry":
+		default:
+			if strings.HasPrefix(key, "req-") {
+				return nil, errors.New(ErrInvalid)
+			}
+		}
+	}
+
+	if amount := params.Get("amount"); amount != "" {
+		paymentRequest.Amount, err = parseCoinAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if expiry := params.Get("expiry"); expiry != "" {
+		paymentRequest.Expiry, err = strconv.ParseInt(expiry, 10, 64)
+		if err != nil || paymentRequest.Expiry < 0 {
+			return nil, errors.New(ErrInvalid)
+		}
+		if paymentRequest.Expiry > 0 && time.Now().Unix() > paymentRequest.Expiry {
+			return nil, errors.New(ErrExpired)
+		}
+	}
+
+	return paymentRequest, nil
+}
+
+// parseCoinAmount parses an amount in DCR to atoms without floating point
+// rounding. Amounts with more than 8 decimal places, signs or any characters
+// other than digits and a decimal point are rejected.
+func parseCoinAmount(amount string) (int64, error) {
+	parts := strings.Split(amount, ".")
+	if len(parts) > 2 || parts[0] == "" && (len(parts) == 1 || parts[1] == "") {
+		return 0, errors.New(ErrInvalidAmount)
+	}
+	for _, part := range parts {
+		if !isDigits(part) {
+			return 0, errors.New(ErrInvalidAmount)
+		}
+	}
+
+	whole := int64(0)
+	if parts[0] != "" {
+		var err error
+		whole, err = strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return 0, errors.New(ErrInvalidAmount)
+		}
+	}
+
+	fraction := int64(0)
+	if len(parts) == 2 && parts[1] != "" {
+		if len(parts[1]) > 8 {
+			return 0, errors.New(ErrInvalidAmount)
+		}
+		var err error
+		fraction, err = strconv.ParseInt(parts[1]+strings.Repeat("0", 8-len(parts[1])), 10, 64)
+		if err != nil {
+			return 0, errors.New(ErrInvalidAmount)
+		}
+	}
+
+	if whole > dcrutil.MaxAmount/dcrutil.AtomsPerCoin {
+		return 0, errors.New(ErrInvalidAmount)
+	}
+
+	atoms := whole*dcrutil.AtomsPerCoin + fraction
+	if atoms <= 0 || atoms > dcrutil.MaxAmount {
+		return 0, errors.New(ErrInvalidAmount)
+	}
+
+	return atoms, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
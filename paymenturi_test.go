@@ -0,0 +1,52 @@
+package dcrlibwallet
+
+import (
+	"testing"
+)
+
+func TestParseCoinAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		atoms  int64
+		valid  bool
+	}{
+		{amount: "1", atoms: 1e8, valid: true},
+		{amount: "1.5", atoms: 1.5e8, valid: true},
+		{amount: "0.00000001", atoms: 1, valid: true},
+		{amount: ".25", atoms: 0.25e8, valid: true},
+		{amount: "2.", atoms: 2e8, valid: true},
+		{amount: "21000000", atoms: 21e14, valid: true},
+		{amount: "", valid: false},
+		{amount: ".", valid: false},
+		{amount: "0", valid: false},
+		{amount: "0.0", valid: false},
+		{amount: "-1", valid: false},
+		{amount: "-0.5", valid: false},
+		{amount: "-.5", valid: false},
+		{amount: "+1", valid: false},
+		{amount: "1.-5", valid: false},
+		{amount: "1.+5", valid: false},
+		{amount: "0.000000001", valid: false},
+		{amount: "1.2.3", valid: false},
+		{amount: "1e8", valid: false},
+		{amount: " 1", valid: false},
+		{amount: "21000000.00000001", valid: false},
+		{amount: "99999999999999999999", valid: false},
+	}
+
+	for _, test := range tests {
+		atoms, err := parseCoinAmount(test.amount)
+		if !test.valid {
+			if err == nil {
+				t.Fatalf("%q: parsed as %d atoms, want error", test.amount, atoms)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", test.amount, err)
+		}
+		if atoms != test.atoms {
+			t.Fatalf("%q: parsed as %d atoms, want %d", test.amount, atoms, test.atoms)
+		}
+	}
+}
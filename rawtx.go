@@ -0,0 +1,262 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// SignedRawTransaction is a raw transaction signed with `SignRawTransaction`.
+// Complete is false if some inputs could not be signed with the supplied
+// keys, or their previous outputs are unknown, the indexes of those inputs
+// are listed in UnsignedInputs.
+type SignedRawTransaction struct {
+	Tx             string  `json:"tx"`
+	Complete       bool    `json:"complete"`
+	UnsignedInputs []int32 `json:"unsigned_inputs"`
+}
+
+// SignRawTransaction signs the inputs of the hex-encoded transaction that
+// spend outputs paid to the keys in the json-encoded array of WIF private
+// keys, and returns the json-encoded `SignedRawTransaction`. The json-encoded
+// `prevScripts` array holds the hex-encoded script of the output spent by
+// each input, in input order. Scripts that are empty, or missing if the array
+// is shorter than the inputs, are read from the wallets' transactions. The
+// keys are only used for signing, they are not imported into any wallet.
+// Inputs that are already signed are left unchanged.
+func (mw *MultiWallet) SignRawTransaction(rawTxHex, jsonEncodedWIFs, jsonEncodedPrevScripts string) (string, error) {
+	var wifs []string
+	err := json.Unmarshal([]byte(jsonEncodedWIFs), &wifs)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	var prevScripts []string
+	if jsonEncodedPrevScripts != "" {
+		err = json.Unmarshal([]byte(jsonEncodedPrevScripts), &prevScripts)
+		if err != nil {
+			return "", errors.New(ErrInvalid)
+		}
+	}
+
+	signedTx, err := mw.SignRawTransactionRaw(rawTxHex, wifs, prevScripts)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTx, err := json.Marshal(signedTx)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTx), nil
+}
+
+func (mw *MultiWallet) SignRawTransactionRaw(rawTxHex string, wifs []string,
+	prevScripts []string) (*SignedRawTransaction, error) {
+
+	msgTx, err := decodeRawTx(rawTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wifs) == 0 || len(prevScripts) > len(msgTx.TxIn) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	pkScripts := make([][]byte, len(msgTx.TxIn))
+	for i, prevScript := range prevScripts {
+		pkScripts[i], err = hex.DecodeString(prevScript)
+		if err != nil {
+			return nil, errors.New(ErrInvalid)
+		}
+	}
+	for i, txIn := range msgTx.TxIn {
+		if len(pkScripts[i]) == 0 && len(txIn.SignatureScript) == 0 {
+			pkScripts[i] = mw.prevOutputScript(&txIn.PreviousOutPoint)
+		}
+	}
+
+	signedTx, err := signRawTx(msgTx, wifs, pkScripts, mw.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(msgTx.SerializeSize())
+	err = msgTx.Serialize(&txBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx.Tx = hex.EncodeToString(txBuf.Bytes())
+	return signedTx, nil
+}
+
+// prevOutputScript returns the script of the output spent by `outpoint`,
+// read from the transactions of the opened wallets, nil if the output is not
+// found.
+func (mw *MultiWallet) prevOutputScript(outpoint *wire.OutPoint) []byte {
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		if pkScript := wallet.prevOutputScript(outpoint); pkScript != nil {
+			return pkScript
+		}
+	}
+	return nil
+}
+
+// prevOutputScript returns the script of the output spent by `outpoint`,
+// read from the transactions of this wallet, nil if the output is not found.
+func (wallet *Wallet) prevOutputScript(outpoint *wire.OutPoint) []byte {
+	txs, _, err := wallet.internal.GetTransactionsByHashes(wallet.shutdownContext(), []*chainhash.Hash{&outpoint.Hash})
+	if err != nil || len(txs) == 0 {
+		return nil
+	}
+	if int(outpoint.Index) < len(txs[0].TxOut) {
+		return txs[0].TxOut[outpoint.Index].PkScript
+	}
+	return nil
+}
+
+// signRawTx signs the unsigned inputs of `msgTx` that spend the outputs with
+// the scripts `pkScripts`, in input order, with the WIF-encoded keys `wifs`.
+// Each signature script is verified against the script of the output it
+// spends, inputs that are not signed and verified, including inputs whose
+// script is nil, are listed in UnsignedInputs.
+func signRawTx(msgTx *wire.MsgTx, wifs []string, pkScripts [][]byte,
+	params *chaincfg.Params) (*SignedRawTransaction, error) {
+
+	// keys are looked up by the P2PKH address of the key
+	keys := make(map[string]*dcrutil.WIF, len(wifs))
+	for _, encodedWIF := range wifs {
+		wif, err := dcrutil.DecodeWIF(encodedWIF, params.PrivateKeyID)
+		if err != nil {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		address, err := dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(wif.PubKey()), params, wif.DSA())
+		if err != nil {
+			return nil, errors.New(ErrInvalid)
+		}
+
+		keys[address.Address()] = wif
+	}
+
+	getKey := txscript.KeyClosure(func(address dcrutil.Address) ([]byte, dcrec.SignatureType, bool, error) {
+		wif, ok := keys[address.Address()]
+		if !ok {
+			return nil, 0, false, errors.New(ErrNotExist)
+		}
+		return wif.PrivKey(), wif.DSA(), true, nil
+	})
+	getScript := txscript.ScriptClosure(func(address dcrutil.Address) ([]byte, error) {
+		return nil, errors.New(ErrNotExist)
+	})
+
+	signedTx := &SignedRawTransaction{
+		UnsignedInputs: make([]int32, 0),
+	}
+
+	for i, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) > 0 {
+			continue
+		}
+
+		pkScript := pkScripts[i]
+		if len(pkScript) == 0 {
+			signedTx.UnsignedInputs = append(signedTx.UnsignedInputs, int32(i))
+			continue
+		}
+
+		sigScript, err := txscript.SignTxOutput(params, msgTx, i, pkScript, txscript.SigHashAll,
+			getKey, getScript, nil)
+		if err == nil {
+			txIn.SignatureScript = sigScript
+			var vm *txscript.Engine
+			vm, err = txscript.NewEngine(pkScript, msgTx, i, txscript.StandardVerifyFlags, 0, nil)
+			if err == nil {
+				err = vm.Execute()
+			}
+		}
+		if err != nil {
+			txIn.SignatureScript = nil
+			signedTx.UnsignedInputs = append(signedTx.UnsignedInputs, int32(i))
+		}
+	}
+
+	signedTx.Complete = len(signedTx.UnsignedInputs) == 0
+	return signedTx, nil
+}
+
+// decodeRawTx deserializes the hex-encoded transaction.
+func decodeRawTx(rawTxHex string) (*wire.MsgTx, error) {
+	serializedTx, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	msgTx := wire.NewMsgTx()
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return msgTx, nil
+}
+
+// PublishTransaction broadcasts the hex-encoded signed transaction through the
+// wallet's network backend and returns the hash of the transaction. The
+// transaction need not be related to the wallet. If the transaction is
+// rejected, the returned error is one of ErrInvalid for malformed
+// transactions, ErrDoubleSpend for transactions spending outputs already
+// spent by another transaction or ErrTxRejected for transactions that break
+// policy or consensus rules.
+func (wallet *Wallet) PublishTransaction(rawTxHex string) ([]byte, error) {
+	serializedTx, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	msgTx := wire.NewMsgTx()
+	err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if len(msgTx.TxIn) == 0 || len(msgTx.TxOut) == 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+	for _, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) == 0 {
+			return nil, errors.New(ErrInvalid)
+		}
+	}
+
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	txHash, err := wallet.internal.PublishTransaction(wallet.shutdownContext(), msgTx, serializedTx, n)
+	if err != nil {
+		log.Errorf("[%d] Error publishing transaction: %v", wallet.ID, err)
+		if errors.Is(err, errors.Policy) || errors.Is(err, errors.Consensus) || errors.Is(err, errors.ScriptFailure) {
+			return nil, errors.New(ErrTxRejected)
+		}
+		return nil, translateError(err)
+	}
+
+	return txHash[:], nil
+}
@@ -0,0 +1,122 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v2"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+)
+
+func testWIF(t *testing.T, params *chaincfg.Params, b byte) (*dcrutil.WIF, []byte) {
+	t.Helper()
+
+	wif, err := dcrutil.NewWIF(bytes.Repeat([]byte{b}, 32), params.PrivateKeyID, dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(wif.PubKey()), params, wif.DSA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return wif, pkScript
+}
+
+func TestSignRawTx(t *testing.T) {
+	params := chaincfg.TestNet3Params()
+	wif1, pkScript1 := testWIF(t, params, 1)
+	wif2, pkScript2 := testWIF(t, params, 2)
+
+	tests := []struct {
+		name      string
+		wifs      []string
+		pkScripts [][]byte
+		unsigned  []int32
+	}{
+		{
+			name:      "key of the spent output",
+			wifs:      []string{wif1.String()},
+			pkScripts: [][]byte{pkScript1},
+			unsigned:  []int32{},
+		},
+		{
+			name:      "spent output paid to another key",
+			wifs:      []string{wif1.String()},
+			pkScripts: [][]byte{pkScript2},
+			unsigned:  []int32{0},
+		},
+		{
+			name:      "unknown spent output",
+			wifs:      []string{wif1.String()},
+			pkScripts: [][]byte{nil},
+			unsigned:  []int32{0},
+		},
+		{
+			name:      "keys in another order than the inputs",
+			wifs:      []string{wif1.String(), wif2.String()},
+			pkScripts: [][]byte{pkScript2, pkScript1},
+			unsigned:  []int32{},
+		},
+		{
+			name:      "one input signed",
+			wifs:      []string{wif2.String()},
+			pkScripts: [][]byte{pkScript1, pkScript2},
+			unsigned:  []int32{0},
+		},
+	}
+
+	for _, test := range tests {
+		msgTx := wire.NewMsgTx()
+		for i := range test.pkScripts {
+			prevOut := wire.NewOutPoint(&chainhash.Hash{byte(i + 1)}, 0, wire.TxTreeRegular)
+			msgTx.AddTxIn(wire.NewTxIn(prevOut, 1e8, nil))
+		}
+		msgTx.AddTxOut(wire.NewTxOut(1e8-1e5, pkScript1))
+
+		signedTx, err := signRawTx(msgTx, test.wifs, test.pkScripts, params)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if len(signedTx.UnsignedInputs) != len(test.unsigned) {
+			t.Fatalf("%s: unsigned inputs %v, want %v", test.name, signedTx.UnsignedInputs, test.unsigned)
+		}
+		for i := range test.unsigned {
+			if signedTx.UnsignedInputs[i] != test.unsigned[i] {
+				t.Fatalf("%s: unsigned inputs %v, want %v", test.name, signedTx.UnsignedInputs, test.unsigned)
+			}
+		}
+		if signedTx.Complete != (len(test.unsigned) == 0) {
+			t.Fatalf("%s: complete is %v", test.name, signedTx.Complete)
+		}
+
+		for _, i := range test.unsigned {
+			if len(msgTx.TxIn[i].SignatureScript) != 0 {
+				t.Fatalf("%s: unsigned input %d has a signature script", test.name, i)
+			}
+		}
+	}
+}
+
+func TestSignRawTxInvalidKey(t *testing.T) {
+	params := chaincfg.TestNet3Params()
+	_, pkScript := testWIF(t, params, 1)
+
+	msgTx := wire.NewMsgTx()
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular), 1e8, nil))
+	msgTx.AddTxOut(wire.NewTxOut(1e8-1e5, pkScript))
+
+	if _, err := signRawTx(msgTx, []string{"not a key"}, [][]byte{pkScript}, params); err == nil {
+		t.Fatal("invalid key accepted")
+	}
+}
@@ -0,0 +1,157 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// rebroadcastInterval is how often the unmined transactions of synced wallets
+// are automatically rebroadcast.
+const rebroadcastInterval = 30 * time.Minute
+
+// RebroadcastUnminedTransactions sends the unmined transactions of the wallet
+// to the connected peers again and returns the json-encoded list of the
+// `RebroadcastResult` of each transaction.
+func (wallet *Wallet) RebroadcastUnminedTransactions() (string, error) {
+	results, err := wallet.RebroadcastUnminedTransactionsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedResults, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedResults), nil
+}
+
+func (wallet *Wallet) RebroadcastUnminedTransactionsRaw() ([]*RebroadcastResult, error) {
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return nil, errors.New(ErrNotConnected)
+	}
+
+	ctx := wallet.shutdownContext()
+	unminedTxs, err := wallet.internal.UnminedTransactions(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// publish each transaction separately so that a transaction rejected by
+	// peers does not prevent the others from being sent.
+	results := make([]*RebroadcastResult, 0, len(unminedTxs))
+	for _, tx := range unminedTxs {
+		result := &RebroadcastResult{
+			TxHash: tx.TxHash().String(),
+		}
+
+		err = n.PublishTransactions(ctx, tx)
+		if err != nil {
+			result.Error = translateError(err).Error()
+		} else {
+			wallet.countRebroadcast(result.TxHash)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (wallet *Wallet) countRebroadcast(txHash string) {
+	wallet.rebroadcastCountsMu.Lock()
+	defer wallet.rebroadcastCountsMu.Unlock()
+
+	if wallet.rebroadcastCounts == nil {
+		wallet.rebroadcastCounts = make(map[string]int32)
+	}
+	wallet.rebroadcastCounts[txHash]++
+}
+
+func (wallet *Wallet) rebroadcastCount(txHash string) int32 {
+	wallet.rebroadcastCountsMu.Lock()
+	defer wallet.rebroadcastCountsMu.Unlock()
+	return wallet.rebroadcastCounts[txHash]
+}
+
+// GetUnminedTransactions returns the json-encoded `UnminedTransaction`s of
+// the pending transactions of this wallet, newest first. Conflicted
+// transactions, which can never be mined, are not included.
+func (wallet *Wallet) GetUnminedTransactions() (string, error) {
+	unminedTxs, err := wallet.GetUnminedTransactionsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTxs, err := json.Marshal(unminedTxs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTxs), nil
+}
+
+func (wallet *Wallet) GetUnminedTransactionsRaw() ([]*UnminedTransaction, error) {
+	matchers := []q.Matcher{q.Eq("BlockHeight", BlockHeightInvalid), q.Eq("ConflictingTxHash", "")}
+	transactions, err := wallet.readMatchingTransactions(0, 0, matchers, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	unminedTxs := make([]*UnminedTransaction, len(transactions))
+	for i, tx := range transactions {
+		unminedTxs[i] = &UnminedTransaction{
+			Transaction:      tx,
+			Age:              now - tx.Timestamp,
+			RebroadcastCount: wallet.rebroadcastCount(tx.Hash),
+		}
+	}
+
+	return unminedTxs, nil
+}
+
+// rebroadcastUnminedTransactionsPeriodically rebroadcasts the unmined
+// transactions of every synced wallet every `rebroadcastInterval` until `ctx`
+// is canceled, so that transactions dropped by peers are not left unmined.
+func (mw *MultiWallet) rebroadcastUnminedTransactionsPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(rebroadcastInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !mw.IsSynced() {
+			continue
+		}
+
+		for _, wallet := range mw.AllWallets() {
+			if !wallet.WalletOpened() || !wallet.IsSynced() {
+				continue
+			}
+
+			results, err := wallet.RebroadcastUnminedTransactionsRaw()
+			if err != nil {
+				log.Errorf("[%d] Error rebroadcasting unmined transactions: %v", wallet.ID, err)
+				continue
+			}
+
+			for _, result := range results {
+				if result.Error != "" {
+					log.Warnf("[%d] Error rebroadcasting transaction %s: %s", wallet.ID, result.TxHash, result.Error)
+				} else {
+					log.Debugf("[%d] Rebroadcast transaction %s", wallet.ID, result.TxHash)
+				}
+			}
+		}
+	}
+}
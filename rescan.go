@@ -7,15 +7,39 @@ import (
 
 	"github.com/decred/dcrwallet/errors"
 	w "github.com/decred/dcrwallet/wallet/v3"
+	"github.com/raedahgroup/dcrlibwallet/spv"
 )
 
+// RescanBlocks rescans the blocks of the specified wallet from the wallet's
+// birthday height. Blocks mined before the wallet's birthday need not be
+// rescanned.
 func (mw *MultiWallet) RescanBlocks(walletID int) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.E(ErrNotExist)
+	}
+
+	startHeight, err := spv.BirthdayHeight(wallet.shutdownContext(), wallet.internal, wallet.Birthday)
+	if err != nil {
+		return err
+	}
+
+	return mw.RescanBlocksFromHeight(walletID, startHeight)
+}
 
+// RescanBlocksFromHeight rescans the blocks of the specified wallet from
+// `startHeight`. Progress of the rescan is reported to the blocks rescan
+// progress listener. Requires the multiwallet to be synced.
+func (mw *MultiWallet) RescanBlocksFromHeight(walletID int, startHeight int32) error {
 	wallet := mw.WalletWithID(walletID)
 	if wallet == nil {
 		return errors.E(ErrNotExist)
 	}
 
+	if startHeight < 0 {
+		return errors.E(ErrInvalid)
+	}
+
 	netBackend, err := wallet.internal.NetworkBackend()
 	if err != nil {
 		return errors.E(ErrNotConnected)
@@ -45,7 +69,7 @@ func (mw *MultiWallet) RescanBlocks(walletID int) error {
 		}
 
 		progress := make(chan w.RescanProgress, 1)
-		go wallet.internal.RescanProgressFromHeight(ctx, netBackend, 0, progress)
+		go wallet.internal.RescanProgressFromHeight(ctx, netBackend, startHeight, progress)
 
 		rescanStartTime := time.Now().Unix()
 
@@ -98,7 +122,7 @@ func (mw *MultiWallet) RescanBlocks(walletID int) error {
 			}
 		}
 
-		err := wallet.reindexTransactions()
+		err = wallet.reindexTransactions()
 		if mw.blocksRescanProgressListener != nil {
 			mw.blocksRescanProgressListener.OnBlocksRescanEnded(walletID, err)
 		}
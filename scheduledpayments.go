@@ -0,0 +1,358 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+const (
+	// scheduledPaymentsCheckInterval is how often due scheduled payments are
+	// checked for while the multiwallet is syncing.
+	scheduledPaymentsCheckInterval = time.Minute
+
+	// scheduledPaymentRetryInterval is how long after a failed attempt a
+	// scheduled payment is attempted again.
+	scheduledPaymentRetryInterval = 10 * time.Minute
+)
+
+// ScheduledPayment is a payment from an account of a wallet to one or more
+// recipients that is sent at NextRun, and every Interval seconds afterwards
+// for recurring payments. Payments are only sent while the multiwallet is
+// synced and the wallet is unlocked with an unlock session (or its private
+// passphrase is available from the key source), due payments are sent once
+// both conditions are met. Active is false once a one-time payment is sent.
+// InFlightTxHash is the hash of the transaction of the current run, saved
+// before the transaction is broadcast so that a run which fails after the
+// transaction is broadcast does not pay the recipients again.
+type ScheduledPayment struct {
+	ID                   int          `storm:"id,increment" json:"id"`
+	WalletID             int          `storm:"index" json:"wallet_id"`
+	Account              int32        `json:"account"`
+	Outputs              []SendOutput `json:"outputs"`
+	Label                string       `json:"label"`
+	NextRun              int64        `json:"next_run"`
+	Interval             int64        `json:"interval"`
+	RequiresConfirmation bool         `json:"requires_confirmation"`
+	Active               bool         `storm:"index" json:"active"`
+	LastRun              int64        `json:"last_run"`
+	LastTxHash           string       `json:"last_tx_hash"`
+	LastError            string       `json:"last_error"`
+	InFlightTxHash       string       `json:"in_flight_tx_hash"`
+	CreatedAt            time.Time    `json:"created_at"`
+}
+
+// SchedulePayment schedules a payment of the json-encoded array of
+// `SendOutput` from `account` of the specified wallet at the unix time
+// `firstRun`, repeated every `interval` seconds if `interval` is not 0. If
+// `requiresConfirmation` is true, the scheduled payment listener must confirm
+// each payment before it is sent. Returns the ID of the scheduled payment.
+func (mw *MultiWallet) SchedulePayment(walletID int, account int32, jsonEncodedOutputs, label string, firstRun,
+	interval int64, requiresConfirmation bool) (int, error) {
+
+	var outputs []SendOutput
+	err := json.Unmarshal([]byte(jsonEncodedOutputs), &outputs)
+	if err != nil {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	return mw.SchedulePaymentRaw(walletID, account, outputs, label, firstRun, interval, requiresConfirmation)
+}
+
+func (mw *MultiWallet) SchedulePaymentRaw(walletID int, account int32, outputs []SendOutput, label string, firstRun,
+	interval int64, requiresConfirmation bool) (int, error) {
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return 0, errors.New(ErrNotExist)
+	}
+
+	if interval < 0 || firstRun <= 0 {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	// validate the outputs as they would be added to the payment transaction
+	tx := mw.NewUnsignedTx(wallet, account)
+	err := tx.AddSendDestinationsRaw(outputs)
+	if err != nil {
+		return 0, err
+	}
+
+	payment := &ScheduledPayment{
+		WalletID:             walletID,
+		Account:              int32(tx.sourceAccountNumber),
+		Outputs:              outputs,
+		Label:                strings.TrimSpace(label),
+		NextRun:              firstRun,
+		Interval:             interval,
+		RequiresConfirmation: requiresConfirmation,
+		Active:               true,
+		CreatedAt:            time.Now(),
+	}
+
+	err = mw.db.Save(payment)
+	if err != nil {
+		return 0, err
+	}
+
+	return payment.ID, nil
+}
+
+// CancelScheduledPayment deletes the specified scheduled payment.
+func (mw *MultiWallet) CancelScheduledPayment(paymentID int) error {
+	mw.scheduledPaymentsMu.Lock()
+	defer mw.scheduledPaymentsMu.Unlock()
+
+	payment := &ScheduledPayment{}
+	err := mw.db.One("ID", paymentID, payment)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return errors.New(ErrNotExist)
+		}
+		return err
+	}
+
+	return mw.db.DeleteStruct(payment)
+}
+
+// ScheduledPayments returns the json-encoded list of the scheduled payments of
+// the specified wallet, ordered by their next run time.
+func (mw *MultiWallet) ScheduledPayments(walletID int) (string, error) {
+	payments, err := mw.ScheduledPaymentsRaw(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPayments, err := json.Marshal(payments)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPayments), nil
+}
+
+func (mw *MultiWallet) ScheduledPaymentsRaw(walletID int) ([]ScheduledPayment, error) {
+	payments := make([]ScheduledPayment, 0)
+	err := mw.db.Select(q.Eq("WalletID", walletID)).OrderBy("NextRun").Find(&payments)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (mw *MultiWallet) SetScheduledPaymentListener(scheduledPaymentListener ScheduledPaymentListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.scheduledPaymentListener = scheduledPaymentListener
+}
+
+func (mw *MultiWallet) getScheduledPaymentListener() ScheduledPaymentListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.scheduledPaymentListener
+}
+
+// runScheduledPaymentsPeriodically sends due scheduled payments every
+// `scheduledPaymentsCheckInterval` until `ctx` is canceled.
+func (mw *MultiWallet) runScheduledPaymentsPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(scheduledPaymentsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if mw.IsSynced() {
+			mw.runDueScheduledPayments()
+		}
+	}
+}
+
+// runDueScheduledPayments sends the active scheduled payments whose next run
+// time has passed, if their wallets can sign the payments.
+func (mw *MultiWallet) runDueScheduledPayments() {
+	mw.scheduledPaymentsMu.Lock()
+	defer mw.scheduledPaymentsMu.Unlock()
+
+	now := time.Now().Unix()
+
+	var payments []ScheduledPayment
+	err := mw.db.Select(q.Eq("Active", true), q.Lte("NextRun", now)).Find(&payments)
+	if err != nil {
+		if err != storm.ErrNotFound {
+			log.Errorf("Error reading due scheduled payments: %v", err)
+		}
+		return
+	}
+
+	mw.notificationListenersMu.RLock()
+	hasKeySource := mw.keySource != nil
+	mw.notificationListenersMu.RUnlock()
+
+	for i := range payments {
+		payment := &payments[i]
+
+		wallet := mw.WalletWithID(payment.WalletID)
+		if wallet == nil || !wallet.WalletOpened() || !wallet.IsSynced() {
+			continue
+		}
+
+		// the payment stays due until the wallet can sign it
+		if !wallet.HasUnlockSession() && !hasKeySource {
+			continue
+		}
+
+		mw.runScheduledPayment(wallet, payment, now)
+	}
+}
+
+// runScheduledPayment sends a due payment and schedules its next run.
+func (mw *MultiWallet) runScheduledPayment(wallet *Wallet, payment *ScheduledPayment, now int64) {
+	listener := mw.getScheduledPaymentListener()
+
+	var txHash string
+	var err error
+	if payment.InFlightTxHash != "" && wallet.hasTransaction(payment.InFlightTxHash) {
+		// the previous attempt was recorded by the wallet before it failed,
+		// the payment was sent
+		txHash = payment.InFlightTxHash
+	} else {
+		txHash, err = mw.sendScheduledPayment(wallet, payment, listener)
+	}
+
+	if err == errScheduledPaymentDeclined {
+		log.Infof("[%d] Scheduled payment %d was declined", wallet.ID, payment.ID)
+	} else if err != nil {
+		log.Errorf("[%d] Error sending scheduled payment %d: %v", wallet.ID, payment.ID, err)
+
+		payment.LastError = err.Error()
+		payment.NextRun = now + int64(scheduledPaymentRetryInterval/time.Second)
+		if err = mw.db.Save(payment); err != nil {
+			log.Errorf("[%d] Error updating scheduled payment %d: %v", wallet.ID, payment.ID, err)
+		}
+
+		if listener != nil {
+			listener.OnScheduledPaymentFailed(payment.ID, payment.LastError)
+		}
+		return
+	} else {
+		log.Infof("[%d] Sent scheduled payment %d in transaction %s", wallet.ID, payment.ID, txHash)
+
+		payment.LastRun = now
+		payment.LastTxHash = txHash
+		payment.LastError = ""
+	}
+	payment.InFlightTxHash = ""
+
+	// missed runs of recurring payments are skipped rather than sent at once
+	if payment.Interval > 0 {
+		for payment.NextRun <= now {
+			payment.NextRun += payment.Interval
+		}
+	} else {
+		payment.Active = false
+	}
+
+	// Save rather than Update, which skips zero values such as Active and
+	// the cleared LastError.
+	err = mw.db.Save(payment)
+	if err != nil {
+		log.Errorf("[%d] Error updating scheduled payment %d: %v", wallet.ID, payment.ID, err)
+	}
+
+	if txHash != "" && listener != nil {
+		listener.OnScheduledPaymentSent(payment.ID, txHash)
+	}
+}
+
+var errScheduledPaymentDeclined = errors.New("scheduled payment declined")
+
+// sendScheduledPayment constructs and broadcasts the transaction of a due
+// payment, after it is confirmed by `listener` if the payment requires
+// confirmation. Returns the hash of the transaction.
+func (mw *MultiWallet) sendScheduledPayment(wallet *Wallet, payment *ScheduledPayment,
+	listener ScheduledPaymentListener) (string, error) {
+
+	tx := mw.NewUnsignedTx(wallet, payment.Account)
+	err := tx.AddSendDestinationsRaw(payment.Outputs)
+	if err != nil {
+		return "", err
+	}
+
+	if payment.RequiresConfirmation {
+		if listener == nil {
+			return "", errors.New(ErrFailedPrecondition)
+		}
+
+		preview, err := tx.Preview()
+		if err != nil {
+			return "", err
+		}
+
+		if !listener.ConfirmScheduledPayment(payment.ID, preview) {
+			return "", errScheduledPaymentDeclined
+		}
+	}
+
+	n, err := wallet.internal.NetworkBackend()
+	if err != nil {
+		return "", err
+	}
+
+	msgTx, err := tx.signTransaction(nil)
+	if err != nil {
+		return "", err
+	}
+
+	// the transaction is recorded as in flight before it is broadcast, the
+	// wallet is checked for it before the payment is attempted again
+	payment.InFlightTxHash = msgTx.TxHash().String()
+	err = mw.db.Save(payment)
+	if err != nil {
+		payment.InFlightTxHash = ""
+		return "", err
+	}
+
+	txHash, err := tx.publish(msgTx, n)
+	if err != nil {
+		// a transaction recorded by the wallet before relaying failed is
+		// sent, the wallet relays it again
+		if wallet.hasTransaction(payment.InFlightTxHash) {
+			log.Errorf("[%d] Error relaying scheduled payment %d: %v", wallet.ID, payment.ID, err)
+			return payment.InFlightTxHash, nil
+		}
+		payment.InFlightTxHash = ""
+		return "", err
+	}
+
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// hasTransaction returns true if the transaction with the hex-encoded hash
+// `txHash` is recorded by the wallet.
+func (wallet *Wallet) hasTransaction(txHash string) bool {
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return false
+	}
+
+	txs, _, err := wallet.internal.GetTransactionsByHashes(wallet.shutdownContext(), []*chainhash.Hash{hash})
+	return err == nil && len(txs) > 0
+}
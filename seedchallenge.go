@@ -0,0 +1,189 @@
+package dcrlibwallet
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+const (
+	// DefaultSeedChallengeCount is the number of seed words a user is asked
+	// to enter to confirm that the seed has been backed up.
+	DefaultSeedChallengeCount = 3
+
+	// seedChallengeOptionsCount is the number of words, including the
+	// correct word, offered as options for each challenge.
+	seedChallengeOptionsCount = 3
+)
+
+// SeedWordChallenge asks the user to enter the seed word at `Position`.
+// Positions are 1-based. `Options` contains the correct word and other
+// words from the same seed in random order, for apps that present the
+// challenge as a multiple choice.
+type SeedWordChallenge struct {
+	Position int32    `json:"position"`
+	Options  []string `json:"options"`
+}
+
+// SeedWordAnswer is the word entered by the user for the challenge at `Position`.
+type SeedWordAnswer struct {
+	Position int32  `json:"position"`
+	Word     string `json:"word"`
+}
+
+// SeedChallenge returns a json-encoded array of `SeedWordChallenge`s for
+// `seedMnemonic`. The same challenges are always returned for a given seed,
+// so that the challenges do not change if the user leaves the backup screen.
+func SeedChallenge(seedMnemonic string, challengeCount int32) (string, error) {
+	challenges, err := SeedChallengeRaw(seedMnemonic, challengeCount)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedChallenges, err := json.Marshal(challenges)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedChallenges), nil
+}
+
+func SeedChallengeRaw(seedMnemonic string, challengeCount int32) ([]*SeedWordChallenge, error) {
+	words := strings.Fields(seedMnemonic)
+	if len(words) == 0 || !VerifySeed(seedMnemonic) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	if challengeCount <= 0 || int(challengeCount) > len(words) {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	rng := seedChallengeRand(words)
+
+	positions := rng.Perm(len(words))[:challengeCount]
+	sort.Ints(positions)
+
+	challenges := make([]*SeedWordChallenge, len(positions))
+	for i, position := range positions {
+		options := []string{words[position]}
+		for _, otherPosition := range rng.Perm(len(words)) {
+			if len(options) == seedChallengeOptionsCount {
+				break
+			}
+			if !containsWord(options, words[otherPosition]) {
+				options = append(options, words[otherPosition])
+			}
+		}
+		rng.Shuffle(len(options), func(a, b int) {
+			options[a], options[b] = options[b], options[a]
+		})
+
+		challenges[i] = &SeedWordChallenge{
+			Position: int32(position + 1),
+			Options:  options,
+		}
+	}
+
+	return challenges, nil
+}
+
+// VerifySeedChallenge checks the json-encoded array of `SeedWordAnswer`s
+// against the challenges returned by `SeedChallenge` for `seedMnemonic`.
+// Returns true only if every challenge is answered correctly.
+func VerifySeedChallenge(seedMnemonic string, challengeCount int32, jsonEncodedAnswers string) (bool, error) {
+	var answers []*SeedWordAnswer
+	err := json.Unmarshal([]byte(jsonEncodedAnswers), &answers)
+	if err != nil {
+		return false, errors.New(ErrInvalid)
+	}
+
+	return VerifySeedChallengeRaw(seedMnemonic, challengeCount, answers)
+}
+
+func VerifySeedChallengeRaw(seedMnemonic string, challengeCount int32, answers []*SeedWordAnswer) (bool, error) {
+	challenges, err := SeedChallengeRaw(seedMnemonic, challengeCount)
+	if err != nil {
+		return false, err
+	}
+
+	answeredWords := make(map[int32]string, len(answers))
+	for _, answer := range answers {
+		answeredWords[answer.Position] = strings.TrimSpace(answer.Word)
+	}
+
+	words := strings.Fields(seedMnemonic)
+	for _, challenge := range challenges {
+		answer, ok := answeredWords[challenge.Position]
+		if !ok || !strings.EqualFold(answer, words[challenge.Position-1]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SeedChallengeForWallet returns the seed challenges for the specified wallet
+// if the wallet's seed has not been backed up.
+func (mw *MultiWallet) SeedChallengeForWallet(walletID int, challengeCount int32) (string, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return "", errors.New(ErrNotExist)
+	}
+
+	if wallet.Seed == "" {
+		return "", errors.New(ErrEmptySeed)
+	}
+
+	return SeedChallenge(wallet.Seed, challengeCount)
+}
+
+// VerifySeedChallengeForWallet verifies the answers to the seed challenges for
+// the specified wallet. The wallet's seed is marked as backed up and removed
+// from the database if all challenges are answered correctly.
+func (mw *MultiWallet) VerifySeedChallengeForWallet(walletID int, challengeCount int32, jsonEncodedAnswers string) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if wallet.Seed == "" {
+		return errors.New(ErrEmptySeed)
+	}
+
+	verified, err := VerifySeedChallenge(wallet.Seed, challengeCount, jsonEncodedAnswers)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.New(ErrInvalid)
+	}
+
+	wallet.Seed = ""
+	return translateError(mw.db.Save(wallet))
+}
+
+// seedChallengeRand returns a random number generator seeded with a hash of
+// the seed words, so that challenges are reproducible for the same seed.
+func seedChallengeRand(words []string) *rand.Rand {
+	normalizedWords := make([]string, len(words))
+	for i, word := range words {
+		normalizedWords[i] = strings.ToLower(word)
+	}
+
+	hash := sha256.Sum256([]byte("seed challenge " + strings.Join(normalizedWords, " ")))
+	return rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(hash[:8]))))
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package dcrlibwallet
+
+import (
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/walletseed"
+	"github.com/raedahgroup/dcrlibwallet/bip39"
+)
+
+// Seed QR payloads are compact binary encodings of a seed, similar to the
+// CompactSeedQR format, meant to be displayed as a binary mode QR code.
+// The first byte of the payload is the seed format:
+//   - seedQRFormatPGP: format || seed bytes
+//   - seedQRFormatBIP39: format || language index || entropy bytes
+//
+// where language index is the index of the word list in `bip39.Languages`.
+const (
+	seedQRFormatPGP   byte = 1
+	seedQRFormatBIP39 byte = 2
+)
+
+// EncodeSeedQR returns the QR payload for `seedMnemonic`. The mnemonic can be
+// restored from the payload using `DecodeSeedQR`.
+func EncodeSeedQR(seedMnemonic string) ([]byte, error) {
+	seed, err := walletseed.DecodeUserInput(seedMnemonic)
+	if err == nil {
+		return append([]byte{seedQRFormatPGP}, seed...), nil
+	}
+
+	language, err := bip39.DetectLanguage(seedMnemonic)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	wordList, _ := bip39.WordList(language)
+	entropy, err := bip39.MnemonicToEntropy(seedMnemonic, wordList)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	for languageIndex, l := range bip39.Languages {
+		if l == language {
+			return append([]byte{seedQRFormatBIP39, byte(languageIndex)}, entropy...), nil
+		}
+	}
+
+	return nil, errors.New(ErrInvalid)
+}
+
+// DecodeSeedQR returns the seed mnemonic encoded in a payload created with
+// `EncodeSeedQR`.
+func DecodeSeedQR(payload []byte) (string, error) {
+	if len(payload) < 2 {
+		return "", errors.New(ErrInvalid)
+	}
+
+	switch payload[0] {
+	case seedQRFormatPGP:
+		seedMnemonic := walletseed.EncodeMnemonic(payload[1:])
+		if !VerifySeed(seedMnemonic) {
+			return "", errors.New(ErrInvalid)
+		}
+		return seedMnemonic, nil
+
+	case seedQRFormatBIP39:
+		languageIndex := int(payload[1])
+		if languageIndex >= len(bip39.Languages) {
+			return "", errors.New(ErrInvalid)
+		}
+
+		wordList, err := bip39.WordList(bip39.Languages[languageIndex])
+		if err != nil {
+			return "", errors.New(ErrInvalid)
+		}
+
+		seedMnemonic, err := bip39.EntropyToMnemonic(payload[2:], wordList)
+		if err != nil {
+			return "", errors.New(ErrInvalid)
+		}
+		return seedMnemonic, nil
+
+	default:
+		return "", errors.New(ErrInvalid)
+	}
+}
@@ -0,0 +1,256 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/pgpwordlist"
+	"github.com/decred/dcrwallet/walletseed"
+)
+
+// Seed shares are created using Shamir's secret sharing over GF(256) of the
+// seed QR payload of the mnemonic, which holds the entropy encoded by the
+// mnemonic and its word list, followed by a digest of the payload, which
+// detects shares of different seeds or splits being combined. Splitting the
+// entropy instead of the wallet seed restores the original mnemonic, so a
+// seed passphrase used with it derives the same wallet. Each share is
+// serialized as threshold || share index || share bytes and encoded using the
+// same PGP word list and checksum word as seed mnemonics. This is a format of
+// this library, shares are not SLIP-0039 mnemonics and cannot be combined by
+// SLIP-0039 wallets.
+const (
+	minSeedShareThreshold = 2
+	maxSeedShares         = 255
+	seedShareDigestSize   = 4
+)
+
+var gfExp, gfLog [256]byte
+
+func init() {
+	// generate the exponent and logarithm tables for GF(256) using the
+	// AES reduction polynomial x^8 + x^4 + x^3 + x + 1 and generator 3.
+	var x byte = 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfLog[x] = byte(i)
+		x ^= gfDouble(x)
+	}
+	gfExp[255] = gfExp[0]
+}
+
+func gfDouble(x byte) byte {
+	if x&0x80 != 0 {
+		return (x << 1) ^ 0x1b
+	}
+	return x << 1
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])-int(gfLog[b])+255)%255]
+}
+
+// SplitSeedMnemonic splits the seed mnemonic `seedMnemonic`, a PGP word list
+// or BIP0039 mnemonic, into `shareCount` shares, any `threshold` of which can
+// be combined using `CombineSeedMnemonicShares` to restore the mnemonic, while
+// fewer shares reveal nothing about the seed. `threshold` must be at least 2,
+// as a single share would be a copy of the seed, and `shareCount` at most 255.
+// Returns the json-encoded array of share mnemonics, in the PGP word list.
+func SplitSeedMnemonic(seedMnemonic string, threshold, shareCount int32) (string, error) {
+	shares, err := SplitSeedMnemonicRaw(seedMnemonic, threshold, shareCount)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedShares, err := json.Marshal(shares)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedShares), nil
+}
+
+func SplitSeedMnemonicRaw(seedMnemonic string, threshold, shareCount int32) ([]string, error) {
+	if threshold < minSeedShareThreshold || shareCount < threshold || shareCount > maxSeedShares {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	payload, err := EncodeSeedQR(seedMnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 0, len(payload)+seedShareDigestSize)
+	secret = append(secret, payload...)
+	secret = append(secret, seedShareDigest(payload)...)
+	defer func() {
+		for i := range payload {
+			payload[i] = 0
+		}
+		for i := range secret {
+			secret[i] = 0
+		}
+	}()
+
+	// each byte of the secret is the constant term of a random polynomial of
+	// degree threshold-1, coefficients[i] holds the coefficients for
+	// secret[i].
+	coefficients := make([][]byte, len(secret))
+	for i := range secret {
+		coefficients[i] = make([]byte, threshold)
+		coefficients[i][0] = secret[i]
+		if _, err := rand.Read(coefficients[i][1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make([]string, shareCount)
+	for shareIndex := range shares {
+		x := byte(shareIndex + 1)
+
+		share := make([]byte, 2+len(secret))
+		share[0] = byte(threshold)
+		share[1] = x
+		for i := range secret {
+			// evaluate the polynomial at x using Horner's method
+			var y byte
+			for c := threshold - 1; c >= 0; c-- {
+				y = gfMul(y, x) ^ coefficients[i][c]
+			}
+			share[2+i] = y
+		}
+
+		shares[shareIndex] = walletseed.EncodeMnemonic(share)
+	}
+
+	for i := range coefficients {
+		for j := range coefficients[i] {
+			coefficients[i][j] = 0
+		}
+	}
+
+	return shares, nil
+}
+
+// CombineSeedMnemonicShares restores the seed mnemonic from the json-encoded
+// array of share mnemonics created with `SplitSeedMnemonic`. The mnemonic is
+// restored in the word list it was split from. Shares beyond the threshold are
+// ignored. Returns `ErrInvalid` if the shares are not from the same split of
+// a seed.
+func CombineSeedMnemonicShares(jsonEncodedShares string) (string, error) {
+	var shares []string
+	err := json.Unmarshal([]byte(jsonEncodedShares), &shares)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	return CombineSeedMnemonicSharesRaw(shares)
+}
+
+func CombineSeedMnemonicSharesRaw(shareMnemonics []string) (string, error) {
+	if len(shareMnemonics) == 0 {
+		return "", errors.New(ErrInvalid)
+	}
+
+	shares := make([][]byte, 0, len(shareMnemonics))
+	seenIndexes := make(map[byte]bool)
+	for _, shareMnemonic := range shareMnemonics {
+		share, err := decodeSeedShareMnemonic(shareMnemonic)
+		if err != nil {
+			return "", err
+		}
+
+		if len(share) < 4+seedShareDigestSize || int(share[0]) < minSeedShareThreshold {
+			return "", errors.New(ErrInvalid)
+		}
+		if len(shares) > 0 && len(share) != len(shares[0]) {
+			return "", errors.New(ErrInvalid)
+		}
+		if len(shares) > 0 && share[0] != shares[0][0] {
+			return "", errors.New(ErrInvalid)
+		}
+		if share[1] == 0 || seenIndexes[share[1]] {
+			return "", errors.New(ErrInvalid)
+		}
+
+		seenIndexes[share[1]] = true
+		shares = append(shares, share)
+	}
+
+	threshold := int(shares[0][0])
+	if len(shares) < threshold {
+		return "", errors.New(ErrInvalid)
+	}
+	shares = shares[:threshold]
+
+	// use lagrange interpolation to evaluate the polynomials at x = 0
+	secret := make([]byte, len(shares[0])-2)
+	defer func() {
+		for i := range secret {
+			secret[i] = 0
+		}
+	}()
+
+	for i, share := range shares {
+		xi := share[1]
+
+		var basis byte = 1
+		for j, otherShare := range shares {
+			if i == j {
+				continue
+			}
+			xj := otherShare[1]
+			basis = gfMul(basis, gfDiv(xj, xj^xi))
+		}
+
+		for b := range secret {
+			secret[b] ^= gfMul(share[2+b], basis)
+		}
+	}
+
+	payload := secret[:len(secret)-seedShareDigestSize]
+	digest := seedShareDigest(payload)
+	if !bytes.Equal(digest, secret[len(payload):]) {
+		return "", errors.New(ErrInvalid)
+	}
+
+	return DecodeSeedQR(payload)
+}
+
+// seedShareDigest returns the digest of `data` that is split with it.
+func seedShareDigest(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return digest[:seedShareDigestSize]
+}
+
+// decodeSeedShareMnemonic decodes a share mnemonic and verifies its checksum
+// word. Shares of 64-byte seeds are longer than the seeds accepted by
+// `walletseed.DecodeUserInput`, so the words are decoded here.
+func decodeSeedShareMnemonic(shareMnemonic string) ([]byte, error) {
+	decoded, err := pgpwordlist.DecodeMnemonics(strings.Fields(shareMnemonic))
+	if err != nil || len(decoded) < 2 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	share, checksum := decoded[:len(decoded)-1], decoded[len(decoded)-1]
+	hash := sha256.Sum256(share)
+	hash = sha256.Sum256(hash[:])
+	if hash[0] != checksum {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return share, nil
+}
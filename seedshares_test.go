@@ -0,0 +1,126 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrwallet/walletseed"
+	"github.com/raedahgroup/dcrlibwallet/bip39"
+)
+
+func TestSplitCombineSeed(t *testing.T) {
+	seedMnemonic := walletseed.EncodeMnemonic(bytes.Repeat([]byte{0x2a}, 32))
+	longSeedMnemonic := walletseed.EncodeMnemonic(bytes.Repeat([]byte{0x2a}, 64))
+	bip39Mnemonic, err := bip39.EntropyToMnemonic(bytes.Repeat([]byte{0x2a}, 32), bip39.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortBIP39Mnemonic, err := bip39.EntropyToMnemonic(bytes.Repeat([]byte{0x2a}, 16), bip39.Spanish)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		seedMnemonic string
+		threshold    int32
+		shareCount   int32
+		combine      []int
+	}{
+		{name: "2 of 2", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 2, combine: []int{0, 1}},
+		{name: "2 of 3, first shares", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 3, combine: []int{0, 1}},
+		{name: "2 of 3, last shares reversed", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 3, combine: []int{2, 1}},
+		{name: "2 of 3, all shares", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 3, combine: []int{0, 1, 2}},
+		{name: "3 of 5", seedMnemonic: seedMnemonic, threshold: 3, shareCount: 5, combine: []int{4, 0, 2}},
+		{name: "5 of 5", seedMnemonic: seedMnemonic, threshold: 5, shareCount: 5, combine: []int{0, 1, 2, 3, 4}},
+		{name: "2 of 255", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 255, combine: []int{253, 254}},
+		{name: "64-byte seed", seedMnemonic: longSeedMnemonic, threshold: 2, shareCount: 3, combine: []int{0, 2}},
+		{name: "BIP0039 mnemonic", seedMnemonic: bip39Mnemonic, threshold: 2, shareCount: 3, combine: []int{1, 2}},
+		{name: "12-word spanish BIP0039 mnemonic", seedMnemonic: shortBIP39Mnemonic, threshold: 3, shareCount: 4, combine: []int{3, 1, 0}},
+	}
+
+	for _, test := range tests {
+		shares, err := SplitSeedMnemonicRaw(test.seedMnemonic, test.threshold, test.shareCount)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(shares) != int(test.shareCount) {
+			t.Fatalf("%s: %d shares, want %d", test.name, len(shares), test.shareCount)
+		}
+
+		combine := make([]string, len(test.combine))
+		for i, shareIndex := range test.combine {
+			combine[i] = shares[shareIndex]
+		}
+		combined, err := CombineSeedMnemonicSharesRaw(combine)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if combined != test.seedMnemonic {
+			t.Fatalf("%s: combined %q, want %q", test.name, combined, test.seedMnemonic)
+		}
+
+		if _, err := CombineSeedMnemonicSharesRaw(combine[:test.threshold-1]); err == nil {
+			t.Fatalf("%s: combined fewer shares than the threshold", test.name)
+		}
+	}
+}
+
+func TestSplitSeedInvalid(t *testing.T) {
+	seedMnemonic := walletseed.EncodeMnemonic(bytes.Repeat([]byte{0x2a}, 32))
+
+	tests := []struct {
+		name         string
+		seedMnemonic string
+		threshold    int32
+		shareCount   int32
+	}{
+		{name: "threshold 0", seedMnemonic: seedMnemonic, threshold: 0, shareCount: 3},
+		{name: "threshold 1", seedMnemonic: seedMnemonic, threshold: 1, shareCount: 3},
+		{name: "fewer shares than threshold", seedMnemonic: seedMnemonic, threshold: 3, shareCount: 2},
+		{name: "too many shares", seedMnemonic: seedMnemonic, threshold: 2, shareCount: 256},
+		{name: "invalid seed", seedMnemonic: "not a seed", threshold: 2, shareCount: 3},
+	}
+
+	for _, test := range tests {
+		if _, err := SplitSeedMnemonicRaw(test.seedMnemonic, test.threshold, test.shareCount); err == nil {
+			t.Fatalf("%s: seed split", test.name)
+		}
+	}
+}
+
+func TestCombineSeedSharesInvalid(t *testing.T) {
+	seedMnemonic := walletseed.EncodeMnemonic(bytes.Repeat([]byte{0x2a}, 32))
+	otherSeedMnemonic := walletseed.EncodeMnemonic(bytes.Repeat([]byte{0x2b}, 32))
+
+	split := func(seedMnemonic string, threshold, shareCount int32) []string {
+		shares, err := SplitSeedMnemonicRaw(seedMnemonic, threshold, shareCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return shares
+	}
+	shares := split(seedMnemonic, 2, 3)
+	resplitShares := split(seedMnemonic, 2, 3)
+	otherSeedShares := split(otherSeedMnemonic, 2, 3)
+	otherThresholdShares := split(seedMnemonic, 3, 3)
+
+	tests := []struct {
+		name   string
+		shares []string
+	}{
+		{name: "no shares", shares: nil},
+		{name: "duplicate share", shares: []string{shares[0], shares[0]}},
+		{name: "shares of another split", shares: []string{shares[0], resplitShares[1]}},
+		{name: "shares of another seed", shares: []string{shares[0], otherSeedShares[1]}},
+		{name: "shares of another threshold", shares: []string{shares[0], otherThresholdShares[1]}},
+		{name: "seed mnemonic", shares: []string{seedMnemonic, shares[1]}},
+		{name: "invalid mnemonic", shares: []string{shares[0], "not a share"}},
+	}
+
+	for _, test := range tests {
+		if _, err := CombineSeedMnemonicSharesRaw(test.shares); err == nil {
+			t.Fatalf("%s: shares combined", test.name)
+		}
+	}
+}
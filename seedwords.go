@@ -0,0 +1,56 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/walletseed"
+	"github.com/raedahgroup/dcrlibwallet/bip39"
+)
+
+// PGPWordListLanguage is returned by `DetectSeedLanguage` for seeds encoded
+// using the PGP word list, which is the default encoding for wallet seeds.
+const PGPWordListLanguage = "pgp"
+
+// SeedWordListLanguages returns the json-encoded array of languages that
+// BIP-0039 seeds can be generated and verified in.
+func SeedWordListLanguages() (string, error) {
+	jsonEncodedLanguages, err := json.Marshal(bip39.Languages)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedLanguages), nil
+}
+
+// SeedWordList returns the json-encoded BIP-0039 word list for `language`,
+// for apps to offer word suggestions while a seed is entered.
+func SeedWordList(language string) (string, error) {
+	wordList, err := bip39.WordList(language)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	jsonEncodedWords, err := json.Marshal(wordList)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedWords), nil
+}
+
+// DetectSeedLanguage returns `PGPWordListLanguage` if `seedMnemonic` is a PGP
+// word list seed or the language of the BIP-0039 word list used to encode
+// `seedMnemonic`. An error is returned if `seedMnemonic` is not a valid seed.
+func DetectSeedLanguage(seedMnemonic string) (string, error) {
+	if _, err := walletseed.DecodeUserInput(seedMnemonic); err == nil {
+		return PGPWordListLanguage, nil
+	}
+
+	language, err := bip39.DetectLanguage(seedMnemonic)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	return language, nil
+}
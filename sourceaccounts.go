@@ -0,0 +1,101 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/decred/dcrwallet/errors/v2"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// SetSourceAccounts sets the json-encoded array of account numbers whose
+// spendable outputs may fund this transaction, e.g. the default and imported
+// accounts, so that one transaction can spend the funds of several accounts.
+// Change is still sent to the change destination of the source account.
+func (tx *TxAuthor) SetSourceAccounts(jsonEncodedAccounts string) error {
+	var accounts []int32
+	err := json.Unmarshal([]byte(jsonEncodedAccounts), &accounts)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return tx.SetSourceAccountsRaw(accounts)
+}
+
+func (tx *TxAuthor) SetSourceAccountsRaw(accounts []int32) error {
+	if len(accounts) == 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	sourceAccounts := make([]uint32, 0, len(accounts))
+	seen := make(map[int32]bool, len(accounts))
+	for _, account := range accounts {
+		if account < 0 {
+			return errors.New(ErrInvalid)
+		}
+		if seen[account] {
+			continue
+		}
+
+		_, err := tx.sourceWallet.AccountNameRaw(uint32(account))
+		if err != nil {
+			return translateError(err)
+		}
+
+		seen[account] = true
+		sourceAccounts = append(sourceAccounts, uint32(account))
+	}
+
+	tx.sourceAccounts = sourceAccounts
+	return nil
+}
+
+// ClearSourceAccounts removes the accounts set with `SetSourceAccounts`, only
+// the source account funds this transaction afterwards.
+func (tx *TxAuthor) ClearSourceAccounts() {
+	tx.sourceAccounts = nil
+}
+
+// inputAccounts returns the accounts whose outputs may fund this transaction.
+func (tx *TxAuthor) inputAccounts() []uint32 {
+	if len(tx.sourceAccounts) > 0 {
+		return tx.sourceAccounts
+	}
+	return []uint32{tx.sourceAccountNumber}
+}
+
+// spendsMixedAccount returns true if the mixed account may fund this
+// transaction.
+func (tx *TxAuthor) spendsMixedAccount() bool {
+	for _, account := range tx.inputAccounts() {
+		if tx.sourceWallet.isMixedAccount(account) {
+			return true
+		}
+	}
+	return false
+}
+
+// unspentOutputs returns the unspent outputs of the accounts that may fund
+// this transaction. Outputs of the mixed account that were not received from
+// a mix are excluded.
+func (tx *TxAuthor) unspentOutputs(ctx context.Context) ([]*w.TransactionOutput, error) {
+	var outputs []*w.TransactionOutput
+	for _, account := range tx.inputAccounts() {
+		policy := w.OutputSelectionPolicy{
+			Account:               account,
+			RequiredConfirmations: 0,
+		}
+		accountOutputs, err := tx.sourceWallet.internal.UnspentOutputs(ctx, policy)
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		// only mixed outputs are spent from the mixed account
+		if tx.sourceWallet.isMixedAccount(account) {
+			accountOutputs = tx.sourceWallet.mixedOutputs(ctx, accountOutputs)
+		}
+		outputs = append(outputs, accountOutputs...)
+	}
+
+	return outputs, nil
+}
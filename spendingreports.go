@@ -0,0 +1,98 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// SpendingReport is the total amount, in atoms, received, sent, paid in fees
+// and earned from votes by the transactions of the period starting at the
+// unix timestamp PeriodStart.
+type SpendingReport struct {
+	PeriodStart      int64 `json:"period_start"`
+	TransactionCount int   `json:"transaction_count"`
+	Received         int64 `json:"received"`
+	Sent             int64 `json:"sent"`
+	Fees             int64 `json:"fees"`
+	StakingRewards   int64 `json:"staking_rewards"`
+}
+
+// GetSpendingReports returns the json-encoded `SpendingReport`s of every day,
+// week or month, as set by `resolution`, with transactions within the unix
+// timestamps `from` and `to`, inclusive, oldest period first. Periods without
+// transactions are left out. Only the transactions of `account` are reported
+// if `account` is not negative and only transactions tagged `tag` if `tag` is
+// not empty. Zero timestamps do not limit the transactions.
+func (wallet *Wallet) GetSpendingReports(resolution string, account int32, tag string, from, to int64) (string, error) {
+	reports, err := wallet.GetSpendingReportsRaw(resolution, account, tag, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedReports, err := json.Marshal(reports)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedReports), nil
+}
+
+func (wallet *Wallet) GetSpendingReportsRaw(resolution string, account int32, tag string,
+	from, to int64) ([]*SpendingReport, error) {
+
+	if _, err := periodStart(time.Now(), resolution); err != nil {
+		return nil, err
+	}
+
+	filter := &TransactionFilter{From: from, To: to}
+	if account >= 0 {
+		filter.Accounts = []int32{account}
+	}
+	if tag != "" {
+		filter.Tags = []string{tag}
+	}
+
+	page, err := wallet.FilterTransactionsRaw(0, 0, filter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*SpendingReport, 0)
+	var report *SpendingReport
+	for i := range page.Transactions {
+		tx := &page.Transactions[i]
+
+		period, _ := periodStart(time.Unix(tx.Timestamp, 0), resolution)
+		if report == nil || report.PeriodStart != period.Unix() {
+			report = &SpendingReport{PeriodStart: period.Unix()}
+			reports = append(reports, report)
+		}
+
+		report.addTransaction(tx, account)
+	}
+
+	return reports, nil
+}
+
+// addTransaction adds the amounts of `tx` for `account`, or for all accounts
+// if `account` is negative, to this report.
+func (report *SpendingReport) addTransaction(tx *Transaction, account int32) {
+	report.TransactionCount++
+	if tx.Type == txhelper.TxTypeVote {
+		// the reward is what the vote returns on top of the ticket price
+		report.StakingRewards += accountBalanceChange(tx, account)
+		return
+	}
+
+	switch tx.Direction {
+	case txhelper.TxDirectionReceived:
+		report.Received += tx.Amount
+	case txhelper.TxDirectionSent:
+		report.Sent += tx.Amount
+		report.Fees += tx.Fee
+	case txhelper.TxDirectionTransferred:
+		report.Fees += tx.Fee
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"context"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/wallet/v3"
+)
+
+// birthdayMargin is subtracted from wallet birthdays to account for
+// inaccurate block timestamps and device clocks.
+const birthdayMargin = 24 * time.Hour
+
+// SetBirthdays sets the creation times of the wallets being synced. Address
+// discovery and rescans for a wallet will not start from blocks mined before
+// the wallet's birthday. Wallets without a birthday are synced from the
+// wallet's rescan point.
+func (s *Syncer) SetBirthdays(birthdays map[int]time.Time) {
+	s.birthdays = birthdays
+}
+
+// BirthdayHeight returns the height of the first main chain block mined after
+// `birthday`, less a margin of a day. Returns 0 if `birthday` is zero.
+func BirthdayHeight(ctx context.Context, w *wallet.Wallet, birthday time.Time) (int32, error) {
+	if birthday.IsZero() {
+		return 0, nil
+	}
+
+	birthdayTimestamp := birthday.Add(-birthdayMargin).Unix()
+
+	// binary search for the first block with a timestamp after the birthday
+	_, tipHeight := w.MainChainTip(ctx)
+	low, high := int32(0), tipHeight
+	for low < high {
+		mid := low + (high-low)/2
+		info, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(mid))
+		if err != nil {
+			return 0, err
+		}
+
+		if info.Timestamp < birthdayTimestamp {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// birthdayRescanPoint returns the block at the wallet's birthday height if
+// `rescanPoint` is a block mined before the wallet's birthday. Otherwise,
+// `rescanPoint` is returned.
+func (s *Syncer) birthdayRescanPoint(ctx context.Context, walletID int, rescanPoint *chainhash.Hash) (*chainhash.Hash, error) {
+	birthday, ok := s.birthdays[walletID]
+	if !ok || birthday.IsZero() {
+		return rescanPoint, nil
+	}
+
+	w := s.wallets[walletID]
+	rescanBlock, err := w.BlockHeader(ctx, rescanPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	birthdayHeight, err := BirthdayHeight(ctx, w, birthday)
+	if err != nil {
+		return nil, err
+	}
+
+	if int32(rescanBlock.Height) >= birthdayHeight {
+		return rescanPoint, nil
+	}
+
+	info, err := w.BlockInfo(ctx, wallet.NewBlockIdentifierFromHeight(birthdayHeight))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Wallet %d: skipping blocks before birthday height %d", walletID, birthdayHeight)
+	return &info.Hash, nil
+}
@@ -40,6 +40,10 @@ type Syncer struct {
 	wallets map[int]*wallet.Wallet
 	lp      *p2p.LocalPeer
 
+	// birthdays holds the creation times of wallets, blocks mined
+	// before a wallet's birthday are not scanned for the wallet.
+	birthdays map[int]time.Time
+
 	// Protected by atomicCatchUpTryLock
 	loadedFilters map[int]bool
 
@@ -57,6 +61,11 @@ type Syncer struct {
 	filterData   map[int]*blockcf.Entries
 	filterMu     sync.Mutex
 
+	// Watch filter for addresses that are not owned by any wallet.
+	// Protected by filterMu.
+	watchFilter     *wallet.RescanFilter
+	watchFilterData *blockcf.Entries
+
 	// seenTxs records hashes of received inventoried transactions.  Once a
 	// transaction is fetched and processed from one peer, the hash is added to
 	// this cache to avoid fetching it again from other peers that announce the
@@ -105,6 +114,18 @@ type Notifications struct {
 	// unspecified order.
 	// reorgDepth is guaranteed to be non-negative.
 	TipChanged func(tip *wire.BlockHeader, reorgDepth int32, txs []*wire.MsgTx)
+
+	// WatchedTxs is called when transactions that pay to watched addresses or
+	// spend watched outpoints are observed. blockHeight is -1 for unmined
+	// transactions.
+	WatchedTxs func(txs []*wire.MsgTx, blockHeight int32)
+
+	// WatchedBlocksDetached is called when the blocks from blockHeight are
+	// removed from the main chain, so that the changes caused by watched
+	// transactions mined in them can be reverted. Watched transactions mined
+	// in the blocks of the new main chain are reported again. May be called
+	// once for each synced wallet.
+	WatchedBlocksDetached func(blockHeight int32)
 }
 
 // NewSyncer creates a Syncer that will sync the wallet using SPV.
@@ -127,6 +148,8 @@ func NewSyncer(wallets map[int]*wallet.Wallet, lp *p2p.LocalPeer) *Syncer {
 		remotes:             make(map[string]*p2p.RemotePeer),
 		rescanFilter:        rescanFilter,
 		filterData:          filterData,
+		watchFilter:         wallet.NewRescanFilter(nil, nil),
+		watchFilterData:     &blockcf.Entries{},
 		seenTxs:             lru.NewCache(2000),
 		lp:                  lp,
 	}
@@ -249,6 +272,12 @@ func (s *Syncer) mempoolTxs(walletID int, txs []*wire.MsgTx) {
 	}
 }
 
+func (s *Syncer) watchedBlocksDetached(blockHeight int32) {
+	if s.notifications != nil && s.notifications.WatchedBlocksDetached != nil {
+		s.notifications.WatchedBlocksDetached(blockHeight)
+	}
+}
+
 func (s *Syncer) tipChanged(tip *wire.BlockHeader, reorgDepth int32, matchingTxs map[chainhash.Hash][]*wire.MsgTx) {
 	if s.notifications != nil && s.notifications.TipChanged != nil {
 		var txs []*wire.MsgTx
@@ -848,6 +877,10 @@ ProcessTx:
 		s.seenTxs.Add(*h)
 	}
 
+	// Report watched transactions before the transactions are filtered for
+	// each wallet.
+	s.watchedMempoolTxs(txs)
+
 	// Save any relevant transaction.
 	for walletID, w := range s.wallets {
 		relevant := s.filterRelevant(txs, walletID)
@@ -1038,6 +1071,11 @@ func (s *Syncer) handleBlockAnnouncements(ctx context.Context, rp *p2p.RemotePee
 		return err
 	}
 
+	// connected blocks are checked for watched transactions once all wallets
+	// have switched to the new chain.
+	var connected []*wallet.BlockNode
+	connectedHashes := make(map[chainhash.Hash]struct{})
+
 	for key, w := range s.wallets {
 		newBlocks := make([]*wallet.BlockNode, 0, len(headers))
 		var bestChain []*wallet.BlockNode
@@ -1095,6 +1133,7 @@ func (s *Syncer) handleBlockAnnouncements(ctx context.Context, rp *p2p.RemotePee
 				for _, n := range prevChain {
 					s.sidechains.AddBlockNode(n)
 				}
+				s.watchedBlocksDetached(int32(prevChain[0].Header.Height))
 			}
 			s.tipChanged(bestChain[len(bestChain)-1].Header, int32(len(prevChain)), matchingTxs)
 
@@ -1115,6 +1154,10 @@ func (s *Syncer) handleBlockAnnouncements(ctx context.Context, rp *p2p.RemotePee
 		for _, n := range bestChain {
 			log.Infof("[%d] Connected block %v, height %d, %d wallet transaction(s)",
 				key, n.Hash, n.Header.Height, len(matchingTxs[*n.Hash]))
+			if _, ok := connectedHashes[*n.Hash]; !ok {
+				connectedHashes[*n.Hash] = struct{}{}
+				connected = append(connected, n)
+			}
 		}
 		// Announced blocks not in the main chain are logged as sidechain or orphan
 		// blocks.
@@ -1130,7 +1173,7 @@ func (s *Syncer) handleBlockAnnouncements(ctx context.Context, rp *p2p.RemotePee
 		}
 	}
 
-	return nil
+	return s.scanWatchedBlocks(ctx, rp, connected, bmap)
 }
 
 // hashStop is a zero value stop hash for fetching all possible data using
@@ -1187,13 +1230,13 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 		lastHeight = int32(headers[len(headers)-1].Height)
 
 		nodes := make([]*wallet.BlockNode, len(headers))
-		g, ctx := errgroup.WithContext(ctx)
+		g, gctx := errgroup.WithContext(ctx)
 		for i := range headers {
 			i := i
 			g.Go(func() error {
 				header := headers[i]
 				hash := header.BlockHash()
-				filter, err := rp.CFilter(ctx, &hash)
+				filter, err := rp.CFilter(gctx, &hash)
 				if err != nil {
 					return err
 				}
@@ -1250,6 +1293,7 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 				for _, n := range prevChain {
 					s.sidechains.AddBlockNode(n)
 				}
+				s.watchedBlocksDetached(int32(prevChain[0].Header.Height))
 			}
 			tip := bestChain[len(bestChain)-1]
 			if len(bestChain) == 1 {
@@ -1263,6 +1307,11 @@ func (s *Syncer) getHeaders(ctx context.Context, rp *p2p.RemotePeer) error {
 			s.sidechainMu.Unlock()
 		}
 
+		err = s.scanWatchedBlocks(ctx, rp, nodes, nil)
+		if err != nil {
+			return err
+		}
+
 		// Generate new locators
 		s.locatorMu.Lock()
 		locators, err = lowestChainWallet.BlockLocators(ctx, nil)
@@ -1344,6 +1393,11 @@ func (s *Syncer) startupSync(ctx context.Context, rp *p2p.RemotePeer) error {
 				// check to see if it was previously synced
 				s.unsynced(walletID)
 
+				rescanPoint, err = s.birthdayRescanPoint(ctx, walletID, rescanPoint)
+				if err != nil {
+					return err
+				}
+
 				s.discoverAddressesStart(walletID)
 				err = w.DiscoverActiveAddresses(ctx, rp, rescanPoint, !w.Locked())
 				if err != nil {
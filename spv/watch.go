@@ -0,0 +1,184 @@
+// Copyright (c) 2018-2019 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package spv
+
+import (
+	"context"
+
+	"github.com/decred/dcrd/blockchain/stake/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/gcs/blockcf"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/p2p/v2"
+	"github.com/decred/dcrwallet/validate"
+	"github.com/decred/dcrwallet/wallet/v3"
+)
+
+// WatchAddresses adds addresses that are not owned by the synced wallets, and
+// the unspent outpoints paid to them, to the watch filter. Transactions that
+// pay to a watched address or spend a watched outpoint are reported through
+// the WatchedTxs notification and are not saved to any wallet. Only blocks
+// fetched after the addresses are added are checked for watched transactions.
+func (s *Syncer) WatchAddresses(addrs []dcrutil.Address, outpoints []wire.OutPoint) {
+	s.filterMu.Lock()
+	defer s.filterMu.Unlock()
+
+	s.addToWatchFilter(addrs, outpoints)
+}
+
+// SetWatchedAddresses replaces the watch filter with a filter of the provided
+// addresses and unspent outpoints, so that addresses that are no longer
+// watched and outpoints that are no longer unspent are removed from it.
+func (s *Syncer) SetWatchedAddresses(addrs []dcrutil.Address, outpoints []wire.OutPoint) {
+	s.filterMu.Lock()
+	defer s.filterMu.Unlock()
+
+	s.watchFilter = wallet.NewRescanFilter(nil, nil)
+	s.watchFilterData = &blockcf.Entries{}
+	s.addToWatchFilter(addrs, outpoints)
+}
+
+// addToWatchFilter adds addrs and outpoints to the watch filter. filterMu must
+// be held.
+func (s *Syncer) addToWatchFilter(addrs []dcrutil.Address, outpoints []wire.OutPoint) {
+	for _, addr := range addrs {
+		var pkScript []byte
+		switch addr := addr.(type) {
+		case wallet.V0Scripter:
+			pkScript = addr.ScriptV0()
+		default:
+			pkScript, _ = txscript.PayToAddrScript(addr)
+		}
+		if pkScript != nil {
+			s.watchFilter.AddAddress(addr)
+			s.watchFilterData.AddRegularPkScript(pkScript)
+		}
+	}
+	for i := range outpoints {
+		s.watchFilter.AddUnspentOutPoint(&outpoints[i])
+		s.watchFilterData.AddOutPoint(&outpoints[i])
+	}
+}
+
+// watchRelevant returns the transactions that pay to watched addresses or
+// spend watched outpoints. Outputs paid to watched addresses are added to the
+// watch filter so that their spends are also reported.
+func (s *Syncer) watchRelevant(txs []*wire.MsgTx) []*wire.MsgTx {
+	s.filterMu.Lock()
+	defer s.filterMu.Unlock()
+
+	if len(*s.watchFilterData) == 0 {
+		return nil
+	}
+
+	var matches []*wire.MsgTx
+	for _, tx := range txs {
+		relevant := false
+		for _, in := range tx.TxIn {
+			if s.watchFilter.ExistsUnspentOutPoint(&in.PreviousOutPoint) {
+				s.watchFilter.RemoveUnspentOutPoint(&in.PreviousOutPoint)
+				relevant = true
+			}
+		}
+		for i, out := range tx.TxOut {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.Version, out.PkScript, s.lp.ChainParams())
+			if err != nil {
+				continue
+			}
+			for _, a := range addrs {
+				if s.watchFilter.ExistsAddress(a) {
+					op := wire.OutPoint{
+						Hash:  tx.TxHash(),
+						Index: uint32(i),
+						Tree:  TxTree(tx),
+					}
+					s.watchFilter.AddUnspentOutPoint(&op)
+					s.watchFilterData.AddOutPoint(&op)
+					relevant = true
+				}
+			}
+		}
+		if relevant {
+			matches = append(matches, tx)
+		}
+	}
+
+	return matches
+}
+
+// scanWatchedBlocks reports the watched transactions mined in the blocks of
+// `nodes`. Blocks are read from bmap if present or fetched from rp if their
+// cfilter matches the watch filter.
+func (s *Syncer) scanWatchedBlocks(ctx context.Context, rp *p2p.RemotePeer, nodes []*wallet.BlockNode,
+	bmap map[chainhash.Hash]*wire.MsgBlock) error {
+
+	if s.notifications == nil || s.notifications.WatchedTxs == nil {
+		return nil
+	}
+
+	for _, n := range nodes {
+		s.filterMu.Lock()
+		filterData := *s.watchFilterData
+		s.filterMu.Unlock()
+
+		if len(filterData) == 0 {
+			return nil
+		}
+		if n.Filter.N() == 0 || !n.Filter.MatchAny(blockcf.Key(n.Hash), filterData) {
+			continue
+		}
+
+		b, ok := bmap[*n.Hash]
+		if !ok {
+			blocks, err := rp.Blocks(ctx, []*chainhash.Hash{n.Hash})
+			if err != nil {
+				return err
+			}
+			b = blocks[0]
+
+			err = validate.MerkleRoots(b)
+			if err != nil {
+				err = validate.DCP0005MerkleRoot(b)
+			}
+			if err == nil {
+				err = validate.RegularCFilter(b, n.Filter)
+			}
+			if err != nil {
+				rp.Disconnect(err)
+				return err
+			}
+		}
+
+		txs := append(s.watchRelevant(b.Transactions),
+			s.watchRelevant(b.STransactions)...)
+		if len(txs) != 0 {
+			s.notifications.WatchedTxs(txs, int32(n.Header.Height))
+		}
+	}
+
+	return nil
+}
+
+// watchedMempoolTxs reports the watched transactions of txs, which are unmined.
+func (s *Syncer) watchedMempoolTxs(txs []*wire.MsgTx) {
+	if s.notifications == nil || s.notifications.WatchedTxs == nil {
+		return
+	}
+
+	watched := s.watchRelevant(txs)
+	if len(watched) != 0 {
+		s.notifications.WatchedTxs(watched, -1)
+	}
+}
+
+// TxTree returns the tree of the block that tx is mined in.
+func TxTree(tx *wire.MsgTx) int8 {
+	if stake.DetermineTxType(tx) != stake.TxTypeRegular {
+		return wire.TxTreeStake
+	}
+	return wire.TxTreeRegular
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/decred/dcrd/addrmgr"
 	"github.com/decred/dcrwallet/errors/v2"
@@ -24,6 +25,7 @@ type syncData struct {
 	syncing      bool
 	cancelSync   context.CancelFunc
 	cancelRescan context.CancelFunc
+	syncer       *spv.Syncer
 	syncCanceled chan bool
 
 	// Flag to notify syncCanceled callback if the sync was canceled so as to be restarted.
@@ -205,15 +207,35 @@ func (mw *MultiWallet) SpvSync() error {
 	// to calculate sync estimates only during sync
 	mw.initActiveSyncData()
 
+	// only opened wallets can participate in the sync session,
+	// wallets that are yet to be opened will be synced on the next session.
 	wallets := make(map[int]*w.Wallet)
-	for id, wallet := range mw.wallets {
-		wallets[id] = wallet.internal
+	birthdays := make(map[int]time.Time)
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
+		wallets[wallet.ID] = wallet.internal
+		birthdays[wallet.ID] = wallet.Birthday
 		wallet.waiting = true
 		wallet.syncing = true
 	}
 
+	if len(wallets) == 0 {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
 	syncer := spv.NewSyncer(wallets, lp)
 	syncer.SetNotifications(mw.spvSyncNotificationCallbacks())
+	syncer.SetBirthdays(birthdays)
+
+	watchedAddrs, watchedOutpoints, err := mw.watchedAddressesFilter()
+	if err != nil {
+		log.Errorf("Error reading watched addresses: %v", err)
+	} else {
+		syncer.WatchAddresses(watchedAddrs, watchedOutpoints)
+	}
 	if len(validPeerAddresses) > 0 {
 		syncer.SetPersistentPeers(validPeerAddresses)
 	}
@@ -229,6 +251,7 @@ func (mw *MultiWallet) SpvSync() error {
 	mw.syncData.restartSyncRequested = false
 	mw.syncData.syncing = true
 	mw.syncData.cancelSync = cancel
+	mw.syncData.syncer = syncer
 	mw.syncData.mu.Unlock()
 
 	for _, listener := range mw.syncProgressListeners() {
@@ -255,6 +278,9 @@ func (mw *MultiWallet) SpvSync() error {
 			}
 		}
 	}()
+
+	go mw.rebroadcastUnminedTransactionsPeriodically(ctx)
+	go mw.runScheduledPaymentsPeriodically(ctx)
 	return nil
 }
 
@@ -287,7 +313,7 @@ func (mw *MultiWallet) CancelSync() {
 		log.Info("Sync fully canceled.")
 	}
 
-	for _, libWallet := range mw.wallets {
+	for _, libWallet := range mw.AllWallets() {
 		loadedWallet, walletLoaded := libWallet.loader.LoadedWallet()
 		if !walletLoaded {
 			continue
@@ -364,7 +390,7 @@ func (mw *MultiWallet) ConnectedPeers() int32 {
 func (mw *MultiWallet) GetBestBlock() *BlockInfo {
 	var bestBlock int32 = -1
 	var blockInfo *BlockInfo
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if !wallet.WalletOpened() {
 			continue
 		}
@@ -382,7 +408,7 @@ func (mw *MultiWallet) GetBestBlock() *BlockInfo {
 func (mw *MultiWallet) GetLowestBlock() *BlockInfo {
 	var lowestBlock int32 = -1
 	var blockInfo *BlockInfo
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		if !wallet.WalletOpened() {
 			continue
 		}
@@ -427,7 +453,11 @@ func (wallet *Wallet) GetBestBlockTimeStamp() int64 {
 
 func (mw *MultiWallet) GetLowestBlockTimestamp() int64 {
 	var timestamp int64 = -1
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
 		bestBlockTimestamp := wallet.GetBestBlockTimeStamp()
 		if bestBlockTimestamp < timestamp || timestamp == -1 {
 			timestamp = bestBlockTimestamp
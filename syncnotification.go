@@ -28,6 +28,8 @@ func (mw *MultiWallet) spvSyncNotificationCallbacks() *spv.Notifications {
 		RescanStarted:                mw.rescanStarted,
 		RescanProgress:               mw.rescanProgress,
 		RescanFinished:               mw.rescanFinished,
+		WatchedTxs:                   mw.watchedTxs,
+		WatchedBlocksDetached:        mw.watchedBlocksDetached,
 	}
 }
 
@@ -68,7 +70,7 @@ func (mw *MultiWallet) fetchHeadersStarted(peerInitialHeight int32) {
 		return
 	}
 
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
 		wallet.waiting = true
 	}
 
@@ -100,8 +102,8 @@ func (mw *MultiWallet) fetchHeadersProgress(lastFetchedHeaderHeight int32, lastF
 		return
 	}
 
-	for _, wallet := range mw.wallets {
-		if wallet.waiting {
+	for _, wallet := range mw.AllWallets() {
+		if wallet.WalletOpened() && wallet.waiting {
 			wallet.waiting = wallet.GetBestBlock() > lastFetchedHeaderHeight
 		}
 	}
@@ -382,7 +384,7 @@ func (mw *MultiWallet) rescanProgress(walletID int, rescannedThrough int32) {
 		return
 	}
 
-	wallet := mw.wallets[walletID]
+	wallet := mw.WalletWithID(walletID)
 	totalHeadersToScan := wallet.GetBestBlock()
 
 	rescanRate := float64(rescannedThrough) / float64(totalHeadersToScan)
@@ -508,12 +510,19 @@ func (mw *MultiWallet) resetSyncData() {
 	mw.syncData.syncing = false
 	mw.syncData.synced = false
 	mw.syncData.cancelSync = nil
+	mw.syncData.syncer = nil
 	mw.syncData.activeSyncData = nil
 	mw.syncData.mu.Unlock()
 
-	for _, wallet := range mw.wallets {
+	for _, wallet := range mw.AllWallets() {
+		if !wallet.WalletOpened() {
+			continue
+		}
+
 		wallet.waiting = true
-		wallet.LockWallet() // lock wallet if previously unlocked to perform account discovery.
+		if !wallet.HasUnlockSession() {
+			wallet.LockWallet() // lock wallet if previously unlocked to perform account discovery.
+		}
 	}
 }
 
@@ -526,11 +535,15 @@ func (mw *MultiWallet) synced(walletID int, synced bool) {
 		return
 	}
 
-	wallet := mw.wallets[walletID]
+	wallet := mw.WalletWithID(walletID)
 	wallet.synced = synced
 	wallet.syncing = false
 	if !wallet.internal.Locked() {
-		wallet.LockWallet() // lock wallet if previously unlocked to perform account discovery.
+		// lock wallet if previously unlocked to perform account discovery,
+		// wallets unlocked for an unlock session are kept unlocked.
+		if !wallet.HasUnlockSession() {
+			wallet.LockWallet()
+		}
 		err := mw.markWalletAsDiscoveredAccounts(walletID)
 		if err != nil {
 			log.Error(err)
@@ -546,8 +559,10 @@ func (mw *MultiWallet) synced(walletID int, synced bool) {
 		// begin indexing transactions after sync is completed,
 		// syncProgressListeners.OnSynced() will be invoked after transactions are indexed
 		var txIndexing errgroup.Group
-		for _, wallet := range mw.wallets {
-			txIndexing.Go(wallet.IndexTransactions)
+		for _, wallet := range mw.AllWallets() {
+			if wallet.WalletOpened() {
+				txIndexing.Go(wallet.IndexTransactions)
+			}
 		}
 
 		go func() {
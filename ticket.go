@@ -178,6 +178,10 @@ func (wallet *Wallet) TicketPrice(ctx context.Context) (*TicketPriceResponse, er
 
 // PurchaseTickets purchases tickets from the wallet. Returns a slice of hashes for tickets purchased
 func (wallet *Wallet) PurchaseTickets(ctx context.Context, request *PurchaseTicketsRequest, vspHost string) ([]string, error) {
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
 	var err error
 
 	// fetch redeem script, ticket address, pool address and pool fee if vsp host isn't empty
@@ -187,6 +191,10 @@ func (wallet *Wallet) PurchaseTickets(ctx context.Context, request *PurchaseTick
 		}
 	}
 
+	if request.UseDefaultAccount {
+		request.Account = uint32(wallet.DefaultAccount())
+	}
+
 	minConf := int32(request.RequiredConfirmations)
 	params := wallet.chainParams
 
@@ -240,25 +248,16 @@ func (wallet *Wallet) PurchaseTickets(ctx context.Context, request *PurchaseTick
 	defer func() {
 		lock <- time.Time{} // send matters, not the value
 	}()
-	err = wallet.internal.Unlock(ctx, request.Passphrase, lock)
-	if err != nil {
-		return nil, translateError(err)
-	}
-
-	purchaseTicketsRequest := &w.PurchaseTicketsRequest{
-		Count:         numTickets,
-		SourceAccount: request.Account,
-		VotingAddress: ticketAddr,
-		MinConf:       minConf,
-		Expiry:        expiry,
-	}
-
-	netBackend, err := wallet.internal.NetworkBackend()
+	err = wallet.unlock(ctx, request.Passphrase, lock)
 	if err != nil {
 		return nil, err
 	}
 
-	purchasedTickets, err := wallet.internal.PurchaseTicketsContext(ctx, netBackend, purchaseTicketsRequest)
+	// the fees are passed with the purchase rather than set on the wallet,
+	// so concurrent transactions keep paying the wallet's fees. A zero txFee
+	// pays the wallet's relay fee.
+	purchasedTickets, err := wallet.internal.PurchaseTickets(ctx, 0, 0, minConf, ticketAddr, request.Account,
+		numTickets, poolAddr, request.PoolFees, expiry, txFee, ticketFee)
 	if err != nil {
 		return nil, fmt.Errorf("unable to purchase tickets: %s", err.Error())
 	}
@@ -271,6 +270,105 @@ func (wallet *Wallet) PurchaseTickets(ctx context.Context, request *PurchaseTick
 	return hashes, nil
 }
 
+// estimatedTicketSize is the approximate size of a ticket purchase and of its
+// output of the split transaction that funds it, used to estimate the fees of
+// a ticket before purchasing.
+const estimatedTicketSize = 300 + 40
+
+// estimatedTicketFee returns the approximate fee of a ticket purchase paying
+// `feeRate` atoms/kB.
+func estimatedTicketFee(feeRate int64) int64 {
+	return int64(txrules.FeeForSerializeSize(dcrutil.Amount(feeRate), estimatedTicketSize))
+}
+
+// PurchaseTicketsForAccount purchases `numTickets` tickets funded by the
+// spendable outputs of `account`, paying `feeRate` atoms/kB or the default
+// relay fee rate if 0. Tickets not mined by the block height `expiry` expire,
+// 0 for no expiry. Returns the json-encoded hashes of the purchased tickets.
+// Returns `ErrInsufficientBalance` if the spendable balance of the account,
+// which excludes immature and unconfirmed funds, cannot pay for the tickets.
+func (wallet *Wallet) PurchaseTicketsForAccount(account, numTickets, expiry int32, feeRate int64,
+	privPass []byte) (string, error) {
+
+	hashes, err := wallet.PurchaseTicketsForAccountRaw(account, numTickets, expiry, feeRate, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedHashes, err := json.Marshal(hashes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedHashes), nil
+}
+
+func (wallet *Wallet) PurchaseTicketsForAccountRaw(account, numTickets, expiry int32, feeRate int64,
+	privPass []byte) ([]string, error) {
+
+	return wallet.purchaseTickets(account, numTickets, expiry, feeRate, privPass)
+}
+
+// purchaseTickets purchases tickets as described in `PurchaseTicketsForAccount`.
+func (wallet *Wallet) purchaseTickets(account, numTickets, expiry int32, feeRate int64,
+	privPass []byte) ([]string, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+	if account < 0 || numTickets < 1 {
+		return nil, errors.New(ErrInvalid)
+	}
+	if expiry != 0 && expiry <= wallet.GetBestBlock() {
+		return nil, errors.New(ErrInvalid)
+	}
+	if feeRate == 0 {
+		feeRate = MinFeeRate
+	}
+	if feeRate < MinFeeRate || feeRate > MaxFeeRate {
+		return nil, errors.New(ErrInvalidAmount)
+	}
+
+	ctx := wallet.shutdownContext()
+	ticketPrice, err := wallet.TicketPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// tickets are funded with spendable outputs only, immature rewards and
+	// unconfirmed outputs must mature first.
+	requiredConfirmations := wallet.RequiredConfirmations()
+	if requiredConfirmations < 1 {
+		requiredConfirmations = 1
+	}
+	balance, err := wallet.GetAccountBalanceWithConfirmations(account, requiredConfirmations)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketsCost := int64(numTickets) * (ticketPrice.TicketPrice + estimatedTicketFee(feeRate))
+	if balance.Spendable < ticketsCost {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	// the split transaction and the tickets both pay `feeRate`
+	return wallet.PurchaseTickets(ctx, &PurchaseTicketsRequest{
+		Account:               uint32(account),
+		RequiredConfirmations: uint32(requiredConfirmations),
+		NumTickets:            uint32(numTickets),
+		Passphrase:            privPass,
+		Expiry:                uint32(expiry),
+		TxFee:                 feeRate,
+		TicketFee:             feeRate,
+	}, "")
+}
+
 func (wallet *Wallet) updateTicketPurchaseRequestWithVSPInfo(vspHost string, request *PurchaseTicketsRequest) error {
 	// generate an address and get the pubkeyaddr
 	address, err := wallet.CurrentAddress(0)
@@ -298,7 +396,10 @@ func (wallet *Wallet) updateTicketPurchaseRequestWithVSPInfo(vspHost string, req
 
 	// unlock wallet and import the decoded script
 	lock := make(chan time.Time, 1)
-	wallet.internal.Unlock(ctx, request.Passphrase, lock)
+	err = wallet.unlock(ctx, request.Passphrase, lock)
+	if err != nil {
+		return err
+	}
 	err = wallet.internal.ImportScript(ctx, rs)
 	lock <- time.Time{}
 	if err != nil && !errors.Is(errors.Exist, err) {
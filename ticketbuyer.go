@@ -0,0 +1,241 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// ticketBuyerExpiry is the number of blocks after which tickets purchased by
+// the ticket buyer expire if they are not mined, so that tickets purchased
+// at an outdated price do not stay unmined.
+const ticketBuyerExpiry = 16
+
+// TicketBuyerConfig is the policy of the automatic ticket buyer of a wallet.
+// Tickets are purchased from Account with the spendable balance above
+// BalanceToMaintain, in atoms, while the ticket price is at most MaxPrice,
+// or at any price if MaxPrice is 0. Purchased tickets are registered with
+// the vspd VSP at VSPHost, if set, and the VSP fees are paid from Account.
+// FeeRate is in atoms/kB, the default relay fee rate is used if 0.
+type TicketBuyerConfig struct {
+	Account           int32  `json:"account"`
+	BalanceToMaintain int64  `json:"balance_to_maintain"`
+	MaxPrice          int64  `json:"max_price"`
+	VSPHost           string `json:"vsp_host"`
+	FeeRate           int64  `json:"fee_rate"`
+}
+
+// TicketBuyerListener is notified of the tickets purchased by the ticket
+// buyers of the wallets and of failed purchases. `ticketHashes` is the
+// json-encoded list of the hashes of the purchased tickets.
+// OnTicketPurchaseFailed is also called if a purchased ticket could not be
+// registered with the VSP, the ticket can then be registered again with
+// `RegisterTicketWithVSP`.
+type TicketBuyerListener interface {
+	OnTicketsPurchased(walletID int, ticketHashes string)
+	OnTicketPurchaseFailed(walletID int, err string)
+}
+
+// ticketBuyer is the running ticket buyer of a wallet.
+type ticketBuyer struct {
+	config *TicketBuyerConfig
+
+	// buying is true while tickets are being purchased, so that blocks
+	// attached meanwhile do not start another purchase with the same funds.
+	buying bool
+}
+
+// StartTicketBuyer starts purchasing tickets for the specified wallet with the
+// json-encoded `TicketBuyerConfig` policy whenever a block is attached and
+// funds are available, until `StopTicketBuyer` is called or the app is
+// closed. Tickets are only purchased while the wallet has an unlock session
+// or a key source is set, since purchases must be signed.
+func (mw *MultiWallet) StartTicketBuyer(walletID int, jsonEncodedConfig string) error {
+	config := &TicketBuyerConfig{}
+	err := json.Unmarshal([]byte(jsonEncodedConfig), config)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return mw.StartTicketBuyerRaw(walletID, config)
+}
+
+func (mw *MultiWallet) StartTicketBuyerRaw(walletID int, config *TicketBuyerConfig) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+	if wallet.IsWatchingOnlyWallet() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
+	if config.Account < 0 || config.BalanceToMaintain < 0 || config.MaxPrice < 0 {
+		return errors.New(ErrInvalid)
+	}
+	if config.FeeRate != 0 && (config.FeeRate < MinFeeRate || config.FeeRate > MaxFeeRate) {
+		return errors.New(ErrInvalidAmount)
+	}
+
+	if _, err := wallet.GetAccountBalanceWithConfirmations(config.Account, 0); err != nil {
+		return errors.New(ErrNotExist)
+	}
+
+	mw.ticketBuyersMu.Lock()
+	defer mw.ticketBuyersMu.Unlock()
+
+	if _, running := mw.ticketBuyers[walletID]; running {
+		return errors.New(ErrExist)
+	}
+	mw.ticketBuyers[walletID] = &ticketBuyer{config: config}
+
+	log.Infof("[%d] Ticket buyer started for account %d", walletID, config.Account)
+	return nil
+}
+
+// StopTicketBuyer stops the ticket buyer of the specified wallet. A purchase
+// in progress is completed.
+func (mw *MultiWallet) StopTicketBuyer(walletID int) {
+	mw.ticketBuyersMu.Lock()
+	defer mw.ticketBuyersMu.Unlock()
+
+	if _, running := mw.ticketBuyers[walletID]; running {
+		delete(mw.ticketBuyers, walletID)
+		log.Infof("[%d] Ticket buyer stopped", walletID)
+	}
+}
+
+// IsTicketBuyerRunning returns true if the ticket buyer of the specified
+// wallet is running.
+func (mw *MultiWallet) IsTicketBuyerRunning(walletID int) bool {
+	mw.ticketBuyersMu.Lock()
+	defer mw.ticketBuyersMu.Unlock()
+
+	_, running := mw.ticketBuyers[walletID]
+	return running
+}
+
+func (mw *MultiWallet) SetTicketBuyerListener(ticketBuyerListener TicketBuyerListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.ticketBuyerListener = ticketBuyerListener
+}
+
+func (mw *MultiWallet) getTicketBuyerListener() TicketBuyerListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.ticketBuyerListener
+}
+
+// runTicketBuyer purchases as many tickets as the policy of the running
+// ticket buyer of the wallet allows, if any. Called when a block is attached,
+// as new blocks mature funds and change the ticket price.
+func (mw *MultiWallet) runTicketBuyer(wallet *Wallet) {
+	mw.ticketBuyersMu.Lock()
+	buyer, running := mw.ticketBuyers[wallet.ID]
+	if !running || buyer.buying {
+		mw.ticketBuyersMu.Unlock()
+		return
+	}
+	buyer.buying = true
+	mw.ticketBuyersMu.Unlock()
+
+	defer func() {
+		mw.ticketBuyersMu.Lock()
+		buyer.buying = false
+		mw.ticketBuyersMu.Unlock()
+	}()
+
+	if !mw.IsSynced() || !wallet.WalletOpened() || !wallet.IsSynced() {
+		return
+	}
+
+	mw.notificationListenersMu.RLock()
+	hasKeySource := mw.keySource != nil
+	mw.notificationListenersMu.RUnlock()
+
+	// purchases wait until the wallet can sign them
+	if !wallet.HasUnlockSession() && !hasKeySource {
+		return
+	}
+
+	numTickets, err := mw.ticketsToBuy(wallet, buyer.config)
+	if err != nil {
+		log.Errorf("[%d] Ticket buyer error: %v", wallet.ID, err)
+		return
+	}
+	if numTickets < 1 {
+		return
+	}
+
+	config := buyer.config
+	expiry := wallet.GetBestBlock() + ticketBuyerExpiry
+	hashes, err := wallet.purchaseTickets(config.Account, numTickets, expiry, config.FeeRate, nil)
+	listener := mw.getTicketBuyerListener()
+	if err != nil {
+		log.Errorf("[%d] Ticket buyer could not purchase %d tickets: %v", wallet.ID, numTickets, err)
+		if listener != nil {
+			listener.OnTicketPurchaseFailed(wallet.ID, err.Error())
+		}
+		return
+	}
+
+	log.Infof("[%d] Ticket buyer purchased %d tickets", wallet.ID, len(hashes))
+	if listener != nil {
+		jsonEncodedHashes, err := json.Marshal(hashes)
+		if err != nil {
+			log.Error(err)
+		} else {
+			listener.OnTicketsPurchased(wallet.ID, string(jsonEncodedHashes))
+		}
+	}
+
+	if config.VSPHost == "" {
+		return
+	}
+
+	for _, hash := range hashes {
+		_, err = mw.RegisterTicketWithVSPRaw(wallet.ID, hash, config.VSPHost, config.Account, nil)
+		if err != nil {
+			log.Errorf("[%d] Ticket buyer could not register ticket %s with VSP %s: %v", wallet.ID, hash,
+				config.VSPHost, err)
+			if listener != nil {
+				listener.OnTicketPurchaseFailed(wallet.ID, err.Error())
+			}
+		}
+	}
+}
+
+// ticketsToBuy returns the number of tickets that the spendable balance above
+// the balance to maintain can purchase at the current price, 0 if the price
+// is above the maximum price.
+func (mw *MultiWallet) ticketsToBuy(wallet *Wallet, config *TicketBuyerConfig) (int32, error) {
+	ticketPrice, err := wallet.TicketPrice(wallet.shutdownContext())
+	if err != nil {
+		return 0, err
+	}
+	if config.MaxPrice > 0 && ticketPrice.TicketPrice > config.MaxPrice {
+		return 0, nil
+	}
+
+	requiredConfirmations := wallet.RequiredConfirmations()
+	if requiredConfirmations < 1 {
+		requiredConfirmations = 1
+	}
+	balance, err := wallet.GetAccountBalanceWithConfirmations(config.Account, requiredConfirmations)
+	if err != nil {
+		return 0, err
+	}
+
+	feeRate := config.FeeRate
+	if feeRate == 0 {
+		feeRate = MinFeeRate
+	}
+
+	available := balance.Spendable - config.BalanceToMaintain
+	if available <= 0 {
+		return 0, nil
+	}
+	return int32(available / (ticketPrice.TicketPrice + estimatedTicketFee(feeRate))), nil
+}
@@ -0,0 +1,271 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/decred/dcrwallet/rpc/client/dcrd"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// Ticket is a ticket of a wallet. Status is one of the statuses returned by
+// `ticketStatusString`, such as "LIVE" or "VOTED". PurchasePrice and Fee are
+// the ticket price and the fee paid to purchase the ticket, in atoms. Reward
+// is the amount, in atoms, that the vote returned on top of the ticket price,
+// 0 if the ticket did not vote. BlockHeight is the height the ticket was
+// mined at, or -1 if unmined, and MaturityHeight and ExpiryHeight are the
+// heights the ticket becomes live and expires at. SpenderHash and
+// SpenderHeight identify the vote or revocation of the ticket, if any.
+// VSPHost is the VSP the ticket was registered with, if any.
+type Ticket struct {
+	Hash           string `json:"hash"`
+	Status         string `json:"status"`
+	PurchasePrice  int64  `json:"purchase_price"`
+	Fee            int64  `json:"fee"`
+	Timestamp      int64  `json:"timestamp"`
+	BlockHeight    int32  `json:"block_height"`
+	MaturityHeight int32  `json:"maturity_height"`
+	ExpiryHeight   int32  `json:"expiry_height"`
+	SpenderHash    string `json:"spender_hash"`
+	SpenderHeight  int32  `json:"spender_height"`
+	Reward         int64  `json:"reward"`
+	VSPHost        string `json:"vsp_host"`
+}
+
+// TicketsPage is a page of the tickets of a wallet. The tickets are not
+// counted, as reading a page stops once the tickets of the page are read.
+type TicketsPage struct {
+	Tickets []*Ticket `json:"tickets"`
+	Offset  int32     `json:"offset"`
+	Limit   int32     `json:"limit"`
+	HasMore bool      `json:"has_more"`
+}
+
+// TicketFilter selects the tickets returned by `GetTickets`. Statuses are the
+// statuses returned by `ticketStatusString`, matched case-insensitively. An
+// empty list does not filter.
+type TicketFilter struct {
+	Statuses []string `json:"statuses"`
+}
+
+// GetTickets returns the json-encoded `TicketsPage` of up to `limit` tickets
+// of the specified wallet selected by the json-encoded `TicketFilter`,
+// starting at `offset`, ordered by the height of the block the tickets were
+// mined in, with unmined tickets last, or first if `newestFirst` is true. All
+// tickets from `offset` are returned if `limit` is 0.
+func (mw *MultiWallet) GetTickets(walletID int, offset, limit int32, jsonEncodedFilter string,
+	newestFirst bool) (string, error) {
+
+	filter := &TicketFilter{}
+	if jsonEncodedFilter != "" {
+		err := json.Unmarshal([]byte(jsonEncodedFilter), filter)
+		if err != nil {
+			return "", errors.New(ErrInvalid)
+		}
+	}
+
+	page, err := mw.GetTicketsRaw(walletID, offset, limit, filter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPage, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPage), nil
+}
+
+func (mw *MultiWallet) GetTicketsRaw(walletID int, offset, limit int32, filter *TicketFilter,
+	newestFirst bool) (*TicketsPage, error) {
+
+	if offset < 0 || limit < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	statuses := make(map[string]bool, len(filter.Statuses))
+	for _, status := range filter.Statuses {
+		statuses[strings.ToUpper(status)] = true
+	}
+
+	// one more ticket than the page is read to know if there are more
+	var maxCount int
+	if limit > 0 {
+		maxCount = int(offset) + int(limit) + 1
+	}
+
+	tickets, err := wallet.allTickets(statuses, newestFirst, maxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(offset) >= len(tickets) {
+		tickets = tickets[:0]
+	} else {
+		tickets = tickets[offset:]
+	}
+
+	hasMore := false
+	if limit > 0 && len(tickets) > int(limit) {
+		tickets = tickets[:limit]
+		hasMore = true
+	}
+
+	mw.setTicketVSPHosts(walletID, tickets)
+
+	return &TicketsPage{
+		Tickets: tickets,
+		Offset:  offset,
+		Limit:   limit,
+		HasMore: hasMore,
+	}, nil
+}
+
+// setTicketVSPHosts sets the VSPHost of the `tickets` of the specified wallet
+// that were registered with a VSP.
+func (mw *MultiWallet) setTicketVSPHosts(walletID int, tickets []*Ticket) {
+	if len(tickets) == 0 {
+		return
+	}
+
+	ticketsByHash := make(map[string]*Ticket, len(tickets))
+	ticketHashes := make([]string, len(tickets))
+	for i, ticket := range tickets {
+		ticketsByHash[ticket.Hash] = ticket
+		ticketHashes[i] = ticket.Hash
+	}
+
+	var vspTickets []*VSPTicket
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.In("TicketHash", ticketHashes)).Find(&vspTickets)
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("[%d] Error reading VSPs of tickets: %v", walletID, err)
+		return
+	}
+
+	for _, vspTicket := range vspTickets {
+		if ticket, ok := ticketsByHash[vspTicket.TicketHash]; ok {
+			ticket.VSPHost = vspTicket.VSPHost
+		}
+	}
+}
+
+// allTickets returns the tickets of this wallet with one of `statuses`, or
+// all tickets if `statuses` is empty, ordered by the height of the block the
+// tickets were mined in, with unmined tickets last, or first if `newestFirst`
+// is true. Reading stops once `maxCount` tickets are read, if not 0.
+func (wallet *Wallet) allTickets(statuses map[string]bool, newestFirst bool, maxCount int) ([]*Ticket, error) {
+	tickets := make([]*Ticket, 0)
+	rangeFn := func(ticketSummaries []*w.TicketSummary, block *wire.BlockHeader) (bool, error) {
+		for _, t := range ticketSummaries {
+			status := ticketStatusString(t.Status)
+			if len(statuses) > 0 && !statuses[status] {
+				continue
+			}
+
+			var blockHeight int32 = BlockHeightInvalid
+			if block != nil {
+				blockHeight = int32(block.Height)
+			}
+
+			ticket, err := wallet.newTicket(t, status, blockHeight)
+			if err != nil {
+				return false, err
+			}
+			tickets = append(tickets, ticket)
+			if maxCount > 0 && len(tickets) >= maxCount {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// blocks are read in reverse, after the unmined tickets, if the start
+	// block is higher than the end block
+	var startBlock, endBlock *w.BlockIdentifier
+	if newestFirst {
+		startBlock = w.NewBlockIdentifierFromHeight(-1)
+		endBlock = w.NewBlockIdentifierFromHeight(0)
+	}
+
+	// see getTickets
+	var rpc *dcrd.RPC
+	if n, err := wallet.internal.NetworkBackend(); err == nil {
+		if client, ok := n.(*dcrd.RPC); ok {
+			rpc = client
+		}
+	}
+
+	var err error
+	ctx := wallet.shutdownContext()
+	if rpc != nil {
+		err = wallet.internal.GetTicketsPrecise(ctx, rpc, rangeFn, startBlock, endBlock)
+	} else {
+		err = wallet.internal.GetTickets(ctx, rangeFn, startBlock, endBlock)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return tickets, nil
+}
+
+// newTicket returns the `Ticket` of the ticket summary `t`, which is mined at
+// `blockHeight`. The summary is read immediately, as it may be reused once
+// the range function it was passed to returns.
+func (wallet *Wallet) newTicket(t *w.TicketSummary, status string, blockHeight int32) (*Ticket, error) {
+	var ticketTx wire.MsgTx
+	err := ticketTx.Deserialize(bytes.NewReader(t.Ticket.Transaction))
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &Ticket{
+		Hash:          t.Ticket.Hash.String(),
+		Status:        status,
+		PurchasePrice: ticketTx.TxOut[0].Value,
+		Fee:           int64(t.Ticket.Fee),
+		Timestamp:     t.Ticket.Timestamp,
+		BlockHeight:   blockHeight,
+		SpenderHeight: BlockHeightInvalid,
+	}
+
+	if blockHeight != BlockHeightInvalid {
+		ticket.MaturityHeight = blockHeight + int32(wallet.chainParams.TicketMaturity)
+		ticket.ExpiryHeight = ticket.MaturityHeight + int32(wallet.chainParams.TicketExpiry)
+	}
+
+	if t.Spender == nil {
+		return ticket, nil
+	}
+
+	ticket.SpenderHash = t.Spender.Hash.String()
+	var spenders []Transaction
+	err = wallet.txDB.ReadMatching(0, 1, []q.Matcher{q.Eq("Hash", ticket.SpenderHash)}, false, &spenders)
+	if err == nil && len(spenders) > 0 {
+		ticket.SpenderHeight = spenders[0].BlockHeight
+	}
+
+	if t.Status == w.TicketStatusVoted {
+		// the reward is what the vote returns to the wallet on top of the
+		// ticket price
+		for _, output := range t.Spender.MyOutputs {
+			ticket.Reward += int64(output.Amount)
+		}
+		for _, input := range t.Spender.MyInputs {
+			ticket.Reward -= int64(input.PreviousAmount)
+		}
+	}
+
+	return ticket, nil
+}
@@ -0,0 +1,171 @@
+package dcrlibwallet
+
+import (
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// TicketListener is notified of the changes of status of the tickets of the
+// wallets. OnTicketMatured is called when a ticket reaches the ticket maturity
+// and is added to the live ticket pool, OnTicketLive when it can be selected
+// to vote, from the next block. `reward` is the amount, in atoms, that the
+// vote returned on top of the ticket price. Votes are not announced for the
+// tickets that the network selected, so OnTicketMissed is called when a ticket
+// that missed its vote is revoked before it expired, just before
+// OnTicketRevoked.
+type TicketListener interface {
+	OnTicketMatured(walletID int, ticketHash string)
+	OnTicketLive(walletID int, ticketHash string)
+	OnTicketVoted(walletID int, ticketHash string, reward int64)
+	OnTicketMissed(walletID int, ticketHash string)
+	OnTicketExpired(walletID int, ticketHash string)
+	OnTicketRevoked(walletID int, ticketHash string)
+}
+
+// ticketState is the last known state of an unspent ticket of a wallet.
+type ticketState struct {
+	// blockHeight is the height the ticket was mined at, BlockHeightInvalid
+	// if the ticket is unmined.
+	blockHeight int32
+
+	matured bool
+	live    bool
+	expired bool
+}
+
+func (mw *MultiWallet) SetTicketListener(ticketListener TicketListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.ticketListener = ticketListener
+}
+
+func (mw *MultiWallet) getTicketListener() TicketListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.ticketListener
+}
+
+// updateTicketStates notifies the ticket listener of the changes of status of
+// the tickets of the wallet caused by the block at `blockHeight`, whose
+// transactions of the wallet are `minedTxs`. Tickets are purchased, voted and
+// revoked by transactions of the wallet, while they mature, become live and
+// expire at heights known from the height they were mined at, so only the
+// states of the unspent tickets are kept. The unspent tickets are read once
+// the wallet is synced, changes until then are not notified.
+func (mw *MultiWallet) updateTicketStates(wallet *Wallet, minedTxs []*Transaction, blockHeight int32) {
+	wallet.ticketStatesMu.Lock()
+	defer wallet.ticketStatesMu.Unlock()
+
+	listener := mw.getTicketListener()
+	if listener == nil || !wallet.IsSynced() {
+		wallet.ticketStates = nil
+		return
+	}
+
+	if wallet.ticketStates == nil {
+		states, err := wallet.unspentTicketStates()
+		if err != nil {
+			log.Errorf("[%d] Error reading tickets: %v", wallet.ID, err)
+			return
+		}
+		wallet.ticketStates = states
+		return
+	}
+
+	for _, tx := range minedTxs {
+		switch tx.Type {
+		case txhelper.TxTypeTicketPurchase:
+			wallet.ticketStates[tx.Hash] = &ticketState{blockHeight: blockHeight}
+
+		case txhelper.TxTypeVote:
+			// the first input of a vote is the stakebase, the second spends
+			// the ticket
+			if len(tx.Inputs) < 2 {
+				continue
+			}
+			ticketHash := tx.Inputs[1].PreviousTransactionHash
+			delete(wallet.ticketStates, ticketHash)
+			listener.OnTicketVoted(wallet.ID, ticketHash, voteReward(tx))
+
+		case txhelper.TxTypeRevocation:
+			if len(tx.Inputs) < 1 {
+				continue
+			}
+			ticketHash := tx.Inputs[0].PreviousTransactionHash
+			if state, ok := wallet.ticketStates[ticketHash]; ok && !state.expired {
+				listener.OnTicketMissed(wallet.ID, ticketHash)
+			}
+			delete(wallet.ticketStates, ticketHash)
+			listener.OnTicketRevoked(wallet.ID, ticketHash)
+		}
+	}
+
+	ticketMaturity := int32(wallet.chainParams.TicketMaturity)
+	ticketExpiry := int32(wallet.chainParams.TicketExpiry)
+	for ticketHash, state := range wallet.ticketStates {
+		if state.blockHeight == BlockHeightInvalid {
+			continue
+		}
+
+		// see newTicket
+		maturityHeight := state.blockHeight + ticketMaturity
+		if !state.matured && blockHeight >= maturityHeight {
+			state.matured = true
+			listener.OnTicketMatured(wallet.ID, ticketHash)
+		}
+		if !state.live && blockHeight > maturityHeight {
+			state.live = true
+			listener.OnTicketLive(wallet.ID, ticketHash)
+		}
+		if !state.expired && blockHeight >= maturityHeight+ticketExpiry {
+			state.expired = true
+			listener.OnTicketExpired(wallet.ID, ticketHash)
+		}
+	}
+}
+
+// unspentTicketStates returns the states of the tickets of the wallet that
+// have not voted or been revoked, by hash.
+func (wallet *Wallet) unspentTicketStates() (map[string]*ticketState, error) {
+	tickets, err := wallet.allTickets(map[string]bool{
+		"UNMINED":  true,
+		"IMMATURE": true,
+		"LIVE":     true,
+		"MISSED":   true,
+		"EXPIRED":  true,
+	}, false, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]*ticketState, len(tickets))
+	for _, ticket := range tickets {
+		live := ticket.Status != "UNMINED" && ticket.Status != "IMMATURE"
+		states[ticket.Hash] = &ticketState{
+			blockHeight: ticket.BlockHeight,
+			matured:     live,
+			live:        live,
+			expired:     ticket.Status == "EXPIRED",
+		}
+	}
+
+	return states, nil
+}
+
+// voteReward returns the amount that the vote `tx` returned to the wallet on
+// top of the ticket price.
+func voteReward(tx *Transaction) int64 {
+	var reward int64
+	for _, output := range tx.Outputs {
+		if output.IsMine {
+			reward += output.Amount
+		}
+	}
+	for _, input := range tx.Inputs {
+		if input.IsMine {
+			reward -= input.Amount
+		}
+	}
+	return reward
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/errors/v2"
 	"github.com/raedahgroup/dcrlibwallet/txhelper"
 	"github.com/raedahgroup/dcrlibwallet/txindex"
 )
@@ -19,6 +20,9 @@ const (
 	TxFilterStaking     = txindex.TxFilterStaking
 	TxFilterCoinBase    = txindex.TxFilterCoinBase
 	TxFilterRegular     = txindex.TxFilterRegular
+	TxFilterTickets     = txindex.TxFilterTickets
+	TxFilterVotes       = txindex.TxFilterVotes
+	TxFilterRevocations = txindex.TxFilterRevocations
 
 	TxDirectionInvalid     = txhelper.TxDirectionInvalid
 	TxDirectionSent        = txhelper.TxDirectionSent
@@ -32,6 +36,35 @@ const (
 	TxTypeRevocation     = txhelper.TxTypeRevocation
 )
 
+// Classifications of a transaction, see `Transaction.Classification`.
+const (
+	// TxClassificationSent is a transaction funded by the wallet that pays
+	// to external addresses.
+	TxClassificationSent = "sent"
+
+	// TxClassificationReceived is a transaction that increases the wallet
+	// balance without spending wallet outputs, or a coinbase or vote
+	// transaction that pays only to the wallet.
+	TxClassificationReceived = "received"
+
+	// TxClassificationSelf is a transfer between wallet accounts or
+	// addresses in which the wallet only pays the fee.
+	TxClassificationSelf = "self"
+
+	// TxClassificationMixed is a transaction with both wallet and external
+	// inputs, such as a mixing or atomic swap transaction.
+	TxClassificationMixed = "mixed"
+)
+
+// Statuses of a transaction, see `Transaction.Status`. A conflicted
+// transaction can never be mined because an input it spends was spent by
+// another mined transaction.
+const (
+	TxStatusPending    = "pending"
+	TxStatusConfirmed  = "confirmed"
+	TxStatusConflicted = "conflicted"
+)
+
 func (wallet *Wallet) GetTransaction(txHash []byte) (string, error) {
 	transaction, err := wallet.GetTransactionRaw(txHash)
 	if err != nil {
@@ -63,6 +96,70 @@ func (wallet *Wallet) GetTransactionRaw(txHash []byte) (*Transaction, error) {
 	return wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
 }
 
+// GetTransactionDetail returns the json-encoded `TransactionDetail` of the
+// transaction with the hex-encoded hash `txHash`, read from the wallet rather
+// than the transaction index so that it is current.
+func (wallet *Wallet) GetTransactionDetail(txHash string) (string, error) {
+	detail, err := wallet.GetTransactionDetailRaw(txHash)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedDetail, err := json.Marshal(detail)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedDetail), nil
+}
+
+func (wallet *Wallet) GetTransactionDetailRaw(txHash string) (*TransactionDetail, error) {
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	txSummary, _, blockHash, err := wallet.internal.TransactionSummary(wallet.shutdownContext(), hash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	transaction, err := wallet.decodeTransactionWithTxSummary(txSummary, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &TransactionDetail{Transaction: *transaction}
+	if transaction.BlockHeight != BlockHeightInvalid && blockHash != nil {
+		detail.BlockHash = blockHash.String()
+	}
+
+	return detail, nil
+}
+
+// setConfirmations sets the confirmations, maturity and status of `tx` at the
+// height `bestBlock`.
+func (wallet *Wallet) setConfirmations(tx *Transaction, bestBlock int32) {
+	tx.Confirmations, tx.IsMature, tx.MaturityHeight = 0, false, 0
+	if tx.BlockHeight == BlockHeightInvalid {
+		tx.Status = TxStatusPending
+		if tx.ConflictingTxHash != "" {
+			tx.Status = TxStatusConflicted
+		}
+		return
+	}
+
+	tx.Status = TxStatusConfirmed
+
+	tx.Confirmations = bestBlock - tx.BlockHeight + 1
+	tx.MaturityHeight = tx.BlockHeight
+	if tx.Type != txhelper.TxTypeRegular {
+		// see unspentOutput
+		tx.MaturityHeight += int32(wallet.chainParams.CoinbaseMaturity) - 1
+	}
+	tx.IsMature = bestBlock >= tx.MaturityHeight
+}
+
 func (wallet *Wallet) GetTransactions(offset, limit, txFilter int32, newestFirst bool) (string, error) {
 	transactions, err := wallet.GetTransactionsRaw(offset, limit, txFilter, newestFirst)
 	if err != nil {
@@ -79,44 +176,294 @@ func (wallet *Wallet) GetTransactions(offset, limit, txFilter int32, newestFirst
 
 func (wallet *Wallet) GetTransactionsRaw(offset, limit, txFilter int32, newestFirst bool) (transactions []Transaction, err error) {
 	err = wallet.txDB.Read(offset, limit, txFilter, newestFirst, &transactions)
+	if err != nil {
+		return
+	}
+
+	// contact names, atomic swap actions, notes, tags, exchange rates and
+	// confirmations are set when transactions are read because they may have
+	// changed since the transactions were indexed.
+	bestBlock := wallet.GetBestBlock()
+	swapActions := wallet.atomicSwapActions()
+	for i := range transactions {
+		wallet.setContactNames(&transactions[i])
+		wallet.setAtomicSwapAction(&transactions[i], swapActions)
+		wallet.setTxNote(&transactions[i])
+		wallet.setTxTags(&transactions[i])
+		wallet.setTxFiatRate(&transactions[i])
+		wallet.setConfirmations(&transactions[i], bestBlock)
+	}
 	return
 }
 
 func (mw *MultiWallet) GetTransactions(offset, limit, txFilter int32, newestFirst bool) (string, error) {
+	page, err := mw.GetTransactionsPageRaw(offset, limit, txFilter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTransactions, err := json.Marshal(&page.Transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTransactions), nil
+}
+
+// GetTransactionsPage returns the json-encoded `TransactionsPage` of up to
+// `limit` transactions of this wallet that match `txFilter`, starting at
+// `offset`, along with the number of matching transactions for paging UIs.
+// All transactions from `offset` are returned if `limit` is 0.
+func (wallet *Wallet) GetTransactionsPage(offset, limit, txFilter int32, newestFirst bool) (string, error) {
+	page, err := wallet.GetTransactionsPageRaw(offset, limit, txFilter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPage, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPage), nil
+}
+
+func (wallet *Wallet) GetTransactionsPageRaw(offset, limit, txFilter int32,
+	newestFirst bool) (*TransactionsPage, error) {
+
+	if offset < 0 || limit < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	totalCount, err := wallet.CountTransactions(txFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := wallet.GetTransactionsRaw(offset, limit, txFilter, newestFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransactionsPage(transactions, offset, limit, totalCount), nil
+}
+
+// GetTransactionsPage returns the json-encoded `TransactionsPage` of up to
+// `limit` transactions of all wallets that match `txFilter`, starting at
+// `offset` of the transactions of all wallets ordered by time.
+func (mw *MultiWallet) GetTransactionsPage(offset, limit, txFilter int32, newestFirst bool) (string, error) {
+	page, err := mw.GetTransactionsPageRaw(offset, limit, txFilter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPage, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPage), nil
+}
+
+func (mw *MultiWallet) GetTransactionsPageRaw(offset, limit, txFilter int32,
+	newestFirst bool) (*TransactionsPage, error) {
+
+	if offset < 0 || limit < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	// the page may hold transactions from any position up to offset+limit of
+	// each wallet's transactions, so that many are read from every wallet.
+	var walletLimit int32
+	if limit > 0 {
+		walletLimit = offset + limit
+	}
+
+	totalCount, err := mw.CountTransactions(txFilter)
+	if err != nil {
+		return nil, err
+	}
+
 	transactions := make([]Transaction, 0)
-	for _, wallet := range mw.wallets {
-		walletTransactions, err := wallet.GetTransactionsRaw(offset, limit, txFilter, newestFirst)
+	for _, wallet := range mw.AllWallets() {
+		walletTransactions, err := wallet.GetTransactionsRaw(0, walletLimit, txFilter, newestFirst)
 		if err != nil {
-			return "", nil
+			return nil, err
 		}
 
 		transactions = append(transactions, walletTransactions...)
 	}
 
-	// sort transaction by timestamp in descending order
-	sort.Slice(transactions[:], func(i, j int) bool {
+	return mergedTransactionsPage(transactions, offset, limit, totalCount, newestFirst), nil
+}
+
+// mergedTransactionsPage returns the page at `offset` of the transactions of
+// several wallets, each read from the start of the wallet's transactions.
+func mergedTransactionsPage(transactions []Transaction, offset, limit int32, totalCount int,
+	newestFirst bool) *TransactionsPage {
+
+	sort.SliceStable(transactions, func(i, j int) bool {
 		if newestFirst {
 			return transactions[i].Timestamp > transactions[j].Timestamp
 		}
 		return transactions[i].Timestamp < transactions[j].Timestamp
 	})
 
-	if len(transactions) > int(limit) && limit > 0 {
+	if int(offset) >= len(transactions) {
+		transactions = transactions[:0]
+	} else {
+		transactions = transactions[offset:]
+	}
+	if limit > 0 && len(transactions) > int(limit) {
 		transactions = transactions[:limit]
 	}
 
-	jsonEncodedTransactions, err := json.Marshal(&transactions)
+	return newTransactionsPage(transactions, offset, limit, totalCount)
+}
+
+func newTransactionsPage(transactions []Transaction, offset, limit int32, totalCount int) *TransactionsPage {
+	if transactions == nil {
+		transactions = make([]Transaction, 0)
+	}
+
+	return &TransactionsPage{
+		Transactions: transactions,
+		Offset:       offset,
+		Limit:        limit,
+		TotalCount:   totalCount,
+		HasMore:      int(offset)+len(transactions) < totalCount,
+	}
+}
+
+// TransactionsList returns the transactions read by `GetTransactionsRaw` as a
+// `Transactions` response, which can be read from gomobile bindings.
+func (wallet *Wallet) TransactionsList(offset, limit, txFilter int32, newestFirst bool) (*Transactions, error) {
+	transactions, err := wallet.GetTransactionsRaw(offset, limit, txFilter, newestFirst)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(jsonEncodedTransactions), nil
+	return &Transactions{transactions: transactions}, nil
+}
+
+// TransactionsCount returns the number of transactions in the response.
+func (transactions *Transactions) TransactionsCount() int {
+	return len(transactions.transactions)
+}
+
+// TransactionAt returns the transaction at `index`, or nil if `index` is out
+// of range.
+func (transactions *Transactions) TransactionAt(index int) *Transaction {
+	if index < 0 || index >= len(transactions.transactions) {
+		return nil
+	}
+	return &transactions.transactions[index]
 }
 
+// InputsCount returns the number of inputs of the transaction.
+func (tx *Transaction) InputsCount() int {
+	return len(tx.Inputs)
+}
+
+// InputAt returns the input at `index`, or nil if `index` is out of range.
+func (tx *Transaction) InputAt(index int) *TxInput {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil
+	}
+	return tx.Inputs[index]
+}
+
+// OutputsCount returns the number of outputs of the transaction.
+func (tx *Transaction) OutputsCount() int {
+	return len(tx.Outputs)
+}
+
+// OutputAt returns the output at `index`, or nil if `index` is out of range.
+func (tx *Transaction) OutputAt(index int) *TxOutput {
+	if index < 0 || index >= len(tx.Outputs) {
+		return nil
+	}
+	return tx.Outputs[index]
+}
+
+// CountTransactions returns the number of transactions of this wallet that
+// match `txFilter`, one of the TxFilter constants, without reading them.
 func (wallet *Wallet) CountTransactions(txFilter int32) (int, error) {
 	return wallet.txDB.Count(txFilter, &Transaction{})
 }
 
+// CountTransactions returns the number of transactions of all wallets that
+// match `txFilter`, one of the TxFilter constants, without reading them.
+func (mw *MultiWallet) CountTransactions(txFilter int32) (int, error) {
+	var count int
+	for _, wallet := range mw.AllWallets() {
+		walletTxCount, err := wallet.CountTransactions(txFilter)
+		if err != nil {
+			return 0, err
+		}
+		count += walletTxCount
+	}
+
+	return count, nil
+}
+
 func TxMatchesFilter(txType string, txDirection, txFilter int32) bool {
 	return txindex.TxMatchesFilter(txType, txDirection, txFilter)
 }
+
+// AbandonTransaction removes the unmined transaction with hash `txHash`, and
+// any unmined transactions that spend its outputs, from the wallet. The
+// outputs spent by the removed transactions become spendable again. This is
+// meant for transactions that will not be mined, such as transactions whose
+// inputs were double spent.
+func (wallet *Wallet) AbandonTransaction(txHash []byte) error {
+	hash, err := chainhash.NewHash(txHash)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	txDetails, err := wallet.internal.TxDetails(ctx, hash)
+	if err != nil {
+		return translateError(err)
+	}
+	if txDetails.Block.Height != -1 {
+		return errors.New(ErrInvalid)
+	}
+
+	unminedTxs, err := wallet.internal.UnminedTransactions(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+
+	err = wallet.internal.AbandonTransaction(ctx, hash)
+	if err != nil {
+		log.Error(err)
+		return translateError(err)
+	}
+
+	remainingTxs, err := wallet.internal.UnminedTransactions(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	remaining := make(map[chainhash.Hash]bool, len(remainingTxs))
+	for _, tx := range remainingTxs {
+		remaining[tx.TxHash()] = true
+	}
+
+	// remove the abandoned transactions from the transaction index
+	for _, tx := range unminedTxs {
+		unminedHash := tx.TxHash()
+		if remaining[unminedHash] {
+			continue
+		}
+
+		err = wallet.txDB.Delete(unminedHash.String(), &Transaction{})
+		if err != nil {
+			log.Errorf("[%d] Error removing abandoned transaction %s from index: %v", wallet.ID, unminedHash, err)
+		}
+	}
+
+	log.Infof("[%d] Abandoned transaction %s", wallet.ID, hash)
+	return nil
+}
@@ -0,0 +1,431 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// Trezor message types used by the Decred flows, from the Trezor protobuf
+// definitions.
+const (
+	trezorMsgFailure           = 3
+	trezorMsgGetPublicKey      = 11
+	trezorMsgPublicKey         = 12
+	trezorMsgSignTx            = 15
+	trezorMsgPinMatrixRequest  = 18
+	trezorMsgPinMatrixAck      = 19
+	trezorMsgTxRequest         = 21
+	trezorMsgTxAck             = 22
+	trezorMsgButtonRequest     = 26
+	trezorMsgButtonAck         = 27
+	trezorMsgGetAddress        = 29
+	trezorMsgAddress           = 30
+	trezorMsgPassphraseRequest = 41
+	trezorMsgPassphraseAck     = 42
+)
+
+// Trezor TxRequest types, input and output script types and failure codes.
+const (
+	trezorTxInput    = 0
+	trezorTxOutput   = 1
+	trezorTxMeta     = 2
+	trezorTxFinished = 3
+
+	trezorSpendAddress  = 0
+	trezorPayToAddress  = 0
+	trezorPayToOpReturn = 3
+
+	trezorFailureActionCancelled = 4
+	trezorFailurePinCancelled    = 6
+	trezorFailurePinInvalid      = 7
+)
+
+// trezorDevice implements the Decred flows of Trezor devices, whose messages
+// are protobuf-encoded and framed as "##", the message type (2 bytes), the
+// payload length (4 bytes) and the payload, all big endian. Splitting frames
+// into the reports of the underlying transport is left to the host app.
+type trezorDevice struct {
+	transport HardwareWalletTransport
+	coinName  string
+}
+
+func (device *trezorDevice) publicKey(path []uint32) (string, error) {
+	msg := protoMessage(nil).path(1, path).string(4, device.coinName)
+	fields, err := device.call(trezorMsgGetPublicKey, msg, trezorMsgPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(fields.bytes(2)), nil
+}
+
+func (device *trezorDevice) address(path []uint32, display bool) (string, error) {
+	msg := protoMessage(nil).path(1, path).string(2, device.coinName).bool(3, display)
+	fields, err := device.call(trezorMsgGetAddress, msg, trezorMsgAddress)
+	if err != nil {
+		return "", err
+	}
+
+	return string(fields.bytes(1)), nil
+}
+
+// signTx streams `unsignedTx` and the previous transactions of its inputs to
+// the device as requested by the device and returns the transaction signed
+// by the device. Requests without a transaction hash are for `unsignedTx`.
+func (device *trezorDevice) signTx(unsignedTx *wire.MsgTx, request *hardwareSignRequest) (*wire.MsgTx, error) {
+	msg := protoMessage(nil).
+		uint(1, uint64(len(unsignedTx.TxOut))).
+		uint(2, uint64(len(unsignedTx.TxIn))).
+		string(3, device.coinName).
+		uint(4, uint64(unsignedTx.Version)).
+		uint(5, uint64(unsignedTx.LockTime)).
+		uint(6, uint64(unsignedTx.Expiry))
+
+	var serializedTx []byte
+	msgType := trezorMsgSignTx
+	for {
+		fields, err := device.call(msgType, msg, trezorMsgTxRequest)
+		if err != nil {
+			return nil, err
+		}
+		msgType = trezorMsgTxAck
+
+		// signatures are not needed, the serialized tx includes them
+		if serialized := fields.message(3); serialized != nil {
+			serializedTx = append(serializedTx, serialized.bytes(3)...)
+		}
+
+		requestType, _ := fields.uint(1)
+		if requestType == trezorTxFinished {
+			break
+		}
+
+		var index uint64
+		var tx *wire.MsgTx
+		isPrevTx := false
+		if details := fields.message(2); details != nil {
+			index, _ = details.uint(1)
+			if txHash := details.bytes(2); len(txHash) > 0 {
+				hash, err := chainhash.NewHash(reverseBytes(txHash))
+				if err != nil {
+					return nil, errors.New(ErrHardwareWalletFailure)
+				}
+				tx, isPrevTx = request.prevTxs[*hash]
+				if !isPrevTx {
+					log.Errorf("Hardware wallet requested unknown transaction %s", hash)
+					return nil, errors.New(ErrHardwareWalletFailure)
+				}
+			}
+		}
+		if tx == nil {
+			tx = unsignedTx
+		}
+
+		var txAck protoMessage
+		switch {
+		case requestType == trezorTxMeta:
+			txAck = protoMessage(nil).
+				uint(1, uint64(tx.Version)).
+				uint(4, uint64(tx.LockTime)).
+				uint(6, uint64(len(tx.TxIn))).
+				uint(7, uint64(len(tx.TxOut))).
+				uint(10, uint64(tx.Expiry))
+
+		case requestType == trezorTxInput && index < uint64(len(tx.TxIn)):
+			txIn := tx.TxIn[index]
+			input := protoMessage(nil).
+				bytes(2, reverseBytes(txIn.PreviousOutPoint.Hash[:])).
+				uint(3, uint64(txIn.PreviousOutPoint.Index)).
+				uint(5, uint64(txIn.Sequence)).
+				uint(9, uint64(txIn.PreviousOutPoint.Tree))
+			if !isPrevTx {
+				input = input.path(1, request.inputPaths[index]).
+					uint(6, trezorSpendAddress).
+					uint(8, uint64(txIn.ValueIn))
+			}
+			txAck = protoMessage(nil).message(2, input)
+
+		case requestType == trezorTxOutput && index < uint64(len(tx.TxOut)) && isPrevTx:
+			txOut := tx.TxOut[index]
+			output := protoMessage(nil).
+				uint(1, uint64(txOut.Value)).
+				bytes(2, txOut.PkScript).
+				uint(3, uint64(txOut.Version))
+			txAck = protoMessage(nil).message(3, output)
+
+		case requestType == trezorTxOutput && index < uint64(len(tx.TxOut)):
+			output, err := device.txOutput(tx.TxOut[index], int(index), request)
+			if err != nil {
+				return nil, err
+			}
+			txAck = protoMessage(nil).message(5, output)
+
+		default:
+			log.Errorf("Hardware wallet sent unexpected transaction request %d for index %d", requestType, index)
+			return nil, errors.New(ErrHardwareWalletFailure)
+		}
+
+		msg = protoMessage(nil).message(1, txAck)
+	}
+
+	signedTx := wire.NewMsgTx()
+	err := signedTx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		log.Errorf("Error decoding transaction signed by hardware wallet: %v", err)
+		return nil, errors.New(ErrHardwareWalletFailure)
+	}
+
+	return signedTx, nil
+}
+
+// txOutput returns the TxOutputType message of an output of the transaction
+// being signed. The change output is identified by its derivation path so
+// that the device does not ask the user to confirm it.
+func (device *trezorDevice) txOutput(txOut *wire.TxOut, index int, request *hardwareSignRequest) (protoMessage, error) {
+	output := protoMessage(nil).uint(3, uint64(txOut.Value))
+
+	if index == request.changeIndex && request.changePath != nil {
+		return output.path(2, request.changePath).uint(4, trezorPayToAddress), nil
+	}
+
+	if len(txOut.PkScript) > 0 && txOut.PkScript[0] == txscript.OP_RETURN {
+		pushes, err := txscript.PushedData(txOut.PkScript)
+		if err != nil || len(pushes) != 1 {
+			return nil, errors.New(ErrInvalid)
+		}
+		return output.uint(4, trezorPayToOpReturn).bytes(6, pushes[0]), nil
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version, txOut.PkScript, request.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return output.string(1, addrs[0].Address()).uint(4, trezorPayToAddress), nil
+}
+
+// call sends a message to the device and returns the fields of its response,
+// which must be of type `responseType`. Button, PIN and passphrase requests
+// sent by the device before the response are handled.
+func (device *trezorDevice) call(msgType int, msg protoMessage, responseType int) (protoFields, error) {
+	for {
+		respType, resp, err := device.exchange(msgType, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		fields, err := decodeProtoFields(resp)
+		if err != nil {
+			log.Errorf("Error decoding hardware wallet message %d: %v", respType, err)
+			return nil, errors.New(ErrHardwareWalletFailure)
+		}
+
+		switch respType {
+		case responseType:
+			return fields, nil
+
+		case trezorMsgButtonRequest:
+			msgType, msg = trezorMsgButtonAck, nil
+
+		case trezorMsgPinMatrixRequest:
+			pin, err := device.transport.RequestPin()
+			if err != nil {
+				return nil, errors.New(ErrContextCanceled)
+			}
+			msgType, msg = trezorMsgPinMatrixAck, protoMessage(nil).string(1, pin)
+
+		case trezorMsgPassphraseRequest:
+			passphrase, err := device.transport.RequestPassphrase()
+			if err != nil {
+				return nil, errors.New(ErrContextCanceled)
+			}
+			msgType, msg = trezorMsgPassphraseAck, protoMessage(nil).string(1, passphrase)
+
+		case trezorMsgFailure:
+			code, _ := fields.uint(1)
+			log.Errorf("Hardware wallet failure %d: %s", code, fields.bytes(2))
+			switch code {
+			case trezorFailureActionCancelled, trezorFailurePinCancelled:
+				return nil, errors.New(ErrContextCanceled)
+			case trezorFailurePinInvalid:
+				return nil, errors.New(ErrInvalidPassphrase)
+			}
+			return nil, errors.New(ErrHardwareWalletFailure)
+
+		default:
+			log.Errorf("Unexpected hardware wallet message %d, expected %d", respType, responseType)
+			return nil, errors.New(ErrHardwareWalletFailure)
+		}
+	}
+}
+
+// exchange sends a framed message to the device and unframes its response.
+func (device *trezorDevice) exchange(msgType int, msg protoMessage) (int, []byte, error) {
+	frame := make([]byte, 8, 8+len(msg))
+	frame[0], frame[1] = '#', '#'
+	binary.BigEndian.PutUint16(frame[2:], uint16(msgType))
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	resp, err := device.transport.Exchange(frame)
+	if err != nil {
+		log.Errorf("Hardware wallet transport error: %v", err)
+		return 0, nil, errors.New(ErrNotConnected)
+	}
+
+	if len(resp) < 8 || resp[0] != '#' || resp[1] != '#' {
+		return 0, nil, errors.New(ErrHardwareWalletFailure)
+	}
+	respType := int(binary.BigEndian.Uint16(resp[2:]))
+	length := binary.BigEndian.Uint32(resp[4:])
+	if uint64(length) > uint64(len(resp)-8) {
+		return 0, nil, errors.New(ErrHardwareWalletFailure)
+	}
+
+	return respType, resp[8 : 8+length], nil
+}
+
+func reverseBytes(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
+
+// protoMessage encodes the protobuf messages sent to Trezor devices, which
+// only use varint and length-delimited fields.
+type protoMessage []byte
+
+func (m protoMessage) uint(field int, value uint64) protoMessage {
+	m = appendVarint(m, uint64(field)<<3)
+	return appendVarint(m, value)
+}
+
+func (m protoMessage) bool(field int, value bool) protoMessage {
+	if value {
+		return m.uint(field, 1)
+	}
+	return m.uint(field, 0)
+}
+
+func (m protoMessage) bytes(field int, value []byte) protoMessage {
+	m = appendVarint(m, uint64(field)<<3|2)
+	m = appendVarint(m, uint64(len(value)))
+	return append(m, value...)
+}
+
+func (m protoMessage) string(field int, value string) protoMessage {
+	return m.bytes(field, []byte(value))
+}
+
+func (m protoMessage) message(field int, value protoMessage) protoMessage {
+	return m.bytes(field, value)
+}
+
+// path encodes a derivation path as a repeated uint32 field.
+func (m protoMessage) path(field int, path []uint32) protoMessage {
+	for _, index := range path {
+		m = m.uint(field, uint64(index))
+	}
+	return m
+}
+
+func appendVarint(b []byte, value uint64) []byte {
+	for value >= 0x80 {
+		b = append(b, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(b, byte(value))
+}
+
+// protoFields are the decoded fields of a protobuf message, by field number.
+// Varint fields hold their value in varint and length-delimited fields hold
+// their data in data.
+type protoFields map[int][]protoField
+
+type protoField struct {
+	varint uint64
+	data   []byte
+}
+
+func (fields protoFields) uint(field int) (uint64, bool) {
+	if len(fields[field]) == 0 {
+		return 0, false
+	}
+	return fields[field][0].varint, true
+}
+
+func (fields protoFields) bytes(field int) []byte {
+	if len(fields[field]) == 0 {
+		return nil
+	}
+	return fields[field][0].data
+}
+
+// message decodes an embedded message field, nil if the field is not set or
+// is not a valid message.
+func (fields protoFields) message(field int) protoFields {
+	if len(fields[field]) == 0 {
+		return nil
+	}
+	embedded, err := decodeProtoFields(fields[field][0].data)
+	if err != nil {
+		return nil
+	}
+	return embedded
+}
+
+func decodeProtoFields(b []byte) (protoFields, error) {
+	fields := make(protoFields)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid field key")
+		}
+		b = b[n:]
+
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0: // varint
+			value, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid varint field %d", field)
+			}
+			b = b[n:]
+			fields[field] = append(fields[field], protoField{varint: value})
+
+		case 1: // 64-bit, unused by the Decred messages
+			if len(b) < 8 {
+				return nil, fmt.Errorf("invalid 64-bit field %d", field)
+			}
+			b = b[8:]
+
+		case 2: // length-delimited
+			length, n := binary.Uvarint(b)
+			if n <= 0 || length > uint64(len(b)-n) {
+				return nil, fmt.Errorf("invalid length-delimited field %d", field)
+			}
+			b = b[n:]
+			fields[field] = append(fields[field], protoField{data: b[:length]})
+			b = b[length:]
+
+		case 5: // 32-bit, unused by the Decred messages
+			if len(b) < 4 {
+				return nil, fmt.Errorf("invalid 32-bit field %d", field)
+			}
+			b = b[4:]
+
+		default:
+			return nil, fmt.Errorf("unsupported wire type of field %d", field)
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,152 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestProtoMessage(t *testing.T) {
+	hardened := uint32(0x80000000)
+
+	tests := []struct {
+		name string
+		msg  protoMessage
+		want string
+	}{
+		{
+			name: "varint",
+			msg:  protoMessage(nil).uint(1, 150),
+			want: "089601",
+		},
+		{
+			name: "bool",
+			msg:  protoMessage(nil).bool(3, true).bool(4, false),
+			want: "18012000",
+		},
+		{
+			name: "string",
+			msg:  protoMessage(nil).string(2, "testing"),
+			want: "120774657374696e67",
+		},
+		{
+			name: "embedded message",
+			msg:  protoMessage(nil).message(1, protoMessage(nil).uint(1, 150)),
+			want: "0a03089601",
+		},
+		{
+			name: "GetPublicKey",
+			msg:  protoMessage(nil).path(1, []uint32{44 + hardened, 42 + hardened, hardened}).string(4, "Decred"),
+			want: "08ac8080800808aa80808008088080808008" + "2206" + hex.EncodeToString([]byte("Decred")),
+		},
+	}
+
+	for _, test := range tests {
+		if got := hex.EncodeToString(test.msg); got != test.want {
+			t.Fatalf("%s: encoded %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDecodeProtoFields(t *testing.T) {
+	fields, err := decodeProtoFields([]byte{0x08, 0x96, 0x01, 0x12, 0x02, 'h', 'i', 0x1a, 0x02, 0x08, 0x07})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := fields.uint(1); !ok || value != 150 {
+		t.Fatalf("field 1 is %d, want 150", value)
+	}
+	if value := fields.bytes(2); string(value) != "hi" {
+		t.Fatalf("field 2 is %q, want \"hi\"", value)
+	}
+	if value, _ := fields.message(3).uint(1); value != 7 {
+		t.Fatalf("field 3.1 is %d, want 7", value)
+	}
+
+	invalid := [][]byte{
+		{0x08},
+		{0x08, 0x96},
+		{0x12, 0x05, 'h', 'i'},
+		{0x0b},
+	}
+	for _, b := range invalid {
+		if _, err := decodeProtoFields(b); err == nil {
+			t.Fatalf("invalid message %x decoded", b)
+		}
+	}
+}
+
+// testTrezorTransport replies to the frames written to it with `responses`,
+// in order, and records the frames.
+type testTrezorTransport struct {
+	frames    [][]byte
+	responses [][]byte
+}
+
+func (transport *testTrezorTransport) Exchange(message []byte) ([]byte, error) {
+	transport.frames = append(transport.frames, message)
+	resp := transport.responses[0]
+	transport.responses = transport.responses[1:]
+	return resp, nil
+}
+
+func (transport *testTrezorTransport) RequestPin() (string, error) {
+	return "", nil
+}
+
+func (transport *testTrezorTransport) RequestPassphrase() (string, error) {
+	return "", nil
+}
+
+func testTrezorFrame(msgType int, msg protoMessage) []byte {
+	frame := []byte{'#', '#', 0, byte(msgType), 0, 0, 0, byte(len(msg))}
+	return append(frame, msg...)
+}
+
+func TestTrezorSignTxMeta(t *testing.T) {
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular), 1e8, nil))
+	tx.AddTxOut(wire.NewTxOut(1e8-1e5, []byte{0x51}))
+
+	var serializedTx bytes.Buffer
+	if err := tx.Serialize(&serializedTx); err != nil {
+		t.Fatal(err)
+	}
+
+	serialized := protoMessage(nil).bytes(3, serializedTx.Bytes())
+	transport := &testTrezorTransport{
+		responses: [][]byte{
+			testTrezorFrame(trezorMsgTxRequest, protoMessage(nil).uint(1, trezorTxMeta)),
+			testTrezorFrame(trezorMsgTxRequest, protoMessage(nil).uint(1, trezorTxFinished).message(3, serialized)),
+		},
+	}
+	device := &trezorDevice{transport: transport, coinName: "Decred"}
+
+	signedTx, err := device.signTx(tx, &hardwareSignRequest{changeIndex: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signedTx.TxHash() != tx.TxHash() {
+		t.Fatalf("signed tx %s, want %s", signedTx.TxHash(), tx.TxHash())
+	}
+
+	wantFrames := []string{
+		// SignTx: 1 output, 1 input, coin name, version 1, lock time 0,
+		// expiry 0
+		"2323000f00000012" + "08011001" + "1a06" + hex.EncodeToString([]byte("Decred")) + "200128003000",
+		// TxAck of the meta of the tx: version 1, lock time 0, 1 input,
+		// 1 output, expiry 0
+		"232300160000000c" + "0a0a" + "08012000300138015000",
+	}
+	if len(transport.frames) != len(wantFrames) {
+		t.Fatalf("sent %d frames, want %d", len(transport.frames), len(wantFrames))
+	}
+	for i, frame := range transport.frames {
+		if got := hex.EncodeToString(frame); got != wantFrames[i] {
+			t.Fatalf("frame %d is %s, want %s", i, got, wantFrames[i])
+		}
+	}
+}
@@ -3,11 +3,20 @@ package dcrlibwallet
 import (
 	"encoding/json"
 
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrwallet/errors/v2"
 )
 
+// startWalletListeners starts the listeners of the notifications of the
+// specified wallet, which must be started again whenever the wallet is opened.
+func (mw *MultiWallet) startWalletListeners(walletID int) {
+	go mw.listenForTransactions(walletID)
+	go mw.listenForAccountNotifications(walletID)
+}
+
 func (mw *MultiWallet) listenForTransactions(walletID int) {
-	wallet := mw.wallets[walletID]
+	wallet := mw.WalletWithID(walletID)
 	n := wallet.internal.NtfnServer.TransactionNotifications()
 	defer n.Done() // disassociate this notification client from server when this function exits.
 
@@ -41,6 +50,8 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 
 		for _, block := range v.AttachedBlocks {
 			blockHash := block.Header.BlockHash()
+			txHashes := make([]string, 0, len(block.Transactions))
+			minedTxs := make([]*Transaction, 0, len(block.Transactions))
 			for _, transaction := range block.Transactions {
 				tempTransaction, err := wallet.decodeTransactionWithTxSummary(&transaction, &blockHash)
 				if err != nil {
@@ -54,9 +65,70 @@ func (mw *MultiWallet) listenForTransactions(walletID int) {
 					return
 				}
 				mw.publishTransactionConfirmed(wallet.ID, transaction.Hash.String(), int32(block.Header.Height))
+				txHashes = append(txHashes, transaction.Hash.String())
+				minedTxs = append(minedTxs, tempTransaction)
 			}
 
+			mw.markConflictedTransactions(wallet, minedTxs)
+			mw.updateTicketStates(wallet, minedTxs, int32(block.Header.Height))
+
+			// the exchange rate source may be slow, don't hold up notifications
+			go mw.recordTxFiatRates(wallet, txHashes, block.Header.Timestamp)
+
 			mw.publishBlockAttached(wallet.ID, int32(block.Header.Height))
+
+			go mw.runTicketBuyer(wallet)
+		}
+	}
+}
+
+// markConflictedTransactions marks the indexed unmined transactions of the
+// wallet that spend an input spent by one of `minedTxs` as conflicted, since
+// they can no longer be mined, and notifies listeners of the conflicts.
+func (mw *MultiWallet) markConflictedTransactions(wallet *Wallet, minedTxs []*Transaction) {
+	// stakebase inputs of votes do not spend a previous output
+	nullHash := chainhash.Hash{}.String()
+
+	spentBy := make(map[string]string)
+	for _, minedTx := range minedTxs {
+		for _, input := range minedTx.Inputs {
+			if input.PreviousTransactionHash != nullHash {
+				spentBy[input.PreviousOutpoint] = minedTx.Hash
+			}
+		}
+	}
+	if len(spentBy) == 0 {
+		return
+	}
+
+	var unminedTxs []Transaction
+	err := wallet.txDB.ReadMatching(0, 0, []q.Matcher{q.Eq("BlockHeight", BlockHeightInvalid)}, false, &unminedTxs)
+	if err != nil {
+		log.Errorf("[%d] Error reading unmined transactions: %v", wallet.ID, err)
+		return
+	}
+
+	for i := range unminedTxs {
+		tx := &unminedTxs[i]
+		if tx.ConflictingTxHash != "" {
+			continue
+		}
+
+		for _, input := range tx.Inputs {
+			conflictingTxHash, spent := spentBy[input.PreviousOutpoint]
+			if !spent || conflictingTxHash == tx.Hash {
+				continue
+			}
+
+			log.Warnf("[%d] Transaction %s conflicts with mined transaction %s", wallet.ID, tx.Hash, conflictingTxHash)
+			tx.ConflictingTxHash = conflictingTxHash
+			_, err = wallet.txDB.SaveOrUpdate(&Transaction{}, tx)
+			if err != nil {
+				log.Errorf("[%d] Error marking transaction %s as conflicted: %v", wallet.ID, tx.Hash, err)
+			} else {
+				mw.publishTransactionConflict(wallet.ID, tx.Hash, conflictingTxHash)
+			}
+			break
 		}
 	}
 }
@@ -82,6 +154,27 @@ func (mw *MultiWallet) RemoveTxAndBlockNotificationListener(uniqueIdentifier str
 	delete(mw.txAndBlockNotificationListeners, uniqueIdentifier)
 }
 
+func (mw *MultiWallet) AddTxConflictListener(txConflictListener TxConflictListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	_, ok := mw.txConflictListeners[uniqueIdentifier]
+	if ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.txConflictListeners[uniqueIdentifier] = txConflictListener
+
+	return nil
+}
+
+func (mw *MultiWallet) RemoveTxConflictListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.txConflictListeners, uniqueIdentifier)
+}
+
 func (mw *MultiWallet) mempoolTransactionNotification(transaction string) {
 	mw.notificationListenersMu.RLock()
 	defer mw.notificationListenersMu.RUnlock()
@@ -108,3 +201,12 @@ func (mw *MultiWallet) publishBlockAttached(walletID int, blockHeight int32) {
 		txAndBlockNotifcationListener.OnBlockAttached(walletID, blockHeight)
 	}
 }
+
+func (mw *MultiWallet) publishTransactionConflict(walletID int, transactionHash, conflictingTxHash string) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, txConflictListener := range mw.txConflictListeners {
+		txConflictListener.OnTransactionConflict(walletID, transactionHash, conflictingTxHash)
+	}
+}
@@ -3,6 +3,7 @@ package dcrlibwallet
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -21,11 +22,70 @@ type TxAuthor struct {
 	sourceAccountNumber uint32
 	destinations        []TransactionDestination
 	changeAddress       string
+
+	// inputs are the outpoints set with `SetInputs`, inputs are selected
+	// automatically if empty.
+	inputs []string
+
+	// feeRate is the fee rate set with `SetFeeRate`, the default relay fee
+	// rate is used if zero.
+	feeRate dcrutil.Amount
+
+	// nullData is the data committed to in a null data (OP_RETURN) output,
+	// set with `SetNullData`.
+	nullData []byte
+
+	// expiry is the expiry height set with `SetExpiryHeight`, the expiry
+	// set by the wallet is kept if hasExpiry is false.
+	hasExpiry bool
+	expiry    uint32
+
+	// coinSelection is the input selection strategy set with
+	// `SetCoinSelection`.
+	coinSelection int32
+
+	// sourceAccounts are the accounts set with `SetSourceAccounts` whose
+	// outputs may fund this transaction, only the source account's outputs
+	// are used if empty.
+	sourceAccounts []uint32
+
+	// spendUnconfirmedChange overrides the wallet's unconfirmed change
+	// setting if not nil.
+	spendUnconfirmedChange *bool
+
+	// changeAccount is the account set with `SetChangeAccount` that receives
+	// the change of this transaction, the source account's change account is
+	// used if nil.
+	changeAccount *uint32
+
+	// draftID is the ID of the draft this transaction was saved to or
+	// resumed from, 0 if the transaction was never saved.
+	draftID int
 }
 
+// Bounds of the fee rates that may be set with `TxAuthor.SetFeeRate`, in
+// atoms/kB. Transactions paying less than the minimum fee rate are not
+// relayed by the network.
+const (
+	MinFeeRate = int64(txrules.DefaultRelayFeePerKb)
+	MaxFeeRate = 100 * MinFeeRate
+)
+
 func (mw *MultiWallet) NewUnsignedTx(sourceWallet *Wallet, sourceAccountNumber int32) *TxAuthor {
+	return sourceWallet.newUnsignedTx(sourceAccountNumber)
+}
+
+// NewUnsignedTxFromDefaultAccount returns a `TxAuthor` for a transaction
+// funded by the default account of `sourceWallet`, see `DefaultAccount`.
+func (mw *MultiWallet) NewUnsignedTxFromDefaultAccount(sourceWallet *Wallet) *TxAuthor {
+	return sourceWallet.newUnsignedTx(sourceWallet.DefaultAccount())
+}
+
+// newUnsignedTx returns a `TxAuthor` for a transaction funded by the
+// specified account of this wallet, see `NewUnsignedTx`.
+func (wallet *Wallet) newUnsignedTx(sourceAccountNumber int32) *TxAuthor {
 	return &TxAuthor{
-		sourceWallet:        sourceWallet,
+		sourceWallet:        wallet,
 		sourceAccountNumber: uint32(sourceAccountNumber),
 		destinations:        make([]TransactionDestination, 0),
 	}
@@ -39,6 +99,48 @@ func (tx *TxAuthor) AddSendDestination(address string, atomAmount int64, sendMax
 	})
 }
 
+// AddSendDestinations adds the json-encoded array of `SendOutput`s as
+// destinations of this transaction. All destinations are paid in a single
+// transaction that has one change output and pays one fee.
+func (tx *TxAuthor) AddSendDestinations(jsonEncodedOutputs string) error {
+	var outputs []SendOutput
+	err := json.Unmarshal([]byte(jsonEncodedOutputs), &outputs)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return tx.AddSendDestinationsRaw(outputs)
+}
+
+// AddSendDestinationsRaw validates `outputs` and adds them as destinations of
+// this transaction. No destination is added if any output is invalid.
+func (tx *TxAuthor) AddSendDestinationsRaw(outputs []SendOutput) error {
+	if len(outputs) == 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	for _, output := range outputs {
+		if _, err := dcrutil.DecodeAddress(output.Address, tx.sourceWallet.chainParams); err != nil {
+			return errors.New(ErrInvalidAddress)
+		}
+		if output.AtomAmount <= 0 || output.AtomAmount > MaxAmountAtom {
+			return errors.New(ErrInvalidAmount)
+		}
+	}
+
+	for _, output := range outputs {
+		tx.AddSendDestination(output.Address, output.AtomAmount, false)
+	}
+
+	return nil
+}
+
+// SendDestinationsCount returns the number of destinations of this
+// transaction.
+func (tx *TxAuthor) SendDestinationsCount() int {
+	return len(tx.destinations)
+}
+
 func (tx *TxAuthor) UpdateSendDestination(index int, address string, atomAmount int64, sendMax bool) {
 	tx.destinations[index] = TransactionDestination{
 		Address:    address,
@@ -69,22 +171,115 @@ func (tx *TxAuthor) TotalSendAmount() *Amount {
 	}
 }
 
+// SendAll replaces the destinations of this transaction with `address`, which
+// receives every spendable output of the source account less the fee.
+func (tx *TxAuthor) SendAll(address string) {
+	tx.destinations = []TransactionDestination{{
+		Address: address,
+		SendMax: true,
+	}}
+}
+
+// SendAllAmount returns the exact amount that the send max destination will
+// receive once the fee is deducted. The amount does not change when the
+// transaction is signed, unless the spendable outputs of the source account
+// change before the transaction is broadcast.
+func (tx *TxAuthor) SendAllAmount() (*Amount, error) {
+	sendMaxIndex := -1
+	for i, destination := range tx.destinations {
+		if destination.SendMax {
+			sendMaxIndex = i
+			break
+		}
+	}
+	if sendMaxIndex == -1 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	unsignedTx, err := tx.constructTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// the send max destination is the change output of the transaction
+	if unsignedTx.ChangeIndex < 0 {
+		return nil, errors.New(ErrInsufficientBalance)
+	}
+
+	sendAmount := unsignedTx.Tx.TxOut[unsignedTx.ChangeIndex].Value
+	return &Amount{
+		AtomValue: sendAmount,
+		DcrValue:  dcrutil.Amount(sendAmount).ToCoin(),
+	}, nil
+}
+
+// SetNullData adds a null data (OP_RETURN) output that commits to `data` to
+// this transaction, replacing any previously set data. The output carries no
+// value and is provably prunable. Returns `ErrInvalid` if `data` is empty or
+// larger than the maximum data carrier size relayed by the network.
+func (tx *TxAuthor) SetNullData(data []byte) error {
+	if len(data) == 0 || len(data) > txscript.MaxDataCarrierSize {
+		return errors.New(ErrInvalid)
+	}
+
+	tx.nullData = make([]byte, len(data))
+	copy(tx.nullData, data)
+	return nil
+}
+
+// ClearNullData removes the null data output set with `SetNullData`.
+func (tx *TxAuthor) ClearNullData() {
+	tx.nullData = nil
+}
+
+// NullData returns the data set with `SetNullData`.
+func (tx *TxAuthor) NullData() []byte {
+	return tx.nullData
+}
+
+// SetFeeRate sets the fee rate, in atoms/kB, paid by this transaction.
+// The default relay fee rate is used if `atomsPerKB` is 0. Returns
+// `ErrInvalidAmount` if the fee rate is not between MinFeeRate and MaxFeeRate.
+func (tx *TxAuthor) SetFeeRate(atomsPerKB int64) error {
+	if atomsPerKB != 0 && (atomsPerKB < MinFeeRate || atomsPerKB > MaxFeeRate) {
+		return errors.New(ErrInvalidAmount)
+	}
+
+	tx.feeRate = dcrutil.Amount(atomsPerKB)
+	return nil
+}
+
+// FeeRate returns the fee rate, in atoms/kB, paid by this transaction.
+func (tx *TxAuthor) FeeRate() int64 {
+	return int64(tx.relayFeePerKb())
+}
+
+func (tx *TxAuthor) relayFeePerKb() dcrutil.Amount {
+	if tx.feeRate == 0 {
+		return txrules.DefaultRelayFeePerKb
+	}
+	return tx.feeRate
+}
+
 func (tx *TxAuthor) EstimateFeeAndSize() (*TxFeeAndSize, error) {
 	unsignedTx, err := tx.constructTransaction()
 	if err != nil {
 		return nil, translateError(err)
 	}
 
-	feeToSendTx := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, unsignedTx.EstimatedSignedSerializeSize)
+	feeToSendTx := txrules.FeeForSerializeSize(tx.relayFeePerKb(), unsignedTx.EstimatedSignedSerializeSize)
 	feeAmount := &Amount{
 		AtomValue: int64(feeToSendTx),
 		DcrValue:  feeToSendTx.ToCoin(),
 	}
 
-	return &TxFeeAndSize{
+	txFeeAndSize := &TxFeeAndSize{
 		EstimatedSignedSize: unsignedTx.EstimatedSignedSerializeSize,
 		Fee:                 feeAmount,
-	}, nil
+		FeeRate:             tx.FeeRate(),
+	}
+
+	return txFeeAndSize, nil
 }
 
 func (tx *TxAuthor) EstimateMaxSendAmount() (*Amount, error) {
@@ -93,9 +288,21 @@ func (tx *TxAuthor) EstimateMaxSendAmount() (*Amount, error) {
 		return nil, err
 	}
 
-	spendableAccountBalance, err := tx.sourceWallet.SpendableForAccount(int32(tx.sourceAccountNumber))
-	if err != nil {
-		return nil, err
+	var spendableAccountBalance int64
+	if len(tx.inputs) > 0 {
+		inputDetail, err := tx.selectedInputs(tx.sourceWallet.shutdownContext())
+		if err != nil {
+			return nil, err
+		}
+		spendableAccountBalance = int64(inputDetail.Amount)
+	} else {
+		for _, account := range tx.inputAccounts() {
+			spendable, err := tx.sourceWallet.SpendableForAccount(int32(account))
+			if err != nil {
+				return nil, err
+			}
+			spendableAccountBalance += spendable
+		}
 	}
 
 	maxSendableAmount := spendableAccountBalance - txFeeAndSize.Fee.AtomValue
@@ -106,19 +313,138 @@ func (tx *TxAuthor) EstimateMaxSendAmount() (*Amount, error) {
 	}, nil
 }
 
+// UnsignedTransaction constructs this transaction and returns it unsigned,
+// along with the fee it pays once signed.
+func (tx *TxAuthor) UnsignedTransaction() (*UnsignedTransaction, error) {
+	unsignedTx, err := tx.constructTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var txBuf bytes.Buffer
+	txBuf.Grow(unsignedTx.Tx.SerializeSize())
+	err = unsignedTx.Tx.Serialize(&txBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalOutputAmount int64
+	for _, txOut := range unsignedTx.Tx.TxOut {
+		totalOutputAmount += txOut.Value
+	}
+
+	pkg, err := tx.sourceWallet.unsignedTxPackage(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	encodedPackage, err := EncodeUnsignedTxPackage(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnsignedTransaction{
+		UnsignedTransaction:       txBuf.Bytes(),
+		EstimatedSignedSize:       unsignedTx.EstimatedSignedSerializeSize,
+		ChangeIndex:               unsignedTx.ChangeIndex,
+		TotalOutputAmount:         totalOutputAmount,
+		TotalPreviousOutputAmount: int64(unsignedTx.TotalInput),
+		Fee:                       int64(unsignedTx.TotalInput) - totalOutputAmount,
+		FeeRate:                   tx.FeeRate(),
+		Package:                   encodedPackage,
+	}, nil
+}
+
+// Preview constructs this transaction without signing it and returns the
+// json-encoded `TxPreview` of the transaction. The wallet need not be
+// unlocked. The previewed inputs are selected again when the transaction is
+// broadcast, so the numbers may differ if the account's outputs change in the
+// meantime.
+func (tx *TxAuthor) Preview() (string, error) {
+	preview, err := tx.PreviewRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPreview, err := json.Marshal(preview)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPreview), nil
+}
+
+func (tx *TxAuthor) PreviewRaw() (*TxPreview, error) {
+	unsignedTx, err := tx.constructTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	preview := &TxPreview{
+		EstimatedSignedSize: unsignedTx.EstimatedSignedSerializeSize,
+		FeeRate:             tx.FeeRate(),
+		TotalInputAmount:    int64(unsignedTx.TotalInput),
+		Expiry:              int32(unsignedTx.Tx.Expiry),
+		Inputs:              make([]*TxPreviewInput, 0, len(unsignedTx.Tx.TxIn)),
+	}
+
+	for _, txIn := range unsignedTx.Tx.TxIn {
+		preview.Inputs = append(preview.Inputs, &TxPreviewInput{
+			OutPoint: txIn.PreviousOutPoint.String(),
+			Amount:   txIn.ValueIn,
+		})
+	}
+
+	// the change output of a send max transaction is the send max destination
+	hasSendMaxDestination := false
+	for _, destination := range tx.destinations {
+		hasSendMaxDestination = hasSendMaxDestination || destination.SendMax
+	}
+
+	var totalOutputAmount int64
+	for i, txOut := range unsignedTx.Tx.TxOut {
+		totalOutputAmount += txOut.Value
+		if i == unsignedTx.ChangeIndex && !hasSendMaxDestination {
+			preview.ChangeAmount = txOut.Value
+		} else {
+			preview.SendAmount += txOut.Value
+		}
+	}
+	preview.Fee = preview.TotalInputAmount - totalOutputAmount
+
+	return preview, nil
+}
+
 func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
-	defer func() {
+	n, err := tx.sourceWallet.internal.NetworkBackend()
+	if err != nil {
 		for i := range privatePassphrase {
 			privatePassphrase[i] = 0
 		}
-	}()
+		log.Error(err)
+		return nil, err
+	}
 
-	n, err := tx.sourceWallet.internal.NetworkBackend()
+	msgTx, err := tx.signTransaction(privatePassphrase)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 
+	return tx.publish(msgTx, n)
+}
+
+// signTransaction constructs and signs the transaction without publishing
+// it, so the caller can record it before it is broadcast with `publish`.
+func (tx *TxAuthor) signTransaction(privatePassphrase []byte) (*wire.MsgTx, error) {
+	defer func() {
+		for i := range privatePassphrase {
+			privatePassphrase[i] = 0
+		}
+	}()
+
+	if tx.sourceWallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
 	unsignedTx, err := tx.constructTransaction()
 	if err != nil {
 		return nil, translateError(err)
@@ -150,10 +476,10 @@ func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
 	}()
 
 	ctx := tx.sourceWallet.shutdownContext()
-	err = tx.sourceWallet.internal.Unlock(ctx, privatePassphrase, lock)
+	err = tx.sourceWallet.unlock(ctx, privatePassphrase, lock)
 	if err != nil {
 		log.Error(err)
-		return nil, errors.New(ErrInvalidPassphrase)
+		return nil, err
 	}
 
 	var additionalPkScripts map[wire.OutPoint][]byte
@@ -168,10 +494,20 @@ func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
 	for i, e := range invalidSigs {
 		invalidInputIndexes[i] = e.InputIndex
 	}
+	if len(invalidInputIndexes) > 0 {
+		log.Errorf("[%d] Could not sign inputs %v of transaction", tx.sourceWallet.ID, invalidInputIndexes)
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return &msgTx, nil
+}
 
+// publish broadcasts the signed transaction `msgTx` through the network
+// backend `n` and returns its hash.
+func (tx *TxAuthor) publish(msgTx *wire.MsgTx, n w.NetworkBackend) ([]byte, error) {
 	var serializedTransaction bytes.Buffer
 	serializedTransaction.Grow(msgTx.SerializeSize())
-	err = msgTx.Serialize(&serializedTransaction)
+	err := msgTx.Serialize(&serializedTransaction)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -184,7 +520,8 @@ func (tx *TxAuthor) Broadcast(privatePassphrase []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	txHash, err := tx.sourceWallet.internal.PublishTransaction(ctx, &msgTx, serializedTransaction.Bytes(), n)
+	ctx := tx.sourceWallet.shutdownContext()
+	txHash, err := tx.sourceWallet.internal.PublishTransaction(ctx, msgTx, serializedTransaction.Bytes(), n)
 	if err != nil {
 		return nil, translateError(err)
 	}
@@ -231,6 +568,14 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 		}
 	}
 
+	if len(tx.nullData) > 0 {
+		nullDataScript, err := txscript.GenerateProvablyPruneableOut(tx.nullData)
+		if err != nil {
+			return nil, errors.E(errors.Invalid, err)
+		}
+		outputs = append(outputs, wire.NewTxOut(0, nullDataScript))
+	}
+
 	if changeSource == nil {
 		// dcrwallet should ordinarily handle cases where a nil changeSource
 		// is passed to `wallet.NewUnsignedTransaction` but the changeSource
@@ -245,18 +590,114 @@ func (tx *TxAuthor) constructTransaction() (*txauthor.AuthoredTx, error) {
 		}
 	}
 
-	requiredConfirmations := tx.sourceWallet.RequiredConfirmations()
-	return tx.sourceWallet.internal.NewUnsignedTransaction(ctx, outputs, txrules.DefaultRelayFeePerKb, tx.sourceAccountNumber,
-		requiredConfirmations, outputSelectionAlgorithm, changeSource)
+	var unsignedTx *txauthor.AuthoredTx
+	if len(tx.inputs) > 0 {
+		unsignedTx, err = tx.constructTransactionWithInputs(ctx, outputs, changeSource)
+	} else if len(tx.sourceAccounts) > 0 || tx.spendsUnconfirmedChange() || tx.spendsMixedAccount() ||
+		(tx.coinSelection != CoinSelectionDefault && outputSelectionAlgorithm != w.OutputSelectionAlgorithmAll) {
+		selectAll := outputSelectionAlgorithm == w.OutputSelectionAlgorithmAll
+		unsignedTx, err = tx.constructTransactionWithStrategy(ctx, outputs, changeSource, selectAll)
+	} else {
+		requiredConfirmations := tx.sourceWallet.RequiredConfirmations()
+		unsignedTx, err = tx.sourceWallet.internal.NewUnsignedTransaction(ctx, outputs, tx.relayFeePerKb(), tx.sourceAccountNumber,
+			requiredConfirmations, outputSelectionAlgorithm, changeSource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.hasExpiry {
+		unsignedTx.Tx.Expiry = tx.expiry
+	}
+
+	return unsignedTx, nil
 }
 
-// changeSource derives an internal address from the source wallet and account
-// for this unsigned tx, if a change address had not been previously derived.
+// SetExpiryHeight sets the block height after which this transaction can no
+// longer be mined. The transaction never expires if `expiryHeight` is 0.
+// Returns `ErrInvalid` if the height has already been reached.
+func (tx *TxAuthor) SetExpiryHeight(expiryHeight int32) error {
+	if expiryHeight < 0 || (expiryHeight > 0 && expiryHeight <= tx.sourceWallet.GetBestBlock()) {
+		return errors.New(ErrInvalid)
+	}
+
+	tx.hasExpiry = true
+	tx.expiry = uint32(expiryHeight)
+	return nil
+}
+
+// SetExpiryBlocks sets the expiry of this transaction to `blocks` blocks after
+// the current best block.
+func (tx *TxAuthor) SetExpiryBlocks(blocks int32) error {
+	if blocks <= 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	return tx.SetExpiryHeight(tx.sourceWallet.GetBestBlock() + blocks)
+}
+
+// ExpiryHeight returns the expiry height set with `SetExpiryHeight` or
+// `SetExpiryBlocks`, 0 if the transaction does not expire, or -1 if no expiry
+// was set and the wallet default is used.
+func (tx *TxAuthor) ExpiryHeight() int32 {
+	if !tx.hasExpiry {
+		return -1
+	}
+	return int32(tx.expiry)
+}
+
+// SetChangeAccount sends the change of this transaction to a new internal
+// address of the specified account of the source wallet, instead of the
+// source account. The address is derived when the transaction is constructed.
+func (tx *TxAuthor) SetChangeAccount(accountNumber int32) error {
+	if accountNumber < 0 {
+		return errors.New(ErrInvalid)
+	}
+
+	_, err := tx.sourceWallet.AccountNameRaw(uint32(accountNumber))
+	if err != nil {
+		return translateError(err)
+	}
+
+	changeAccount := uint32(accountNumber)
+	tx.changeAccount = &changeAccount
+	tx.changeAddress = ""
+	return nil
+}
+
+// SetChangeAddress sends the change of this transaction to `address`, which
+// need not belong to the source wallet.
+func (tx *TxAuthor) SetChangeAddress(address string) error {
+	if _, err := dcrutil.DecodeAddress(address, tx.sourceWallet.chainParams); err != nil {
+		return errors.New(ErrInvalidAddress)
+	}
+
+	tx.changeAccount = nil
+	tx.changeAddress = address
+	return nil
+}
+
+// ResetChangeDestination sends the change of this transaction to the source
+// account (or the unmixed account, for the mixed account) again.
+func (tx *TxAuthor) ResetChangeDestination() {
+	tx.changeAccount = nil
+	tx.changeAddress = ""
+}
+
+// changeSource derives an internal address from the source wallet and the
+// account set with `SetChangeAccount` or the source account for this unsigned
+// tx, if a change address had not been previously derived or set with
+// `SetChangeAddress`.
+// Change from the mixed account is sent to the unmixed account.
 // The derived (or previously derived) address is used to prepare a
 // change source for receiving change from this tx back into the wallet.
 func (tx *TxAuthor) changeSource(ctx context.Context) (txauthor.ChangeSource, error) {
 	if tx.changeAddress == "" {
-		address, err := tx.sourceWallet.internal.NewChangeAddress(ctx, tx.sourceAccountNumber)
+		changeAccount := tx.sourceWallet.changeAccount(tx.sourceAccountNumber)
+		if tx.changeAccount != nil {
+			changeAccount = *tx.changeAccount
+		}
+		address, err := tx.sourceWallet.internal.NewChangeAddress(ctx, changeAccount)
 		if err != nil {
 			return nil, fmt.Errorf("change address error: %v", err)
 		}
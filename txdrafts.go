@@ -0,0 +1,175 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// TxDraft is an in-progress transaction saved with `SaveTxDraft`, so that the
+// transaction can be resumed with `ResumeTxDraft` after the app is restarted.
+// FeeRate is 0 and ExpiryHeight is -1 if the defaults are used.
+type TxDraft struct {
+	ID                     int                      `storm:"id,increment" json:"id"`
+	WalletID               int                      `storm:"index" json:"wallet_id"`
+	Label                  string                   `json:"label"`
+	SourceAccount          int32                    `json:"source_account"`
+	SourceAccounts         []int32                  `json:"source_accounts"`
+	Destinations           []TransactionDestination `json:"destinations"`
+	Inputs                 []string                 `json:"inputs"`
+	FeeRate                int64                    `json:"fee_rate"`
+	CoinSelection          int32                    `json:"coin_selection"`
+	ChangeAddress          string                   `json:"change_address"`
+	ChangeAccount          *uint32                  `json:"change_account"`
+	NullData               []byte                   `json:"null_data"`
+	ExpiryHeight           int32                    `json:"expiry_height"`
+	SpendUnconfirmedChange *bool                    `json:"spend_unconfirmed_change"`
+	CreatedAt              time.Time                `json:"created_at"`
+	UpdatedAt              time.Time                `storm:"index" json:"updated_at"`
+}
+
+// SaveTxDraft saves the recipients, amounts, accounts and fee options of `tx`
+// as a draft labelled `label` and returns the ID of the draft. Saving a
+// transaction that was saved or resumed before updates its draft.
+func (mw *MultiWallet) SaveTxDraft(tx *TxAuthor, label string) (int, error) {
+	draft := &TxDraft{}
+	if tx.draftID != 0 {
+		err := mw.db.One("ID", tx.draftID, draft)
+		if err != nil && err != storm.ErrNotFound {
+			return 0, err
+		}
+	}
+	if draft.ID == 0 {
+		draft.CreatedAt = time.Now()
+	}
+
+	draft.WalletID = tx.sourceWallet.ID
+	draft.Label = strings.TrimSpace(label)
+	draft.SourceAccount = int32(tx.sourceAccountNumber)
+	draft.SourceAccounts = make([]int32, len(tx.sourceAccounts))
+	for i, account := range tx.sourceAccounts {
+		draft.SourceAccounts[i] = int32(account)
+	}
+	draft.Destinations = tx.destinations
+	draft.Inputs = tx.inputs
+	draft.FeeRate = int64(tx.feeRate)
+	draft.CoinSelection = tx.coinSelection
+	draft.ChangeAddress = tx.changeAddress
+	draft.ChangeAccount = tx.changeAccount
+	draft.NullData = tx.nullData
+	draft.ExpiryHeight = tx.ExpiryHeight()
+	draft.SpendUnconfirmedChange = tx.spendUnconfirmedChange
+	draft.UpdatedAt = time.Now()
+
+	err := mw.db.Save(draft)
+	if err != nil {
+		return 0, err
+	}
+
+	tx.draftID = draft.ID
+	return draft.ID, nil
+}
+
+// ResumeTxDraft returns a transaction with the recipients, amounts, accounts
+// and fee options saved in the specified draft. The draft is updated when the
+// returned transaction is saved again.
+func (mw *MultiWallet) ResumeTxDraft(draftID int) (*TxAuthor, error) {
+	draft, err := mw.txDraft(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := mw.WalletWithID(draft.WalletID)
+	if wallet == nil || !wallet.WalletOpened() {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	tx := mw.NewUnsignedTx(wallet, draft.SourceAccount)
+	tx.draftID = draft.ID
+	if draft.Destinations != nil {
+		tx.destinations = draft.Destinations
+	}
+	tx.inputs = draft.Inputs
+	tx.coinSelection = draft.CoinSelection
+	tx.changeAddress = draft.ChangeAddress
+	tx.changeAccount = draft.ChangeAccount
+	tx.nullData = draft.NullData
+	tx.spendUnconfirmedChange = draft.SpendUnconfirmedChange
+
+	if draft.FeeRate != 0 {
+		if err = tx.SetFeeRate(draft.FeeRate); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(draft.SourceAccounts) > 0 {
+		if err = tx.SetSourceAccountsRaw(draft.SourceAccounts); err != nil {
+			return nil, err
+		}
+	}
+
+	// an expiry height that has been reached since the draft was saved is
+	// dropped, the wallet default is used instead.
+	if draft.ExpiryHeight >= 0 {
+		if err = tx.SetExpiryHeight(draft.ExpiryHeight); err != nil {
+			log.Warnf("[%d] Dropping expiry height %d of transaction draft %d: %v", wallet.ID,
+				draft.ExpiryHeight, draft.ID, err)
+		}
+	}
+
+	return tx, nil
+}
+
+// DeleteTxDraft deletes the specified draft, e.g. after its transaction is
+// broadcast.
+func (mw *MultiWallet) DeleteTxDraft(draftID int) error {
+	draft, err := mw.txDraft(draftID)
+	if err != nil {
+		return err
+	}
+
+	return mw.db.DeleteStruct(draft)
+}
+
+// TxDrafts returns the json-encoded list of the drafts of the specified wallet,
+// most recently updated first.
+func (mw *MultiWallet) TxDrafts(walletID int) (string, error) {
+	drafts, err := mw.TxDraftsRaw(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedDrafts, err := json.Marshal(drafts)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedDrafts), nil
+}
+
+func (mw *MultiWallet) TxDraftsRaw(walletID int) ([]TxDraft, error) {
+	drafts := make([]TxDraft, 0)
+	err := mw.db.Select(q.Eq("WalletID", walletID)).OrderBy("UpdatedAt").Reverse().Find(&drafts)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+func (mw *MultiWallet) txDraft(draftID int) (*TxDraft, error) {
+	draft := &TxDraft{}
+	err := mw.db.One("ID", draftID, draft)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return nil, errors.New(ErrNotExist)
+		}
+		return nil, err
+	}
+
+	return draft, nil
+}
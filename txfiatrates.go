@@ -0,0 +1,157 @@
+package dcrlibwallet
+
+import (
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+)
+
+// DefaultFiatCurrency is the currency of the exchange rates recorded for a
+// wallet whose FiatCurrencyConfigKey is not set.
+const DefaultFiatCurrency = "USD"
+
+// txFiatRateMaxBlockAge is the age of a block after which the current exchange
+// rate is not recorded for its transactions, as it is not the rate of when
+// they were confirmed.
+const txFiatRateMaxBlockAge = time.Hour
+
+// ExchangeRateSource is implemented by the host app to supply the current
+// DCR exchange rate from the exchange configured by the user. When an
+// exchange rate source is set, the rate is recorded for every transaction
+// when it is confirmed, so that the fiat value of the transaction at the time
+// it was confirmed is known without fetching historical prices.
+type ExchangeRateSource interface {
+	// ExchangeRate returns the current price of 1 DCR in `currency`, a
+	// currency code such as "USD".
+	ExchangeRate(currency string) (float64, error)
+}
+
+// TxFiatRate is the DCR exchange rate in Currency when a transaction of a
+// wallet was confirmed. Rates are stored apart from the transaction index so
+// they survive rescans, and are included in wallet backups.
+type TxFiatRate struct {
+	ID         int     `storm:"id,increment" json:"id"`
+	WalletID   int     `storm:"index" json:"wallet_id"`
+	TxHash     string  `storm:"index" json:"tx_hash"`
+	Currency   string  `json:"currency"`
+	Rate       float64 `json:"rate"`
+	RecordedAt int64   `json:"recorded_at"`
+}
+
+// SetExchangeRateSource sets the source of the exchange rates recorded for
+// confirmed transactions. Pass nil to stop recording exchange rates.
+func (mw *MultiWallet) SetExchangeRateSource(exchangeRateSource ExchangeRateSource) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+	mw.exchangeRateSource = exchangeRateSource
+}
+
+// FiatCurrency returns the currency of the exchange rates recorded for the
+// transactions of this wallet.
+func (wallet *Wallet) FiatCurrency() string {
+	return wallet.ReadStringConfigValueForKey(FiatCurrencyConfigKey, DefaultFiatCurrency)
+}
+
+// recordTxFiatRates records the current exchange rate of the wallet's fiat
+// currency for the transactions with the provided hashes, which were just
+// confirmed in a block mined at `blockTime`. Nothing is recorded while the
+// wallet is syncing or for blocks mined more than an hour ago, whose
+// transactions were not confirmed at the current rate. Transactions that
+// already have a recorded rate, such as transactions confirmed again after a
+// reorg, keep their rate.
+func (mw *MultiWallet) recordTxFiatRates(wallet *Wallet, txHashes []string, blockTime time.Time) {
+	if !wallet.IsSynced() || time.Since(blockTime) > txFiatRateMaxBlockAge {
+		return
+	}
+
+	mw.notificationListenersMu.RLock()
+	exchangeRateSource := mw.exchangeRateSource
+	mw.notificationListenersMu.RUnlock()
+
+	if exchangeRateSource == nil || len(txHashes) == 0 {
+		return
+	}
+
+	currency := strings.ToUpper(wallet.FiatCurrency())
+	rate, err := exchangeRateSource.ExchangeRate(currency)
+	if err != nil {
+		log.Errorf("[%d] Error getting %s exchange rate: %v", wallet.ID, currency, err)
+		return
+	}
+	if rate <= 0 {
+		return
+	}
+
+	recordedAt := time.Now().Unix()
+	for _, txHash := range txHashes {
+		err = mw.saveTxFiatRate(&TxFiatRate{
+			WalletID:   wallet.ID,
+			TxHash:     txHash,
+			Currency:   currency,
+			Rate:       rate,
+			RecordedAt: recordedAt,
+		})
+		if err != nil {
+			log.Errorf("[%d] Error saving exchange rate of transaction %s: %v", wallet.ID, txHash, err)
+		}
+	}
+}
+
+// saveTxFiatRate saves `txFiatRate` if no rate was recorded for the
+// transaction.
+func (mw *MultiWallet) saveTxFiatRate(txFiatRate *TxFiatRate) error {
+	err := mw.db.Select(q.Eq("WalletID", txFiatRate.WalletID), q.Eq("TxHash", txFiatRate.TxHash)).First(&TxFiatRate{})
+	if err == nil {
+		return nil
+	}
+	if err != storm.ErrNotFound {
+		return err
+	}
+
+	txFiatRate.ID = 0
+	return mw.db.Save(txFiatRate)
+}
+
+// TransactionFiatRate returns the exchange rate recorded when the transaction
+// with the hex-encoded hash `txHash` of the specified wallet was confirmed,
+// nil if no rate was recorded.
+func (mw *MultiWallet) TransactionFiatRate(walletID int, txHash string) *TxFiatRate {
+	txFiatRate := &TxFiatRate{}
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash)).First(txFiatRate)
+	if err != nil {
+		return nil
+	}
+	return txFiatRate
+}
+
+// walletTxFiatRates returns the exchange rates recorded for the specified
+// wallet's transactions.
+func (mw *MultiWallet) walletTxFiatRates(walletID int) ([]*TxFiatRate, error) {
+	var txFiatRates []*TxFiatRate
+	err := mw.db.Find("WalletID", walletID, &txFiatRates)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return txFiatRates, nil
+}
+
+func (mw *MultiWallet) txFiatRateFn(walletID int) func(txHash string) *TxFiatRate {
+	return func(txHash string) *TxFiatRate {
+		return mw.TransactionFiatRate(walletID, txHash)
+	}
+}
+
+// setTxFiatRate sets the fiat currency and exchange rate of `tx`, if a rate
+// was recorded when it was confirmed.
+func (wallet *Wallet) setTxFiatRate(tx *Transaction) {
+	if wallet.txFiatRate == nil {
+		return
+	}
+
+	if txFiatRate := wallet.txFiatRate(tx.Hash); txFiatRate != nil {
+		tx.FiatCurrency = txFiatRate.Currency
+		tx.FiatRate = txFiatRate.Rate
+	}
+}
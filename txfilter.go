@@ -0,0 +1,397 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// TransactionFilter selects the transactions returned by
+// `FilterTransactions`. Directions are TxDirection constants and Types are
+// TxType constants. Accounts are the account numbers that a transaction must
+// spend from or pay to. From and To are the unix timestamps the transaction
+// time must be within, inclusive. Tags are transaction tags, a transaction
+// must have at least one of them. Empty lists and zero timestamps do not
+// filter.
+type TransactionFilter struct {
+	Directions []int32  `json:"directions"`
+	Types      []string `json:"types"`
+	Accounts   []int32  `json:"accounts"`
+	From       int64    `json:"from"`
+	To         int64    `json:"to"`
+	Tags       []string `json:"tags"`
+}
+
+// matchers returns the storm matchers of the transactions of `wallet`
+// selected by this filter.
+func (filter *TransactionFilter) matchers(wallet *Wallet) ([]q.Matcher, error) {
+	var matchers []q.Matcher
+
+	if len(filter.Directions) > 0 {
+		for _, direction := range filter.Directions {
+			if direction != txhelper.TxDirectionSent && direction != txhelper.TxDirectionReceived &&
+				direction != txhelper.TxDirectionTransferred {
+				return nil, errors.New(ErrInvalid)
+			}
+		}
+		matchers = append(matchers, q.In("Direction", filter.Directions))
+	}
+
+	if len(filter.Types) > 0 {
+		for _, txType := range filter.Types {
+			switch txType {
+			case txhelper.TxTypeRegular, txhelper.TxTypeCoinBase, txhelper.TxTypeTicketPurchase,
+				txhelper.TxTypeVote, txhelper.TxTypeRevocation:
+			default:
+				return nil, errors.New(ErrInvalid)
+			}
+		}
+		matchers = append(matchers, q.In("Type", filter.Types))
+	}
+
+	if len(filter.Accounts) > 0 {
+		accounts := make(map[int32]bool, len(filter.Accounts))
+		for _, account := range filter.Accounts {
+			accounts[account] = true
+		}
+		matchers = append(matchers, q.Or(
+			q.NewFieldMatcher("Inputs", txInputsAccountMatcher(accounts)),
+			q.NewFieldMatcher("Outputs", txOutputsAccountMatcher(accounts)),
+		))
+	}
+
+	if filter.From > 0 && filter.To > 0 && filter.From > filter.To {
+		return nil, errors.New(ErrInvalid)
+	}
+	if filter.From > 0 {
+		matchers = append(matchers, q.Gte("Timestamp", filter.From))
+	}
+	if filter.To > 0 {
+		matchers = append(matchers, q.Lte("Timestamp", filter.To))
+	}
+
+	if len(filter.Tags) > 0 {
+		tags := make(map[string]bool, len(filter.Tags))
+		for _, tag := range filter.Tags {
+			tags[normalizeTxTag(tag)] = true
+		}
+		matchers = append(matchers, q.NewFieldMatcher("Hash", &txTagsMatcher{tags: tags, txTags: wallet.txTags}))
+	}
+
+	return matchers, nil
+}
+
+// txInputsAccountMatcher matches transactions with an input that spends from
+// one of the accounts.
+type txInputsAccountMatcher map[int32]bool
+
+func (accounts txInputsAccountMatcher) MatchField(v interface{}) (bool, error) {
+	inputs, _ := v.([]*TxInput)
+	for _, input := range inputs {
+		if accounts[input.AccountNumber] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// txOutputsAccountMatcher matches transactions with an output that pays to
+// one of the accounts.
+type txOutputsAccountMatcher map[int32]bool
+
+func (accounts txOutputsAccountMatcher) MatchField(v interface{}) (bool, error) {
+	outputs, _ := v.([]*TxOutput)
+	for _, output := range outputs {
+		if accounts[output.AccountNumber] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// txTagsMatcher matches transactions with one of the tags.
+type txTagsMatcher struct {
+	tags   map[string]bool
+	txTags func(txHash string) []string
+}
+
+func (matcher *txTagsMatcher) MatchField(v interface{}) (bool, error) {
+	txHash, _ := v.(string)
+	if matcher.txTags == nil || txHash == "" {
+		return false, nil
+	}
+
+	for _, tag := range matcher.txTags(txHash) {
+		if matcher.tags[tag] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func decodeTransactionFilter(jsonEncodedFilter string) (*TransactionFilter, error) {
+	filter := &TransactionFilter{}
+	if jsonEncodedFilter == "" {
+		return filter, nil
+	}
+
+	err := json.Unmarshal([]byte(jsonEncodedFilter), filter)
+	if err != nil {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return filter, nil
+}
+
+// FilterTransactions returns the json-encoded `TransactionsPage` of up to
+// `limit` transactions of this wallet selected by the json-encoded
+// `TransactionFilter`, starting at `offset`. All transactions are selected if
+// the filter is empty.
+func (wallet *Wallet) FilterTransactions(offset, limit int32, jsonEncodedFilter string,
+	newestFirst bool) (string, error) {
+
+	filter, err := decodeTransactionFilter(jsonEncodedFilter)
+	if err != nil {
+		return "", err
+	}
+
+	page, err := wallet.FilterTransactionsRaw(offset, limit, filter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPage, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPage), nil
+}
+
+func (wallet *Wallet) FilterTransactionsRaw(offset, limit int32, filter *TransactionFilter,
+	newestFirst bool) (*TransactionsPage, error) {
+
+	if offset < 0 || limit < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	matchers, err := filter.matchers(wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := wallet.countMatchingTransactions(matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := wallet.readMatchingTransactions(offset, limit, matchers, newestFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransactionsPage(transactions, offset, limit, totalCount), nil
+}
+
+// FilterTransactions returns the json-encoded `TransactionsPage` of up to
+// `limit` transactions of all wallets selected by the json-encoded
+// `TransactionFilter`, starting at `offset` of the selected transactions of
+// all wallets ordered by time.
+func (mw *MultiWallet) FilterTransactions(offset, limit int32, jsonEncodedFilter string,
+	newestFirst bool) (string, error) {
+
+	filter, err := decodeTransactionFilter(jsonEncodedFilter)
+	if err != nil {
+		return "", err
+	}
+
+	page, err := mw.FilterTransactionsRaw(offset, limit, filter, newestFirst)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedPage, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedPage), nil
+}
+
+func (mw *MultiWallet) FilterTransactionsRaw(offset, limit int32, filter *TransactionFilter,
+	newestFirst bool) (*TransactionsPage, error) {
+
+	if offset < 0 || limit < 0 {
+		return nil, errors.New(ErrInvalid)
+	}
+
+	// see GetTransactionsPageRaw
+	var walletLimit int32
+	if limit > 0 {
+		walletLimit = offset + limit
+	}
+
+	var totalCount int
+	transactions := make([]Transaction, 0)
+	for _, wallet := range mw.AllWallets() {
+		matchers, err := filter.matchers(wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		walletTxCount, err := wallet.countMatchingTransactions(matchers)
+		if err != nil {
+			return nil, err
+		}
+		totalCount += walletTxCount
+
+		walletTransactions, err := wallet.readMatchingTransactions(0, walletLimit, matchers, newestFirst)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, walletTransactions...)
+	}
+
+	return mergedTransactionsPage(transactions, offset, limit, totalCount, newestFirst), nil
+}
+
+func (wallet *Wallet) readMatchingTransactions(offset, limit int32, matchers []q.Matcher,
+	newestFirst bool) ([]Transaction, error) {
+
+	var transactions []Transaction
+	err := wallet.txDB.ReadMatching(offset, limit, matchers, newestFirst, &transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	swapActions := wallet.atomicSwapActions()
+	for i := range transactions {
+		wallet.setContactNames(&transactions[i])
+		wallet.setAtomicSwapAction(&transactions[i], swapActions)
+		wallet.setTxNote(&transactions[i])
+		wallet.setTxTags(&transactions[i])
+		wallet.setTxFiatRate(&transactions[i])
+		wallet.setConfirmations(&transactions[i], bestBlock)
+	}
+
+	return transactions, nil
+}
+
+// TransactionIterator is implemented by the host app to receive the
+// transactions of a wallet one at a time with `IterateTransactions`.
+type TransactionIterator interface {
+	// OnTransaction is called with each json-encoded transaction and returns
+	// true to stop the iteration.
+	OnTransaction(transaction string) (stop bool)
+}
+
+// IterateTransactions calls `iterator` with every transaction of this wallet
+// selected by the json-encoded `TransactionFilter`, one at a time, until it
+// returns true. Unlike `FilterTransactions`, the selected transactions are not
+// encoded into a single json string, which keeps memory use flat for wallets
+// with a large history.
+func (wallet *Wallet) IterateTransactions(jsonEncodedFilter string, newestFirst bool,
+	iterator TransactionIterator) error {
+
+	if iterator == nil {
+		return errors.New(ErrInvalid)
+	}
+
+	filter, err := decodeTransactionFilter(jsonEncodedFilter)
+	if err != nil {
+		return err
+	}
+
+	var encodeErr error
+	err = wallet.IterateTransactionsRaw(filter, newestFirst, func(tx *Transaction) bool {
+		jsonEncodedTx, err := json.Marshal(tx)
+		if err != nil {
+			encodeErr = err
+			return true
+		}
+		return iterator.OnTransaction(string(jsonEncodedTx))
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeErr
+}
+
+func (wallet *Wallet) IterateTransactionsRaw(filter *TransactionFilter, newestFirst bool,
+	fn func(tx *Transaction) (stop bool)) error {
+
+	matchers, err := filter.matchers(wallet)
+	if err != nil {
+		return err
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	swapActions := wallet.atomicSwapActions()
+	return wallet.txDB.EachMatching(matchers, newestFirst, &Transaction{}, func(record interface{}) bool {
+		tx, ok := record.(*Transaction)
+		if !ok {
+			return false
+		}
+
+		wallet.setContactNames(tx)
+		wallet.setAtomicSwapAction(tx, swapActions)
+		wallet.setTxNote(tx)
+		wallet.setTxTags(tx)
+		wallet.setTxFiatRate(tx)
+		wallet.setConfirmations(tx, bestBlock)
+		return fn(tx)
+	})
+}
+
+// CountFilteredTransactions returns the number of transactions of this wallet
+// selected by the json-encoded `TransactionFilter`, without reading them.
+func (wallet *Wallet) CountFilteredTransactions(jsonEncodedFilter string) (int, error) {
+	filter, err := decodeTransactionFilter(jsonEncodedFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	return wallet.CountFilteredTransactionsRaw(filter)
+}
+
+func (wallet *Wallet) CountFilteredTransactionsRaw(filter *TransactionFilter) (int, error) {
+	matchers, err := filter.matchers(wallet)
+	if err != nil {
+		return 0, err
+	}
+
+	return wallet.countMatchingTransactions(matchers)
+}
+
+// CountFilteredTransactions returns the number of transactions of all wallets
+// selected by the json-encoded `TransactionFilter`, without reading them.
+func (mw *MultiWallet) CountFilteredTransactions(jsonEncodedFilter string) (int, error) {
+	filter, err := decodeTransactionFilter(jsonEncodedFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	return mw.CountFilteredTransactionsRaw(filter)
+}
+
+func (mw *MultiWallet) CountFilteredTransactionsRaw(filter *TransactionFilter) (int, error) {
+	var count int
+	for _, wallet := range mw.AllWallets() {
+		walletTxCount, err := wallet.CountFilteredTransactionsRaw(filter)
+		if err != nil {
+			return 0, err
+		}
+		count += walletTxCount
+	}
+
+	return count, nil
+}
+
+func (wallet *Wallet) countMatchingTransactions(matchers []q.Matcher) (int, error) {
+	return wallet.txDB.CountMatching(matchers, &Transaction{})
+}
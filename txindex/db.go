@@ -16,7 +16,7 @@ const (
 
 	// Necessary to force re-indexing if changes are made to the structure of data being stored.
 	// Increment this version number if db structure changes such that client apps need to re-index.
-	TxDbVersion uint32 = 1
+	TxDbVersion uint32 = 4
 )
 
 type DB struct {
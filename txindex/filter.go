@@ -14,6 +14,9 @@ const (
 	TxFilterStaking     int32 = 4
 	TxFilterCoinBase    int32 = 5
 	TxFilterRegular     int32 = 6
+	TxFilterTickets     int32 = 7
+	TxFilterVotes       int32 = 8
+	TxFilterRevocations int32 = 9
 )
 
 func TxMatchesFilter(txType string, txDirection, txFilter int32) bool {
@@ -30,6 +33,12 @@ func TxMatchesFilter(txType string, txDirection, txFilter int32) bool {
 		return txType == txhelper.TxTypeCoinBase
 	case TxFilterRegular:
 		return txType == txhelper.TxTypeRegular
+	case TxFilterTickets:
+		return txType == txhelper.TxTypeTicketPurchase
+	case TxFilterVotes:
+		return txType == txhelper.TxTypeVote
+	case TxFilterRevocations:
+		return txType == txhelper.TxTypeRevocation
 	case TxFilterAll:
 		return true
 	}
@@ -69,6 +78,18 @@ func (db *DB) prepareTxQuery(txFilter int32) (query storm.Query) {
 		query = db.txDB.Select(
 			q.Eq("Type", txhelper.TxTypeRegular),
 		)
+	case TxFilterTickets:
+		query = db.txDB.Select(
+			q.Eq("Type", txhelper.TxTypeTicketPurchase),
+		)
+	case TxFilterVotes:
+		query = db.txDB.Select(
+			q.Eq("Type", txhelper.TxTypeVote),
+		)
+	case TxFilterRevocations:
+		query = db.txDB.Select(
+			q.Eq("Type", txhelper.TxTypeRevocation),
+		)
 	default:
 		query = db.txDB.Select(
 			q.True(),
@@ -77,3 +98,11 @@ func (db *DB) prepareTxQuery(txFilter int32) (query storm.Query) {
 
 	return
 }
+
+func (db *DB) prepareMatchingTxQuery(matchers []q.Matcher) storm.Query {
+	if len(matchers) == 0 {
+		return db.txDB.Select(q.True())
+	}
+
+	return db.txDB.Select(matchers...)
+}
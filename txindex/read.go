@@ -1,7 +1,10 @@
 package txindex
 
 import (
+	"errors"
+
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 )
 
 const MaxReOrgBlocks = 6
@@ -27,7 +30,17 @@ func (db *DB) ReadIndexingStartBlock() (int32, error) {
 // starting from the specified `offset`; and saves the transactions found to the received `transactions` object.
 // `transactions` should be a pointer to a slice of Transaction objects.
 func (db *DB) Read(offset, limit, txFilter int32, newestFirst bool, transactions interface{}) error {
-	query := db.prepareTxQuery(txFilter)
+	return db.find(db.prepareTxQuery(txFilter), offset, limit, newestFirst, transactions)
+}
+
+// ReadMatching queries the db for `limit` count transactions that match all
+// `matchers` starting from the specified `offset`; and saves the transactions
+// found to the received `transactions` object.
+func (db *DB) ReadMatching(offset, limit int32, matchers []q.Matcher, newestFirst bool, transactions interface{}) error {
+	return db.find(db.prepareMatchingTxQuery(matchers), offset, limit, newestFirst, transactions)
+}
+
+func (db *DB) find(query storm.Query, offset, limit int32, newestFirst bool, transactions interface{}) error {
 	if offset > 0 {
 		query = query.Skip(int(offset))
 	}
@@ -47,6 +60,34 @@ func (db *DB) Read(offset, limit, txFilter int32, newestFirst bool, transactions
 	return nil
 }
 
+// errStopIteration is returned by the callback of `EachMatching` to stop
+// iterating transactions.
+var errStopIteration = errors.New("stop iteration")
+
+// EachMatching calls `fn` with every transaction that matches all `matchers`,
+// ordered by time, until `fn` returns true. Each transaction is decoded into
+// a new object of the type of `txObj` and passed to `fn` instead of being
+// collected into a slice.
+func (db *DB) EachMatching(matchers []q.Matcher, newestFirst bool, txObj interface{},
+	fn func(tx interface{}) (stop bool)) error {
+
+	query := db.prepareMatchingTxQuery(matchers).OrderBy("Timestamp")
+	if newestFirst {
+		query = query.Reverse()
+	}
+
+	err := query.Each(txObj, func(tx interface{}) error {
+		if fn(tx) {
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && err != errStopIteration && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
 // Count queries the db for transactions of the `txObj` type
 // to return the number of records matching the specified `txFilter`.
 func (db *DB) Count(txFilter int32, txObj interface{}) (int, error) {
@@ -59,3 +100,14 @@ func (db *DB) Count(txFilter int32, txObj interface{}) (int, error) {
 
 	return count, nil
 }
+
+// CountMatching queries the db for transactions of the `txObj` type to return
+// the number of records matching all `matchers`.
+func (db *DB) CountMatching(matchers []q.Matcher, txObj interface{}) (int, error) {
+	count, err := db.prepareMatchingTxQuery(matchers).Count(txObj)
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
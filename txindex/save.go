@@ -49,3 +49,16 @@ func (db *DB) ClearSavedTransactions(emptyTxPointer interface{}) error {
 
 	return db.SaveLastIndexPoint(0)
 }
+
+// Delete removes the transaction with hash `txHash` from the database if it
+// was indexed. `emptyTxPointer` is used to find the transaction.
+func (db *DB) Delete(txHash string, emptyTxPointer interface{}) error {
+	err := db.txDB.One("Hash", txHash, emptyTxPointer)
+	if err == storm.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return db.txDB.DeleteStruct(emptyTxPointer)
+}
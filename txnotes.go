@@ -0,0 +1,102 @@
+package dcrlibwallet
+
+import (
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+// TxNote is a free-text note attached to a transaction of a wallet. Notes are
+// stored apart from the transaction index so they survive rescans, and are
+// included in wallet backups.
+type TxNote struct {
+	ID        int    `storm:"id,increment"`
+	WalletID  int    `storm:"index"`
+	TxHash    string `storm:"index"`
+	Note      string
+	UpdatedAt time.Time
+}
+
+// SetTransactionNote attaches `note` to the transaction with the hex-encoded
+// hash `txHash` of the specified wallet, replacing any previous note. The note
+// is removed if `note` is empty.
+func (mw *MultiWallet) SetTransactionNote(walletID int, txHash, note string) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if _, err := chainhash.NewHashFromStr(txHash); err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return mw.saveTxNote(walletID, txHash, strings.TrimSpace(note))
+}
+
+func (mw *MultiWallet) saveTxNote(walletID int, txHash, note string) error {
+	txNote := &TxNote{}
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash)).First(txNote)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	if note == "" {
+		if txNote.ID == 0 {
+			return nil
+		}
+		return mw.db.DeleteStruct(txNote)
+	}
+
+	txNote.WalletID = walletID
+	txNote.TxHash = txHash
+	txNote.Note = note
+	txNote.UpdatedAt = time.Now()
+	return mw.db.Save(txNote)
+}
+
+// TransactionNote returns the note attached to the transaction with the
+// hex-encoded hash `txHash` of the specified wallet, or an empty string.
+func (mw *MultiWallet) TransactionNote(walletID int, txHash string) string {
+	txNote := &TxNote{}
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash)).First(txNote)
+	if err != nil {
+		return ""
+	}
+
+	return txNote.Note
+}
+
+// walletTxNotes returns the notes of the specified wallet's transactions by
+// transaction hash.
+func (mw *MultiWallet) walletTxNotes(walletID int) (map[string]string, error) {
+	var txNotes []TxNote
+	err := mw.db.Find("WalletID", walletID, &txNotes)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	notes := make(map[string]string, len(txNotes))
+	for _, txNote := range txNotes {
+		notes[txNote.TxHash] = txNote.Note
+	}
+	return notes, nil
+}
+
+func (mw *MultiWallet) txNoteFn(walletID int) func(txHash string) string {
+	return func(txHash string) string {
+		return mw.TransactionNote(walletID, txHash)
+	}
+}
+
+// setTxNote sets the note attached to `tx`, if any.
+func (wallet *Wallet) setTxNote(tx *Transaction) {
+	if wallet.txNote == nil {
+		return
+	}
+
+	tx.Note = wallet.txNote(tx.Hash)
+}
@@ -60,5 +60,16 @@ func (wallet *Wallet) decodeTransactionWithTxSummary(txSummary *w.TransactionSum
 		Outputs:     walletOutputs,
 	}
 
-	return DecodeTransaction(walletTx, wallet.chainParams)
+	tx, err := DecodeTransaction(walletTx, wallet.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.setContactNames(tx)
+	wallet.setAtomicSwapAction(tx, wallet.atomicSwapActions())
+	wallet.setTxNote(tx)
+	wallet.setTxTags(tx)
+	wallet.setTxFiatRate(tx)
+	wallet.setConfirmations(tx, wallet.GetBestBlock())
+	return tx, nil
 }
@@ -0,0 +1,243 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/raedahgroup/dcrlibwallet/txhelper"
+)
+
+// maxTxTagLength is the maximum number of characters in a transaction tag.
+const maxTxTagLength = 32
+
+// TxTag is a tag, such as "salary" or "gift", attached to a transaction of a
+// wallet. Tags are stored apart from the transaction index so they survive
+// rescans, and are included in wallet backups.
+type TxTag struct {
+	ID       int    `storm:"id,increment"`
+	WalletID int    `storm:"index"`
+	TxHash   string `storm:"index"`
+	Tag      string `storm:"index"`
+}
+
+// TagSummary is the total amount received and sent by the transactions of a
+// wallet with a tag.
+type TagSummary struct {
+	Tag              string `json:"tag"`
+	TransactionCount int    `json:"transaction_count"`
+	Received         int64  `json:"received"`
+	Sent             int64  `json:"sent"`
+	Fees             int64  `json:"fees"`
+}
+
+// normalizeTxTag trims and lowercases `tag`, so tags differing only in case
+// are the same tag.
+func normalizeTxTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// AddTransactionTag tags the transaction with the hex-encoded hash `txHash` of
+// the specified wallet with `tag`. Tags are case-insensitive and stored in
+// lowercase.
+func (mw *MultiWallet) AddTransactionTag(walletID int, txHash, tag string) error {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	if _, err := chainhash.NewHashFromStr(txHash); err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	tag = normalizeTxTag(tag)
+	if tag == "" || utf8.RuneCountInString(tag) > maxTxTagLength {
+		return errors.New(ErrInvalid)
+	}
+
+	return mw.saveTxTag(walletID, txHash, tag)
+}
+
+func (mw *MultiWallet) saveTxTag(walletID int, txHash, tag string) error {
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash), q.Eq("Tag", tag)).First(&TxTag{})
+	if err == nil {
+		return nil
+	}
+	if err != storm.ErrNotFound {
+		return err
+	}
+
+	return mw.db.Save(&TxTag{
+		WalletID: walletID,
+		TxHash:   txHash,
+		Tag:      tag,
+	})
+}
+
+// RemoveTransactionTag removes `tag` from the transaction with the hex-encoded
+// hash `txHash` of the specified wallet.
+func (mw *MultiWallet) RemoveTransactionTag(walletID int, txHash, tag string) error {
+	txTag := &TxTag{}
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash), q.Eq("Tag", normalizeTxTag(tag))).First(txTag)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return errors.New(ErrNotExist)
+		}
+		return err
+	}
+
+	return mw.db.DeleteStruct(txTag)
+}
+
+// TransactionTags returns the json-encoded tags of the transaction with the
+// hex-encoded hash `txHash` of the specified wallet, sorted.
+func (mw *MultiWallet) TransactionTags(walletID int, txHash string) (string, error) {
+	jsonEncodedTags, err := json.Marshal(mw.TransactionTagsRaw(walletID, txHash))
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTags), nil
+}
+
+func (mw *MultiWallet) TransactionTagsRaw(walletID int, txHash string) []string {
+	var txTags []TxTag
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TxHash", txHash)).OrderBy("Tag").Find(&txTags)
+	if err != nil {
+		return make([]string, 0)
+	}
+
+	tags := make([]string, len(txTags))
+	for i, txTag := range txTags {
+		tags[i] = txTag.Tag
+	}
+	return tags
+}
+
+// WalletTransactionTags returns the json-encoded tags used by the transactions
+// of the specified wallet, sorted.
+func (mw *MultiWallet) WalletTransactionTags(walletID int) (string, error) {
+	tags, err := mw.WalletTransactionTagsRaw(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTags, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTags), nil
+}
+
+func (mw *MultiWallet) WalletTransactionTagsRaw(walletID int) ([]string, error) {
+	txTags, err := mw.walletTxTags(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	tagsMap := make(map[string]bool)
+	for _, tags := range txTags {
+		for _, tag := range tags {
+			tagsMap[tag] = true
+		}
+	}
+
+	tags := make([]string, 0, len(tagsMap))
+	for tag := range tagsMap {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// TagSummaries returns the json-encoded `TagSummary` of every tag used by the
+// transactions of the specified wallet, for the transactions within the unix
+// timestamps `from` and `to`, inclusive. Zero timestamps do not limit the
+// transactions.
+func (mw *MultiWallet) TagSummaries(walletID int, from, to int64) (string, error) {
+	summaries, err := mw.TagSummariesRaw(walletID, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedSummaries, err := json.Marshal(summaries)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedSummaries), nil
+}
+
+func (mw *MultiWallet) TagSummariesRaw(walletID int, from, to int64) ([]*TagSummary, error) {
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	tags, err := mw.WalletTransactionTagsRaw(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*TagSummary, 0, len(tags))
+	for _, tag := range tags {
+		filter := &TransactionFilter{Tags: []string{tag}, From: from, To: to}
+		page, err := wallet.FilterTransactionsRaw(0, 0, filter, true)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := &TagSummary{Tag: tag, TransactionCount: len(page.Transactions)}
+		for _, tx := range page.Transactions {
+			switch tx.Direction {
+			case txhelper.TxDirectionReceived:
+				summary.Received += tx.Amount
+			case txhelper.TxDirectionSent:
+				summary.Sent += tx.Amount
+				summary.Fees += tx.Fee
+			case txhelper.TxDirectionTransferred:
+				summary.Fees += tx.Fee
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// walletTxTags returns the tags of the specified wallet's transactions by
+// transaction hash.
+func (mw *MultiWallet) walletTxTags(walletID int) (map[string][]string, error) {
+	var txTags []TxTag
+	err := mw.db.Find("WalletID", walletID, &txTags)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	tags := make(map[string][]string)
+	for _, txTag := range txTags {
+		tags[txTag.TxHash] = append(tags[txTag.TxHash], txTag.Tag)
+	}
+	return tags, nil
+}
+
+func (mw *MultiWallet) txTagsFn(walletID int) func(txHash string) []string {
+	return func(txHash string) []string {
+		return mw.TransactionTagsRaw(walletID, txHash)
+	}
+}
+
+// setTxTags sets the tags of `tx`.
+func (wallet *Wallet) setTxTags(tx *Transaction) {
+	if wallet.txTags == nil {
+		return
+	}
+
+	tx.Tags = wallet.txTags(tx.Hash)
+}
@@ -7,6 +7,43 @@ type WalletsIterator struct {
 	wallets      []*Wallet
 }
 
+// WalletSetupOptions are the optional settings of a wallet created with
+// `CreateNewWalletWithOptions` or restored with `RestoreWalletWithOptions`.
+// The zero value of a field selects the default setting.
+type WalletSetupOptions struct {
+	// PublicPassphrase must be provided to open the wallet. The default
+	// public passphrase is used if empty.
+	PublicPassphrase string
+
+	// SeedPassphrase is used with the seed to derive the wallet's keys, the
+	// same seed passphrase must be provided when restoring the wallet.
+	SeedPassphrase string
+
+	// CoinType is the BIP0044 coin type used to derive the wallet's
+	// accounts, either the legacy or the SLIP0044 coin type of the network.
+	// The legacy coin type is used if 0.
+	CoinType int32
+
+	// Birthday is the unix time before which a restored wallet is known to
+	// have no transactions, blocks mined before it are not scanned. The whole
+	// chain is scanned if 0. New wallets are born when they are created.
+	Birthday int64
+}
+
+type WalletInfo struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	CreatedAt         int64  `json:"created_at"`
+	Birthday          int64  `json:"birthday"`
+	Tag               string `json:"tag"`
+	NetType           string `json:"net_type"`
+	IsRestored        bool   `json:"is_restored"`
+	HasSeedPassphrase bool   `json:"has_seed_passphrase"`
+	IsWatchingOnly    bool   `json:"is_watching_only"`
+	CoinType          int32  `json:"coin_type"`
+	DerivationPath    string `json:"derivation_path"`
+}
+
 type BlockInfo struct {
 	Height    int32
 	Timestamp int64
@@ -19,6 +56,7 @@ type Amount struct {
 
 type TxFeeAndSize struct {
 	Fee                 *Amount
+	FeeRate             int64
 	EstimatedSignedSize int
 }
 
@@ -28,6 +66,36 @@ type UnsignedTransaction struct {
 	ChangeIndex               int
 	TotalOutputAmount         int64
 	TotalPreviousOutputAmount int64
+
+	// Fee is the fee paid by the signed transaction at FeeRate, in
+	// atoms/kB.
+	Fee     int64
+	FeeRate int64
+
+	// Package is the encoded `UnsignedTxPackage` of the transaction, for
+	// signing the transaction offline.
+	Package string
+}
+
+// TxPreview describes a transaction constructed without signing it. Fee is
+// the fee paid at FeeRate, in atoms/kB, and SendAmount is the total amount
+// paid to the destinations, including any send max destination. Expiry is 0
+// if the transaction does not expire.
+type TxPreview struct {
+	EstimatedSignedSize int               `json:"estimated_signed_size"`
+	Fee                 int64             `json:"fee"`
+	FeeRate             int64             `json:"fee_rate"`
+	SendAmount          int64             `json:"send_amount"`
+	ChangeAmount        int64             `json:"change_amount"`
+	TotalInputAmount    int64             `json:"total_input_amount"`
+	Expiry              int32             `json:"expiry"`
+	Inputs              []*TxPreviewInput `json:"inputs"`
+}
+
+// TxPreviewInput is an output selected to fund a previewed transaction.
+type TxPreviewInput struct {
+	OutPoint string `json:"outpoint"`
+	Amount   int64  `json:"amount"`
 }
 
 type Balance struct {
@@ -38,6 +106,11 @@ type Balance struct {
 	LockedByTickets         int64
 	VotingAuthority         int64
 	UnConfirmed             int64
+
+	// Unmixed is the amount of the outputs of the mixed account that were
+	// not received from a mix, which is not included in Spendable as these
+	// outputs are not spent from the mixed account.
+	Unmixed int64
 }
 
 type Account struct {
@@ -49,6 +122,10 @@ type Account struct {
 	ExternalKeyCount int32
 	InternalKeyCount int32
 	ImportedKeyCount int32
+	Hidden           bool
+	Imported         bool
+	Mixed            bool
+	Unmixed          bool
 }
 
 type AccountsIterator struct {
@@ -63,6 +140,29 @@ type Accounts struct {
 	CurrentBlockHeight int32
 }
 
+type WalletDeletionListener interface {
+	OnWalletDeleted(walletID int)
+}
+
+// WalletWipeListener is notified when a wallet is wiped because the maximum
+// number of failed unlock attempts was exceeded.
+type WalletWipeListener interface {
+	OnWalletWiped(walletID int)
+}
+
+// WalletLockListener is notified when a wallet that was unlocked with
+// `UnlockWallet` is locked, either because its unlock session expired or
+// because it was locked with `LockWallet` or `LockWallets`.
+type WalletLockListener interface {
+	OnLocked(walletID int)
+}
+
+// ConfigChangeListener is notified when a multiwallet config value is saved
+// or deleted. `key` is empty if all config values were cleared.
+type ConfigChangeListener interface {
+	OnConfigValueChanged(key string)
+}
+
 /** begin sync-related types */
 
 type SyncProgressListener interface {
@@ -122,6 +222,39 @@ type TxAndBlockNotificationListener interface {
 	OnTransactionConfirmed(walletID int, hash string, blockHeight int32)
 }
 
+// TxConflictListener is notified when an unmined transaction of a wallet can
+// no longer be mined.
+type TxConflictListener interface {
+	// OnTransactionConflict is called when the unmined transaction `hash`
+	// can no longer be mined because an input it spends was spent by the
+	// mined transaction `conflictingHash`.
+	OnTransactionConflict(walletID int, hash string, conflictingHash string)
+}
+
+// AccountNotificationListener is notified when an account is created or
+// renamed in a wallet, so that account lists can be refreshed.
+type AccountNotificationListener interface {
+	OnAccountCreated(walletID int, accountNumber int32)
+	OnAccountRenamed(walletID int, accountNumber int32, newName string)
+}
+
+// WatchedAddressListener is notified of transactions that pay to or spend
+// from watched addresses. `amount` is negative for spends and `blockHeight`
+// is -1 for unmined transactions.
+type WatchedAddressListener interface {
+	OnWatchedAddressActivity(address, txHash string, amount int64, blockHeight int32)
+}
+
+// ScheduledPaymentListener is asked to confirm scheduled payments that
+// require confirmation and is notified when scheduled payments are sent or
+// fail. `preview` is the json-encoded `TxPreview` of the payment transaction.
+// Declined payments are skipped until their next scheduled time.
+type ScheduledPaymentListener interface {
+	ConfirmScheduledPayment(paymentID int, preview string) bool
+	OnScheduledPaymentSent(paymentID int, txHash string)
+	OnScheduledPaymentFailed(paymentID int, err string)
+}
+
 type BlocksRescanProgressListener interface {
 	OnBlocksRescanStarted(walletID int)
 	OnBlocksRescanProgress(*HeadersRescanProgressReport)
@@ -150,10 +283,83 @@ type Transaction struct {
 	Inputs    []*TxInput  `json:"inputs"`
 	Outputs   []*TxOutput `json:"outputs"`
 
+	// AccountDebits are the amounts spent from each wallet account by the
+	// inputs of the transaction.
+	AccountDebits []*AccountDebit `json:"account_debits"`
+
+	// AccountDeltas are the changes of the balance of each wallet account
+	// caused by the transaction. Classification is one of the
+	// TxClassification constants and tells transfers between own accounts
+	// and transactions with both wallet and external inputs apart from sent
+	// and received transactions, which Direction does not.
+	AccountDeltas  []*AccountDelta `json:"account_deltas"`
+	Classification string          `json:"classification"`
+
 	// Vote Info
 	VoteVersion    int32  `json:"vote_version"`
 	LastBlockValid bool   `json:"last_block_valid"`
 	VoteBits       string `json:"vote_bits"`
+
+	// TicketHash and TicketPrice are the hash and price of the ticket
+	// purchased by a ticket purchase or spent by a vote or revocation.
+	TicketHash  string `json:"ticket_hash"`
+	TicketPrice int64  `json:"ticket_price"`
+
+	// AtomicSwapAction is the action of the transaction in an atomic swap of
+	// the wallet, empty if the transaction is not part of an atomic swap.
+	AtomicSwapAction string `json:"atomic_swap_action"`
+
+	// Note is the note attached to the transaction with
+	// `SetTransactionNote`.
+	Note string `json:"note"`
+
+	// Tags are the tags added to the transaction with `AddTransactionTag`.
+	Tags []string `json:"tags"`
+
+	// FiatCurrency and FiatRate are the currency and DCR exchange rate
+	// recorded when the transaction was confirmed, see
+	// `SetExchangeRateSource`. Both are empty if no rate was recorded.
+	FiatCurrency string  `json:"fiat_currency"`
+	FiatRate     float64 `json:"fiat_rate"`
+
+	// Confirmations, IsMature and MaturityHeight are computed against the
+	// best block when the transaction is read. Outputs of coinbase and stake
+	// transactions can only be spent at MaturityHeight, other transactions
+	// are mature once mined. All are empty for unmined transactions.
+	Confirmations  int32 `json:"confirmations"`
+	IsMature       bool  `json:"is_mature"`
+	MaturityHeight int32 `json:"maturity_height"`
+
+	// Status is TxStatusPending, TxStatusConfirmed or TxStatusConflicted.
+	// ConflictingTxHash is the hash of the mined transaction that spent an
+	// input of this transaction while it was unmined, if any.
+	Status            string `json:"status"`
+	ConflictingTxHash string `json:"conflicting_tx_hash"`
+}
+
+// TransactionDetail is a transaction with the details shown when the
+// transaction is viewed that are not indexed. BlockHash is empty for unmined
+// transactions.
+type TransactionDetail struct {
+	Transaction
+	BlockHash string `json:"block_hash"`
+}
+
+// Transactions is a list of transactions that can be read from gomobile
+// bindings using `TransactionsCount` and `TransactionAt`.
+type Transactions struct {
+	transactions []Transaction
+}
+
+// TransactionsPage is a page of up to Limit transactions starting at Offset,
+// from the TotalCount transactions that match the filter the page was read
+// with. HasMore is true if there are transactions after this page.
+type TransactionsPage struct {
+	Transactions []Transaction `json:"transactions"`
+	Offset       int32         `json:"offset"`
+	Limit        int32         `json:"limit"`
+	TotalCount   int           `json:"total_count"`
+	HasMore      bool          `json:"has_more"`
 }
 
 type TxInput struct {
@@ -163,6 +369,23 @@ type TxInput struct {
 	Amount                   int64  `json:"amount"`
 	AccountName              string `json:"account_name"`
 	AccountNumber            int32  `json:"account_number"`
+	IsMine                   bool   `json:"is_mine"`
+}
+
+// AccountDebit is the total amount spent from a wallet account by the inputs
+// of a transaction.
+type AccountDebit struct {
+	AccountNumber int32  `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Amount        int64  `json:"amount"`
+}
+
+// AccountDelta is the change of the balance of a wallet account caused by a
+// transaction, negative if the account balance decreased.
+type AccountDelta struct {
+	AccountNumber int32  `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Amount        int64  `json:"amount"`
 }
 
 type TxOutput struct {
@@ -174,6 +397,11 @@ type TxOutput struct {
 	Internal      bool   `json:"internal"`
 	AccountName   string `json:"account_name"`
 	AccountNumber int32  `json:"account_number"`
+	IsMine        bool   `json:"is_mine"`
+
+	// ContactName is the name of the address book contact with the address
+	// of this output, if the output does not pay to the wallet.
+	ContactName string `json:"contact_name"`
 }
 
 // TxInfoFromWallet contains tx data that relates to the querying wallet.
@@ -212,6 +440,39 @@ type TransactionDestination struct {
 	SendMax    bool
 }
 
+// SendOutput is a recipient of a multi-recipient transaction.
+type SendOutput struct {
+	Address    string `json:"address"`
+	AtomAmount int64  `json:"amount"`
+}
+
+// UnminedTransaction is a pending transaction of a wallet. Age is the number
+// of seconds since the transaction was first seen by the wallet and
+// RebroadcastCount the number of times it was rebroadcast since the wallet
+// was loaded.
+type UnminedTransaction struct {
+	Transaction
+	Age              int64 `json:"age"`
+	RebroadcastCount int32 `json:"rebroadcast_count"`
+}
+
+// RebroadcastResult is the result of rebroadcasting an unmined transaction.
+// Error is empty if the transaction was sent to the connected peers.
+type RebroadcastResult struct {
+	TxHash string `json:"tx_hash"`
+	Error  string `json:"error"`
+}
+
+// ConsolidationResult is the result of consolidating the outputs of an
+// account with `ConsolidateUTXOs`.
+type ConsolidationResult struct {
+	TxHash           string `json:"tx_hash"`
+	InputCount       int32  `json:"input_count"`
+	TotalInputAmount int64  `json:"total_input_amount"`
+	OutputAmount     int64  `json:"output_amount"`
+	Fee              int64  `json:"fee"`
+}
+
 /** end tx-related types */
 
 /** begin ticket-related types */
@@ -227,6 +488,10 @@ type PurchaseTicketsRequest struct {
 	PoolAddress           string
 	PoolFees              float64
 	TicketFee             int64
+
+	// UseDefaultAccount purchases tickets from the wallet's default account
+	// instead of Account.
+	UseDefaultAccount bool
 }
 
 type GetTicketsRequest struct {
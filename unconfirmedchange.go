@@ -0,0 +1,88 @@
+package dcrlibwallet
+
+import (
+	"context"
+
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// SetSpendUnconfirmedChange sets whether change outputs of the wallet's own
+// unmined transactions may be spent before they are confirmed, even when
+// unconfirmed outputs are otherwise not spendable. Unconfirmed change is
+// counted as spendable in the account balances of the wallet if enabled.
+func (wallet *Wallet) SetSpendUnconfirmedChange(spend bool) {
+	wallet.SetBoolConfigValueForKey(SpendUnconfirmedChangeConfigKey, spend)
+}
+
+// SpendUnconfirmedChange returns true if unconfirmed change may be spent.
+func (wallet *Wallet) SpendUnconfirmedChange() bool {
+	return wallet.ReadBoolConfigValueForKey(SpendUnconfirmedChangeConfigKey, false)
+}
+
+// SetSpendUnconfirmedChange overrides the wallet's unconfirmed change setting
+// for this transaction.
+func (tx *TxAuthor) SetSpendUnconfirmedChange(spend bool) {
+	tx.spendUnconfirmedChange = &spend
+}
+
+// ResetSpendUnconfirmedChange removes the override set with
+// `SetSpendUnconfirmedChange`, the wallet's setting is used afterwards.
+func (tx *TxAuthor) ResetSpendUnconfirmedChange() {
+	tx.spendUnconfirmedChange = nil
+}
+
+// spendsUnconfirmedChange returns true if unconfirmed change may fund this
+// transaction in addition to the outputs that are spendable under the
+// wallet's confirmation policy.
+func (tx *TxAuthor) spendsUnconfirmedChange() bool {
+	if tx.sourceWallet.RequiredConfirmations() == 0 {
+		return false
+	}
+	if tx.spendUnconfirmedChange != nil {
+		return *tx.spendUnconfirmedChange
+	}
+	return tx.sourceWallet.SpendUnconfirmedChange()
+}
+
+// isUnconfirmedChange returns true if `output` is an unmined change output of
+// a transaction created by the wallet.
+func (wallet *Wallet) isUnconfirmedChange(ctx context.Context, output *w.TransactionOutput) bool {
+	if output.ContainingBlock.Height > 0 {
+		return false
+	}
+
+	txDetails, err := wallet.internal.TxDetails(ctx, &output.OutPoint.Hash)
+	if err != nil {
+		return false
+	}
+
+	for _, credit := range txDetails.Credits {
+		if credit.Index == output.OutPoint.Index {
+			return credit.Change
+		}
+	}
+	return false
+}
+
+// unconfirmedChange returns the total amount of the unspent unconfirmed change
+// outputs of `account`.
+func (wallet *Wallet) unconfirmedChange(account uint32) (int64, error) {
+	policy := w.OutputSelectionPolicy{
+		Account:               account,
+		RequiredConfirmations: 0,
+	}
+
+	ctx := wallet.shutdownContext()
+	outputs, err := wallet.internal.UnspentOutputs(ctx, policy)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var total int64
+	for _, output := range outputs {
+		if wallet.isUnconfirmedChange(ctx, output) {
+			total += output.Output.Value
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,192 @@
+package dcrlibwallet
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+const (
+	// MaxUnlockAttemptsConfigKey is the multiwallet config key for the number
+	// of consecutive failed unlock attempts after which a wallet is wiped.
+	// Wallets are never wiped if the value is not set or is 0.
+	MaxUnlockAttemptsConfigKey = "max_unlock_attempts"
+
+	failedUnlockAttemptsConfigKey = "failed_unlock_attempts"
+	lastFailedUnlockConfigKey     = "last_failed_unlock_time"
+
+	// unlockBackoffFreeAttempts is the number of failed unlock attempts
+	// allowed before further attempts are delayed.
+	unlockBackoffFreeAttempts = 3
+	unlockBackoffBase         = 30 * time.Second
+	unlockBackoffMax          = 24 * time.Hour
+)
+
+// unlock unlocks the wallet with `privPass` until a value is sent on `lock`.
+// Failed attempts are recorded and further attempts are rejected with
+// `ErrTooManyAttempts` until the backoff delay for the number of consecutive
+// failed attempts elapses. The wallet is wiped if the configured maximum
+// number of attempts is exceeded.
+// While an unlock session is active, temporary unlocks (with a non-nil `lock`)
+// do not lock the wallet afterwards and need no passphrase.
+// If `privPass` is empty, the passphrase is requested from the key source set
+// by the host app, if any. Only failed attempts with a passphrase typed by the
+// user, i.e. a non-empty `privPass`, are recorded, so background unlocks
+// without a passphrase or with the key source's passphrase never wipe the
+// wallet.
+func (wallet *Wallet) unlock(ctx context.Context, privPass []byte, lock <-chan time.Time) error {
+	if lock != nil && wallet.HasUnlockSession() {
+		if len(privPass) == 0 {
+			return nil
+		}
+		lock = nil
+	}
+
+	userPassphrase := len(privPass) > 0
+	if len(privPass) == 0 && wallet.keySourcePassphrase != nil {
+		keySourcePass, err := wallet.keySourcePassphrase()
+		if err != nil {
+			return err
+		}
+		if keySourcePass != nil {
+			defer func() {
+				for i := range keySourcePass {
+					keySourcePass[i] = 0
+				}
+			}()
+			privPass = keySourcePass
+		}
+	}
+
+	if wallet.UnlockBackoffRemaining() > 0 {
+		return errors.New(ErrTooManyAttempts)
+	}
+
+	err := wallet.internal.Unlock(ctx, privPass, lock)
+	if err != nil {
+		if userPassphrase && isPassphraseError(err) {
+			// the duress passphrase hides the wallet and is not counted as
+			// a failed attempt
+			if wallet.duressUnlock != nil && wallet.duressUnlock(privPass) {
+				return errors.New(ErrNotExist)
+			}
+			wallet.recordFailedUnlock()
+		}
+		return translateError(err)
+	}
+
+	if wallet.FailedUnlockAttempts() > 0 {
+		wallet.SaveUserConfigValue(failedUnlockAttemptsConfigKey, 0)
+	}
+
+	return nil
+}
+
+func isPassphraseError(err error) bool {
+	walletErr, ok := err.(*errors.Error)
+	return ok && walletErr.Kind == errors.Passphrase
+}
+
+func (wallet *Wallet) recordFailedUnlock() {
+	failedAttempts := wallet.FailedUnlockAttempts() + 1
+	wallet.SaveUserConfigValue(failedUnlockAttemptsConfigKey, failedAttempts)
+	wallet.SaveUserConfigValue(lastFailedUnlockConfigKey, time.Now().Unix())
+
+	var maxAttempts int32
+	wallet.readUserConfigValue(true, MaxUnlockAttemptsConfigKey, &maxAttempts)
+	if maxAttempts > 0 && failedAttempts >= maxAttempts && wallet.unlockAttemptsExceeded != nil {
+		log.Warnf("[%d] Maximum unlock attempts exceeded, wiping wallet", wallet.ID)
+		// the wipe shuts down the wallet and may stop the sync, it must not
+		// run in the code path of the caller, which may hold wallet or sync
+		// resources
+		go wallet.unlockAttemptsExceeded()
+	}
+}
+
+// FailedUnlockAttempts returns the number of consecutive failed attempts to
+// unlock this wallet.
+func (wallet *Wallet) FailedUnlockAttempts() int32 {
+	return wallet.ReadInt32ConfigValueForKey(failedUnlockAttemptsConfigKey, 0)
+}
+
+// UnlockBackoffRemaining returns the number of seconds before the next attempt
+// to unlock this wallet is allowed.
+func (wallet *Wallet) UnlockBackoffRemaining() int64 {
+	lastFailedUnlock := wallet.ReadLongConfigValueForKey(lastFailedUnlockConfigKey, 0)
+	return unlockBackoffRemaining(wallet.FailedUnlockAttempts(), lastFailedUnlock)
+}
+
+// unlockBackoffRemaining returns the number of seconds left of the backoff
+// delay for `failedAttempts` consecutive failed attempts, the last of which
+// was made at `lastFailedAttempt`.
+func unlockBackoffRemaining(failedAttempts int32, lastFailedAttempt int64) int64 {
+	if failedAttempts < unlockBackoffFreeAttempts {
+		return 0
+	}
+
+	// double the delay for every failed attempt after the free attempts
+	exponent := float64(failedAttempts - unlockBackoffFreeAttempts)
+	delay := time.Duration(math.Min(float64(unlockBackoffBase)*math.Pow(2, exponent), float64(unlockBackoffMax)))
+
+	remaining := time.Until(time.Unix(lastFailedAttempt, 0).Add(delay))
+	if remaining <= 0 {
+		return 0
+	}
+
+	return int64(math.Ceil(remaining.Seconds()))
+}
+
+// walletWipeFn returns the function called to wipe the specified wallet when
+// the maximum number of failed unlock attempts is exceeded. The wallet wipe
+// listener is notified once the wallet is wiped.
+func (mw *MultiWallet) walletWipeFn(walletID int) func() {
+	return func() {
+		// wipes triggered by concurrent failed attempts must not overlap
+		mw.walletWipeMu.Lock()
+		defer mw.walletWipeMu.Unlock()
+
+		wallet := mw.WalletWithID(walletID)
+		if wallet == nil {
+			return
+		}
+
+		if mw.IsConnectedToDecredNetwork() {
+			mw.CancelSync()
+			defer func() {
+				if mw.OpenedWalletsCount() > 0 {
+					mw.SpvSync()
+				}
+			}()
+		}
+
+		err := wallet.wipeWallet()
+		if err != nil {
+			log.Errorf("[%d] Error wiping wallet: %v", walletID, err)
+		}
+
+		err = mw.removeWallet(wallet)
+		if err != nil {
+			log.Errorf("[%d] Error removing wiped wallet: %v", walletID, err)
+		}
+
+		if listener := mw.getWalletWipeListener(); listener != nil {
+			listener.OnWalletWiped(walletID)
+		}
+	}
+}
+
+func (mw *MultiWallet) SetWalletWipeListener(walletWipeListener WalletWipeListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.walletWipeListener = walletWipeListener
+}
+
+func (mw *MultiWallet) getWalletWipeListener() WalletWipeListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.walletWipeListener
+}
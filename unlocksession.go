@@ -0,0 +1,84 @@
+package dcrlibwallet
+
+import (
+	"time"
+)
+
+// startUnlockSession starts an unlock session that locks the wallet after
+// `timeout`, replacing any previous session. The session does not expire if
+// `timeout` is 0.
+func (wallet *Wallet) startUnlockSession(timeout time.Duration) {
+	wallet.unlockSessionMu.Lock()
+	defer wallet.unlockSessionMu.Unlock()
+
+	if wallet.unlockSessionTimer != nil {
+		wallet.unlockSessionTimer.Stop()
+		wallet.unlockSessionTimer = nil
+	}
+
+	wallet.unlockSession = true
+	if timeout > 0 {
+		wallet.unlockSessionTimer = time.AfterFunc(timeout, func() {
+			log.Infof("[%d] Unlock session expired, locking wallet", wallet.ID)
+			wallet.LockWallet()
+		})
+	}
+}
+
+// endUnlockSession ends the unlock session without locking the wallet and
+// returns true if a session was active.
+func (wallet *Wallet) endUnlockSession() bool {
+	wallet.unlockSessionMu.Lock()
+	defer wallet.unlockSessionMu.Unlock()
+
+	if wallet.unlockSessionTimer != nil {
+		wallet.unlockSessionTimer.Stop()
+		wallet.unlockSessionTimer = nil
+	}
+
+	hadUnlockSession := wallet.unlockSession
+	wallet.unlockSession = false
+	return hadUnlockSession
+}
+
+// HasUnlockSession returns true if the wallet was unlocked with `UnlockWallet`
+// and has not been locked since.
+func (wallet *Wallet) HasUnlockSession() bool {
+	wallet.unlockSessionMu.Lock()
+	defer wallet.unlockSessionMu.Unlock()
+	return wallet.unlockSession && wallet.WalletOpened() && !wallet.internal.Locked()
+}
+
+// walletLockedFn returns the function called when the specified wallet is
+// locked after being unlocked with `UnlockWallet`.
+func (mw *MultiWallet) walletLockedFn(walletID int) func() {
+	return func() {
+		if walletLockListener := mw.getWalletLockListener(); walletLockListener != nil {
+			walletLockListener.OnLocked(walletID)
+		}
+	}
+}
+
+func (mw *MultiWallet) SetWalletLockListener(walletLockListener WalletLockListener) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	mw.walletLockListener = walletLockListener
+}
+
+func (mw *MultiWallet) getWalletLockListener() WalletLockListener {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	return mw.walletLockListener
+}
+
+// LockWallets locks all opened wallets and ends their unlock sessions. It
+// should be called when the app is sent to the background.
+func (mw *MultiWallet) LockWallets() {
+	for _, wallet := range mw.AllWallets() {
+		if wallet.WalletOpened() && !wallet.IsWatchingOnlyWallet() {
+			wallet.LockWallet()
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// UsedAddress is an address of the wallet that has received funds.
+// ReceiveCount is the number of transaction outputs that paid to the address
+// and TotalReceived is the sum of the amounts of those outputs in atoms.
+type UsedAddress struct {
+	Address       string `json:"address"`
+	Internal      bool   `json:"internal"`
+	ReceiveCount  int32  `json:"receive_count"`
+	TotalReceived int64  `json:"total_received"`
+}
+
+// ListUsedAddresses returns the json-encoded list of the addresses of the
+// specified account that have received funds.
+func (wallet *Wallet) ListUsedAddresses(account int32) (string, error) {
+	usedAddresses, err := wallet.ListUsedAddressesRaw(account)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedAddresses, err := json.Marshal(usedAddresses)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedAddresses), nil
+}
+
+// ListUsedAddressesRaw returns the addresses of the specified account that
+// have received funds according to the indexed transactions, ordered by the
+// number of times each address received funds, most reused first.
+func (wallet *Wallet) ListUsedAddressesRaw(account int32) ([]*UsedAddress, error) {
+	usages, err := wallet.addressUsages()
+	if err != nil {
+		return nil, err
+	}
+
+	addressList := make([]*UsedAddress, 0)
+	for address, usage := range usages {
+		if !usage.isMine || usage.account != account {
+			continue
+		}
+
+		addressList = append(addressList, &UsedAddress{
+			Address:       address,
+			Internal:      usage.internal,
+			ReceiveCount:  usage.receiveCount,
+			TotalReceived: usage.totalReceived,
+		})
+	}
+	sort.Slice(addressList, func(i, j int) bool {
+		if addressList[i].ReceiveCount != addressList[j].ReceiveCount {
+			return addressList[i].ReceiveCount > addressList[j].ReceiveCount
+		}
+		return addressList[i].Address < addressList[j].Address
+	})
+
+	return addressList, nil
+}
+
+// addressUsage is the number of indexed transaction outputs that paid to an
+// address and the sum of their amounts. isMine is true if the address belongs
+// to the wallet, in which case account is the account of the address.
+type addressUsage struct {
+	isMine        bool
+	account       int32
+	internal      bool
+	receiveCount  int32
+	totalReceived int64
+}
+
+// addressUsages scans the indexed transactions of the wallet and returns the
+// usage of each address of the wallet that received funds and of each
+// counterparty address that the wallet paid, by address. Outputs paying to
+// counterparty addresses are only counted for transactions with inputs of the
+// wallet, as the other outputs of received transactions were not paid by the
+// wallet.
+func (wallet *Wallet) addressUsages() (map[string]*addressUsage, error) {
+	var transactions []Transaction
+	err := wallet.txDB.Read(0, 0, TxFilterAll, false, &transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string]*addressUsage)
+	for _, tx := range transactions {
+		var sentByWallet bool
+		for _, input := range tx.Inputs {
+			if input.IsMine {
+				sentByWallet = true
+				break
+			}
+		}
+
+		for _, output := range tx.Outputs {
+			if output.Address == "" || (!output.IsMine && !sentByWallet) {
+				continue
+			}
+
+			usage, ok := usages[output.Address]
+			if !ok {
+				usage = &addressUsage{
+					isMine:   output.IsMine,
+					account:  output.AccountNumber,
+					internal: output.Internal,
+				}
+				usages[output.Address] = usage
+			}
+
+			usage.receiveCount++
+			usage.totalReceived += output.Amount
+		}
+	}
+
+	return usages, nil
+}
@@ -2,9 +2,11 @@ package dcrlibwallet
 
 import (
 	"context"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"os"
@@ -19,12 +21,17 @@ import (
 	"github.com/decred/dcrwallet/wallet/v3"
 	"github.com/decred/dcrwallet/wallet/v3/txrules"
 	"github.com/decred/dcrwallet/walletseed"
+	"github.com/raedahgroup/dcrlibwallet/bip39"
 	"github.com/raedahgroup/dcrlibwallet/internal/loader"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
 	walletDbName = "wallet.db"
 
+	seedPassphraseSaltPrefix = "dcrlibwallet seed passphrase"
+	seedPassphraseIterations = 2048
+
 	// Use 10% of estimated total headers fetch time to estimate rescan time
 	RescanPercentage = 0.1
 
@@ -129,11 +136,72 @@ func GenerateSeed() (string, error) {
 	return walletseed.EncodeMnemonic(seed), nil
 }
 
+// GenerateBIP39Seed generates a 24-word BIP-0039 mnemonic that can be used
+// to create or restore a wallet in place of the PGP word list mnemonic.
+func GenerateBIP39Seed() (string, error) {
+	return GenerateBIP39SeedInLanguage("english")
+}
+
+// GenerateBIP39SeedInLanguage generates a 24-word BIP-0039 mnemonic using
+// the word list for `language`. See `SeedWordListLanguages`.
+func GenerateBIP39SeedInLanguage(language string) (string, error) {
+	wordList, err := bip39.WordList(language)
+	if err != nil {
+		return "", errors.New(ErrInvalid)
+	}
+
+	entropy, err := bip39.NewEntropy(bip39.DefaultEntropyBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.EntropyToMnemonic(entropy, wordList)
+}
+
+// VerifySeed returns true if `seedMnemonic` is a valid PGP word list or
+// BIP-0039 mnemonic.
 func VerifySeed(seedMnemonic string) bool {
-	_, err := walletseed.DecodeUserInput(seedMnemonic)
+	_, err := decodeSeedMnemonic(seedMnemonic, "")
 	return err == nil
 }
 
+// VerifyBIP39Seed returns true if `seedMnemonic` is a valid BIP-0039 mnemonic
+// in any of the supported languages.
+func VerifyBIP39Seed(seedMnemonic string) bool {
+	_, err := bip39.DetectLanguage(seedMnemonic)
+	return err == nil
+}
+
+// decodeSeedMnemonic decodes a PGP word list mnemonic or hex-encoded seed into
+// the wallet seed. If the decoding fails, the mnemonic is decoded as a BIP-0039
+// mnemonic and the wallet seed is derived from it.
+// If `seedPassphrase` is not empty, it is used to derive a different wallet
+// seed from the mnemonic. For BIP-0039 mnemonics, the seed passphrase is used
+// as specified by BIP-0039.
+func decodeSeedMnemonic(seedMnemonic, seedPassphrase string) ([]byte, error) {
+	seed, err := walletseed.DecodeUserInput(seedMnemonic)
+	if err == nil {
+		if seedPassphrase == "" {
+			return seed, nil
+		}
+
+		// PGP word list mnemonics encode the seed directly, derive a new
+		// seed of the same length from the decoded seed and the passphrase.
+		salt := []byte(seedPassphraseSaltPrefix + seedPassphrase)
+		derivedSeed := pbkdf2.Key(seed, salt, seedPassphraseIterations, len(seed), sha512.New)
+		for i := range seed {
+			seed[i] = 0
+		}
+		return derivedSeed, nil
+	}
+
+	if !VerifyBIP39Seed(seedMnemonic) {
+		return nil, err
+	}
+
+	return bip39.NewSeed(seedMnemonic, seedPassphrase), nil
+}
+
 // ExtractDateOrTime returns the date represented by the timestamp as a date string if the timestamp is over 24 hours ago.
 // Otherwise, the time alone is returned as a string.
 func ExtractDateOrTime(timestamp int64) string {
@@ -260,7 +328,55 @@ func moveFile(sourcePath, destinationPath string) error {
 	return nil
 }
 
-func initWalletLoader(chainParams *chaincfg.Params, walletDataDir, walletDbDriver string) *loader.Loader {
+// copyFile copies the file at `sourcePath` to `destinationPath`
+// and flushes the copy to disk.
+func copyFile(sourcePath, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(destinationPath)
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	if _, err = io.Copy(destination, source); err != nil {
+		return err
+	}
+
+	return destination.Sync()
+}
+
+// overwriteFile replaces the content of the file at `filePath`
+// with zeros and flushes the changes to disk.
+func overwriteFile(filePath string, size int64) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zeros := make([]byte, 32*1024)
+	for written := int64(0); written < size; {
+		chunk := int64(len(zeros))
+		if size-written < chunk {
+			chunk = size - written
+		}
+
+		n, err := file.Write(zeros[:chunk])
+		if err != nil {
+			return err
+		}
+		written += int64(n)
+	}
+
+	return file.Sync()
+}
+
+func initWalletLoader(chainParams *chaincfg.Params, walletDataDir, walletDbDriver string, gapLimit int32) *loader.Loader {
 	defaultFeePerKb := txrules.DefaultRelayFeePerKb.ToCoin()
 	stakeOptions := &loader.StakeOptions{
 		VotingEnabled: false,
@@ -269,7 +385,7 @@ func initWalletLoader(chainParams *chaincfg.Params, walletDataDir, walletDbDrive
 		TicketFee:     defaultFeePerKb,
 	}
 
-	walletLoader := loader.NewLoader(chainParams, walletDataDir, stakeOptions, 20, false,
+	walletLoader := loader.NewLoader(chainParams, walletDataDir, stakeOptions, int(gapLimit), false,
 		defaultFeePerKb, wallet.DefaultAccountGapLimit, false)
 
 	if walletDbDriver != "" {
@@ -0,0 +1,111 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	w "github.com/decred/dcrwallet/wallet/v3"
+)
+
+// UnspentOutput is an unspent output of an account. OutPoint is formatted as
+// hash:index and Confirmations is 0 for unmined outputs. Coinbase and stake
+// tree outputs cannot be spent until they are mature. Locked outputs are not
+// selected automatically when constructing transactions.
+type UnspentOutput struct {
+	OutPoint      string `json:"outpoint"`
+	TxHash        string `json:"tx_hash"`
+	Index         uint32 `json:"index"`
+	Tree          int8   `json:"tree"`
+	Amount        int64  `json:"amount"`
+	Address       string `json:"address"`
+	BlockHeight   int32  `json:"block_height"`
+	Confirmations int32  `json:"confirmations"`
+	ReceiveTime   int64  `json:"receive_time"`
+	IsCoinbase    bool   `json:"is_coinbase"`
+	IsMature      bool   `json:"is_mature"`
+	IsSpendable   bool   `json:"is_spendable"`
+	IsLocked      bool   `json:"is_locked"`
+}
+
+// ListUnspent returns the json-encoded list of the unspent outputs of the
+// specified account, including unmined outputs.
+func (wallet *Wallet) ListUnspent(account int32) (string, error) {
+	unspentOutputs, err := wallet.ListUnspentRaw(account)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedOutputs, err := json.Marshal(unspentOutputs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedOutputs), nil
+}
+
+// ListUnspentRaw returns the unspent outputs of the specified account, ordered
+// by amount, largest first. An output is spendable if it is mature and has
+// the wallet's required number of confirmations.
+func (wallet *Wallet) ListUnspentRaw(account int32) ([]*UnspentOutput, error) {
+	policy := w.OutputSelectionPolicy{
+		Account:               uint32(account),
+		RequiredConfirmations: 0,
+	}
+
+	ctx := wallet.shutdownContext()
+	outputs, err := wallet.internal.UnspentOutputs(ctx, policy)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	bestBlock := wallet.GetBestBlock()
+	requiredConfirmations := wallet.RequiredConfirmations()
+
+	unspentOutputs := make([]*UnspentOutput, 0, len(outputs))
+	for _, output := range outputs {
+		unspentOutputs = append(unspentOutputs, wallet.unspentOutput(output, bestBlock, requiredConfirmations))
+	}
+
+	sort.Slice(unspentOutputs, func(i, j int) bool {
+		return unspentOutputs[i].Amount > unspentOutputs[j].Amount
+	})
+
+	return unspentOutputs, nil
+}
+
+// unspentOutput converts an output returned by the wallet to an
+// `UnspentOutput`, computing its confirmations from `bestBlock`.
+func (wallet *Wallet) unspentOutput(output *w.TransactionOutput, bestBlock, requiredConfirmations int32) *UnspentOutput {
+	coinbaseMaturity := int32(wallet.chainParams.CoinbaseMaturity)
+
+	unspentOutput := &UnspentOutput{
+		OutPoint:    output.OutPoint.String(),
+		TxHash:      output.OutPoint.Hash.String(),
+		Index:       output.OutPoint.Index,
+		Tree:        output.OutPoint.Tree,
+		Amount:      output.Output.Value,
+		BlockHeight: -1,
+		ReceiveTime: output.ReceiveTime.Unix(),
+		IsCoinbase:  output.OutputKind == w.OutputKindCoinbase,
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(output.Output.Version, output.Output.PkScript, wallet.chainParams)
+	if err == nil && len(addrs) > 0 {
+		unspentOutput.Address = addrs[0].Address()
+	}
+
+	// unmined outputs have no containing block
+	if output.ContainingBlock.Height > 0 {
+		unspentOutput.BlockHeight = output.ContainingBlock.Height
+		unspentOutput.Confirmations = bestBlock - output.ContainingBlock.Height + 1
+	}
+
+	requiresMaturity := unspentOutput.IsCoinbase || unspentOutput.Tree == wire.TxTreeStake
+	unspentOutput.IsMature = !requiresMaturity || unspentOutput.Confirmations >= coinbaseMaturity
+	unspentOutput.IsSpendable = unspentOutput.IsMature && unspentOutput.Confirmations >= requiredConfirmations
+	unspentOutput.IsLocked = wallet.internal.LockedOutpoint(output.OutPoint)
+
+	return unspentOutput
+}
@@ -0,0 +1,698 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/decred/dcrd/blockchain/stake/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+const (
+	// vspdAPIPath is the path of the version of the vspd API used to
+	// register tickets with a VSP.
+	vspdAPIPath = "/api/v3"
+
+	vspdRequestTimeout  = 30 * time.Second
+	maxVSPDResponseSize = 1 << 20
+)
+
+// Statuses of the fee transaction of a ticket registered with a VSP, as
+// reported by the VSP. The fee transaction is received when it is paid and
+// broadcast by the VSP once the ticket is confirmed.
+const (
+	VSPFeeTxStatusNone      = "none"
+	VSPFeeTxStatusReceived  = "received"
+	VSPFeeTxStatusBroadcast = "broadcast"
+	VSPFeeTxStatusConfirmed = "confirmed"
+	VSPFeeTxStatusError     = "error"
+)
+
+// VSPInfo is the policy of a vspd VSP, as returned by its /vspinfo endpoint.
+// FeePercentage is the percentage of the ticket price charged as fee.
+type VSPInfo struct {
+	APIVersions   []int64 `json:"apiversions"`
+	Timestamp     int64   `json:"timestamp"`
+	PubKey        []byte  `json:"pubkey"`
+	FeePercentage float64 `json:"feepercentage"`
+	VSPClosed     bool    `json:"vspclosed"`
+	Network       string  `json:"network"`
+}
+
+// VSPTicket is a ticket of a wallet registered with the vspd VSP at VSPHost.
+// The VSP votes the ticket once FeeTxStatus is confirmed, using the agenda
+// choices in VoteChoices. VSPPubKey is the key that the responses of the VSP
+// were signed with when the ticket was registered, later responses signed
+// with another key are rejected. FeeInputs are the outpoints, mapped to their
+// tree, spent by the fee transaction, which the wallet keeps locked until the
+// fee transaction is confirmed or fails.
+type VSPTicket struct {
+	ID              int               `storm:"id,increment" json:"id"`
+	WalletID        int               `storm:"index" json:"wallet_id"`
+	TicketHash      string            `storm:"index" json:"ticket_hash"`
+	VSPHost         string            `json:"vsp_host"`
+	VSPPubKey       []byte            `json:"vsp_pubkey"`
+	FeeAddress      string            `json:"fee_address"`
+	FeeAmount       int64             `json:"fee_amount"`
+	FeeExpiration   int64             `json:"fee_expiration"`
+	FeeTxHash       string            `json:"fee_tx_hash"`
+	FeeTxStatus     string            `json:"fee_tx_status"`
+	FeeInputs       map[string]int8   `json:"fee_inputs"`
+	TicketConfirmed bool              `json:"ticket_confirmed"`
+	VoteChoices     map[string]string `json:"vote_choices"`
+	UpdatedAt       int64             `json:"updated_at"`
+}
+
+// vspdClient makes requests to the vspd API of a VSP.
+type vspdClient struct {
+	host       string
+	httpClient *http.Client
+
+	// pubKey is the key that responses must be signed with, responses are
+	// not verified if nil.
+	pubKey ed25519.PublicKey
+}
+
+func newVSPDClient(host string, pubKey []byte) *vspdClient {
+	return &vspdClient{
+		host:       strings.TrimSuffix(host, "/"),
+		httpClient: &http.Client{Timeout: vspdRequestTimeout},
+		pubKey:     pubKey,
+	}
+}
+
+// do sends the json-encoded `request`, if not nil, to the API endpoint at
+// `path` and decodes the response into `response`. If `sign` is not nil, the
+// request is signed with the signature it returns for the request body, as
+// vspd requires the requests about a ticket to be signed by the ticket's
+// commitment address.
+func (c *vspdClient) do(method, path string, request interface{}, sign func(message string) ([]byte, error),
+	response interface{}) error {
+
+	var body []byte
+	if request != nil {
+		var err error
+		body, err = json.Marshal(request)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.host+vspdAPIPath+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if sign != nil {
+		signature, err := sign(string(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("VSP-Client-Signature", base64.StdEncoding.EncodeToString(signature))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("VSP %s request error: %v", c.host, err)
+		return errors.New(ErrVSPFailure)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxVSPDResponseSize))
+	if err != nil {
+		log.Errorf("VSP %s response error: %v", c.host, err)
+		return errors.New(ErrVSPFailure)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(respBody, &apiError)
+		log.Errorf("VSP %s%s error: %s (%d: %s)", c.host, path, resp.Status, apiError.Code, apiError.Message)
+		return errors.New(ErrVSPFailure)
+	}
+
+	if c.pubKey != nil {
+		signature, err := base64.StdEncoding.DecodeString(resp.Header.Get("VSP-Server-Signature"))
+		if err != nil || !ed25519.Verify(c.pubKey, respBody, signature) {
+			log.Errorf("VSP %s%s response has an invalid signature", c.host, path)
+			return errors.New(ErrVSPFailure)
+		}
+	}
+
+	err = json.Unmarshal(respBody, response)
+	if err != nil {
+		log.Errorf("VSP %s%s response error: %v", c.host, path, err)
+		return errors.New(ErrVSPFailure)
+	}
+
+	return nil
+}
+
+// GetVSPInfo returns the json-encoded `VSPInfo` of the vspd VSP at `vspHost`.
+func GetVSPInfo(vspHost string) (string, error) {
+	info, err := GetVSPInfoRaw(vspHost)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedInfo, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedInfo), nil
+}
+
+func GetVSPInfoRaw(vspHost string) (*VSPInfo, error) {
+	info := &VSPInfo{}
+	err := newVSPDClient(vspHost, nil).do(http.MethodGet, "/vspinfo", nil, nil, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(info.PubKey) != ed25519.PublicKeySize {
+		log.Errorf("VSP %s has an invalid public key", vspHost)
+		return nil, errors.New(ErrVSPFailure)
+	}
+
+	return info, nil
+}
+
+// vspTicketTxs returns the ticket with the hex-encoded hash `ticketHash` and
+// the transaction it spends, which vspd requires to validate the ticket, and
+// the ticket's commitment and voting addresses.
+func (wallet *Wallet) vspTicketTxs(ticketHash string) (ticket, parent *wire.MsgTx, commitmentAddr,
+	votingAddr dcrutil.Address, err error) {
+
+	hash, err := chainhash.NewHashFromStr(ticketHash)
+	if err != nil {
+		return nil, nil, nil, nil, errors.New(ErrInvalid)
+	}
+
+	ctx := wallet.shutdownContext()
+	txs, _, err := wallet.internal.GetTransactionsByHashes(ctx, []*chainhash.Hash{hash})
+	if err != nil {
+		return nil, nil, nil, nil, translateError(err)
+	}
+	if len(txs) == 0 || stake.DetermineTxType(txs[0]) != stake.TxTypeSStx {
+		return nil, nil, nil, nil, errors.New(ErrNotExist)
+	}
+	ticket = txs[0]
+
+	txs, _, err = wallet.internal.GetTransactionsByHashes(ctx, []*chainhash.Hash{&ticket.TxIn[0].PreviousOutPoint.Hash})
+	if err != nil {
+		return nil, nil, nil, nil, translateError(err)
+	}
+	if len(txs) == 0 {
+		return nil, nil, nil, nil, errors.New(ErrNotExist)
+	}
+	parent = txs[0]
+
+	commitmentAddr, err = stake.AddrFromSStxPkScrCommitment(ticket.TxOut[1].PkScript, wallet.chainParams)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	votingOutput := ticket.TxOut[0]
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(votingOutput.Version, votingOutput.PkScript, wallet.chainParams)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(addrs) != 1 {
+		return nil, nil, nil, nil, errors.New(ErrInvalidAddress)
+	}
+	votingAddr = addrs[0]
+
+	return ticket, parent, commitmentAddr, votingAddr, nil
+}
+
+// RegisterTicketWithVSP registers the ticket with the hex-encoded hash
+// `ticketHash` of the specified wallet with the vspd VSP at `vspHost` and
+// pays the VSP fee from `feeAccount`. The ticket's voting key is shared with
+// the VSP so it can vote the ticket. The fee transaction is broadcast by the
+// VSP once the ticket is confirmed. Returns the json-encoded `VSPTicket`.
+func (mw *MultiWallet) RegisterTicketWithVSP(walletID int, ticketHash, vspHost string, feeAccount int32,
+	privPass []byte) (string, error) {
+
+	vspTicket, err := mw.RegisterTicketWithVSPRaw(walletID, ticketHash, vspHost, feeAccount, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTicket, err := json.Marshal(vspTicket)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTicket), nil
+}
+
+func (mw *MultiWallet) RegisterTicketWithVSPRaw(walletID int, ticketHash, vspHost string, feeAccount int32,
+	privPass []byte) (*VSPTicket, error) {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	wallet := mw.WalletWithID(walletID)
+	if wallet == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+	if wallet.IsWatchingOnlyWallet() {
+		return nil, errors.New(ErrWalletIsWatchOnly)
+	}
+
+	vspTicket, err := mw.vspTicket(walletID, ticketHash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	// tickets whose fee payment failed may be registered again
+	if vspTicket != nil && vspTicket.FeeTxStatus != VSPFeeTxStatusError {
+		return nil, errors.New(ErrExist)
+	}
+
+	ticket, parent, commitmentAddr, votingAddr, err := wallet.vspTicketTxs(ticketHash)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := GetVSPInfoRaw(vspHost)
+	if err != nil {
+		return nil, err
+	}
+	if info.Network != wallet.chainParams.Name {
+		log.Errorf("VSP %s is on %s, wallet is on %s", vspHost, info.Network, wallet.chainParams.Name)
+		return nil, errors.New(ErrVSPFailure)
+	}
+	if info.VSPClosed {
+		log.Errorf("VSP %s is closed", vspHost)
+		return nil, errors.New(ErrVSPFailure)
+	}
+
+	lock := make(chan time.Time, 1)
+	defer func() {
+		lock <- time.Time{}
+	}()
+
+	ctx := wallet.shutdownContext()
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	votingWIF, err := wallet.internal.DumpWIFPrivateKey(ctx, votingAddr)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	client := newVSPDClient(vspHost, info.PubKey)
+	sign := func(message string) ([]byte, error) {
+		return wallet.internal.SignMessage(ctx, message, commitmentAddr)
+	}
+
+	ticketHex, err := txHex(ticket)
+	if err != nil {
+		return nil, err
+	}
+	parentHex, err := txHex(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	feeAddressRequest := map[string]interface{}{
+		"timestamp":  time.Now().Unix(),
+		"tickethash": ticketHash,
+		"tickethex":  ticketHex,
+		"parenthex":  parentHex,
+	}
+	var feeAddressResponse struct {
+		FeeAddress string `json:"feeaddress"`
+		FeeAmount  int64  `json:"feeamount"`
+		Expiration int64  `json:"expiration"`
+	}
+	err = client.do(http.MethodPost, "/feeaddress", feeAddressRequest, sign, &feeAddressResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dcrutil.DecodeAddress(feeAddressResponse.FeeAddress, wallet.chainParams); err != nil {
+		log.Errorf("VSP %s returned an invalid fee address: %v", vspHost, err)
+		return nil, errors.New(ErrVSPFailure)
+	}
+	if feeAddressResponse.FeeAmount <= 0 {
+		log.Errorf("VSP %s returned an invalid fee amount: %d", vspHost, feeAddressResponse.FeeAmount)
+		return nil, errors.New(ErrVSPFailure)
+	}
+
+	// the inputs of the failed fee transaction may be spent by the new one
+	if vspTicket != nil {
+		wallet.unlockVSPFeeInputs(vspTicket)
+	}
+
+	feeTx, err := mw.signedVSPFeeTx(wallet, feeAccount, feeAddressResponse.FeeAddress, feeAddressResponse.FeeAmount)
+	if err != nil {
+		return nil, err
+	}
+	feeTxHex, err := txHex(feeTx)
+	if err != nil {
+		return nil, err
+	}
+
+	voteChoices := make(map[string]string)
+	payFeeRequest := map[string]interface{}{
+		"timestamp":   time.Now().Unix(),
+		"tickethash":  ticketHash,
+		"feetx":       feeTxHex,
+		"votingkey":   votingWIF,
+		"votechoices": voteChoices,
+	}
+	var payFeeResponse struct{}
+	err = client.do(http.MethodPost, "/payfee", payFeeRequest, sign, &payFeeResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if vspTicket == nil {
+		vspTicket = &VSPTicket{WalletID: walletID, TicketHash: ticketHash}
+	}
+
+	// the fee transaction is not published by the wallet, lock its inputs
+	// so they are not spent by another transaction before the VSP
+	// broadcasts it. The locks are saved with the ticket and restored when
+	// the wallet is reopened.
+	vspTicket.FeeInputs = make(map[string]int8, len(feeTx.TxIn))
+	for _, txIn := range feeTx.TxIn {
+		wallet.internal.LockOutpoint(txIn.PreviousOutPoint)
+		vspTicket.FeeInputs[txIn.PreviousOutPoint.String()] = txIn.PreviousOutPoint.Tree
+	}
+
+	vspTicket.VSPHost = client.host
+	vspTicket.VSPPubKey = info.PubKey
+	vspTicket.FeeAddress = feeAddressResponse.FeeAddress
+	vspTicket.FeeAmount = feeAddressResponse.FeeAmount
+	vspTicket.FeeExpiration = feeAddressResponse.Expiration
+	vspTicket.FeeTxHash = feeTx.TxHash().String()
+	vspTicket.FeeTxStatus = VSPFeeTxStatusReceived
+	vspTicket.VoteChoices = voteChoices
+	vspTicket.UpdatedAt = time.Now().Unix()
+
+	err = mw.db.Save(vspTicket)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	log.Infof("[%d] Ticket %s registered with VSP %s", walletID, ticketHash, vspTicket.VSPHost)
+	return vspTicket, nil
+}
+
+// signedVSPFeeTx returns the signed transaction paying `feeAmount` to the
+// VSP fee address `feeAddress` from `feeAccount`. The wallet must be
+// unlocked.
+func (mw *MultiWallet) signedVSPFeeTx(wallet *Wallet, feeAccount int32, feeAddress string,
+	feeAmount int64) (*wire.MsgTx, error) {
+
+	txAuthor := mw.NewUnsignedTx(wallet, feeAccount)
+	txAuthor.AddSendDestination(feeAddress, feeAmount, false)
+
+	unsignedTx, err := txAuthor.constructTransaction()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if unsignedTx.ChangeIndex >= 0 {
+		unsignedTx.RandomizeChangePosition()
+	}
+
+	feeTx := unsignedTx.Tx
+	ctx := wallet.shutdownContext()
+	invalidSigs, err := wallet.internal.SignTransaction(ctx, feeTx, txscript.SigHashAll, nil, nil, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if len(invalidSigs) > 0 {
+		log.Errorf("[%d] VSP fee transaction has %d invalid signatures", wallet.ID, len(invalidSigs))
+		return nil, errors.New(ErrInvalid)
+	}
+
+	return feeTx, nil
+}
+
+// unlockVSPFeeInputs unlocks the inputs of the fee transaction of
+// `vspTicket` and removes them from the ticket.
+func (wallet *Wallet) unlockVSPFeeInputs(vspTicket *VSPTicket) {
+	for outpoint, tree := range vspTicket.FeeInputs {
+		op, err := parseOutPoint(outpoint, tree)
+		if err != nil {
+			log.Errorf("[%d] Invalid VSP fee input %s: %v", wallet.ID, outpoint, err)
+			continue
+		}
+		wallet.internal.UnlockOutpoint(*op)
+	}
+	vspTicket.FeeInputs = nil
+}
+
+// vspFeeInputsFn returns a function that returns the inputs of the fee
+// transactions of the specified wallet's tickets that are kept locked.
+func (mw *MultiWallet) vspFeeInputsFn(walletID int) func() map[string]int8 {
+	return func() map[string]int8 {
+		feeInputs := make(map[string]int8)
+
+		var vspTickets []*VSPTicket
+		err := mw.db.Find("WalletID", walletID, &vspTickets)
+		if err != nil && err != storm.ErrNotFound {
+			log.Errorf("[%d] Error reading VSP tickets: %v", walletID, err)
+			return feeInputs
+		}
+
+		for _, vspTicket := range vspTickets {
+			for outpoint, tree := range vspTicket.FeeInputs {
+				feeInputs[outpoint] = tree
+			}
+		}
+		return feeInputs
+	}
+}
+
+func txHex(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+	buf.Grow(tx.SerializeSize())
+	err := tx.Serialize(&buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// vspTicket returns the record of the ticket with the hex-encoded hash
+// `ticketHash` of the specified wallet registered with a VSP, nil if the
+// ticket was not registered.
+func (mw *MultiWallet) vspTicket(walletID int, ticketHash string) (*VSPTicket, error) {
+	vspTicket := &VSPTicket{}
+	err := mw.db.Select(q.Eq("WalletID", walletID), q.Eq("TicketHash", ticketHash)).First(vspTicket)
+	if err == storm.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vspTicket, nil
+}
+
+// vspTicketRequest signs `request` about the registered ticket `vspTicket`
+// with the ticket's commitment address and sends it to the ticket's VSP.
+func (mw *MultiWallet) vspTicketRequest(vspTicket *VSPTicket, path string, request interface{},
+	response interface{}, privPass []byte) error {
+
+	wallet := mw.WalletWithID(vspTicket.WalletID)
+	if wallet == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	_, _, commitmentAddr, _, err := wallet.vspTicketTxs(vspTicket.TicketHash)
+	if err != nil {
+		return err
+	}
+
+	lock := make(chan time.Time, 1)
+	defer func() {
+		lock <- time.Time{}
+	}()
+
+	ctx := wallet.shutdownContext()
+	err = wallet.unlock(ctx, privPass, lock)
+	if err != nil {
+		return err
+	}
+
+	sign := func(message string) ([]byte, error) {
+		return wallet.internal.SignMessage(ctx, message, commitmentAddr)
+	}
+	return newVSPDClient(vspTicket.VSPHost, vspTicket.VSPPubKey).do(http.MethodPost, path, request, sign, response)
+}
+
+// VSPTicketStatus returns the json-encoded `VSPTicket` of the ticket with the
+// hex-encoded hash `ticketHash` of the specified wallet, updated with the
+// status reported by its VSP.
+func (mw *MultiWallet) VSPTicketStatus(walletID int, ticketHash string, privPass []byte) (string, error) {
+	vspTicket, err := mw.VSPTicketStatusRaw(walletID, ticketHash, privPass)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTicket, err := json.Marshal(vspTicket)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTicket), nil
+}
+
+func (mw *MultiWallet) VSPTicketStatusRaw(walletID int, ticketHash string, privPass []byte) (*VSPTicket, error) {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	vspTicket, err := mw.vspTicket(walletID, ticketHash)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if vspTicket == nil {
+		return nil, errors.New(ErrNotExist)
+	}
+
+	request := map[string]interface{}{
+		"tickethash": ticketHash,
+	}
+	var response struct {
+		TicketConfirmed bool              `json:"ticketconfirmed"`
+		FeeTxStatus     string            `json:"feetxstatus"`
+		FeeTxHash       string            `json:"feetxhash"`
+		VoteChoices     map[string]string `json:"votechoices"`
+	}
+	err = mw.vspTicketRequest(vspTicket, "/ticketstatus", request, &response, privPass)
+	if err != nil {
+		return nil, err
+	}
+
+	vspTicket.TicketConfirmed = response.TicketConfirmed
+	vspTicket.FeeTxStatus = response.FeeTxStatus
+	if response.FeeTxHash != "" {
+		vspTicket.FeeTxHash = response.FeeTxHash
+	}
+	vspTicket.VoteChoices = response.VoteChoices
+	vspTicket.UpdatedAt = time.Now().Unix()
+
+	// the inputs of a confirmed fee transaction are spent and those of a
+	// failed one may be spent by other transactions
+	if vspTicket.FeeTxStatus == VSPFeeTxStatusConfirmed || vspTicket.FeeTxStatus == VSPFeeTxStatusError {
+		if wallet := mw.WalletWithID(walletID); wallet != nil {
+			wallet.unlockVSPFeeInputs(vspTicket)
+		}
+	}
+
+	err = mw.db.Save(vspTicket)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return vspTicket, nil
+}
+
+// SetVSPVoteChoices sets the json-encoded agenda choices, a map of agenda ID
+// to choice ID, that the VSP of the ticket with the hex-encoded hash
+// `ticketHash` of the specified wallet votes the ticket with.
+func (mw *MultiWallet) SetVSPVoteChoices(walletID int, ticketHash, jsonEncodedChoices string, privPass []byte) error {
+	var voteChoices map[string]string
+	err := json.Unmarshal([]byte(jsonEncodedChoices), &voteChoices)
+	if err != nil {
+		return errors.New(ErrInvalid)
+	}
+
+	return mw.SetVSPVoteChoicesRaw(walletID, ticketHash, voteChoices, privPass)
+}
+
+func (mw *MultiWallet) SetVSPVoteChoicesRaw(walletID int, ticketHash string, voteChoices map[string]string,
+	privPass []byte) error {
+
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	vspTicket, err := mw.vspTicket(walletID, ticketHash)
+	if err != nil {
+		return translateError(err)
+	}
+	if vspTicket == nil {
+		return errors.New(ErrNotExist)
+	}
+
+	request := map[string]interface{}{
+		"timestamp":   time.Now().Unix(),
+		"tickethash":  ticketHash,
+		"votechoices": voteChoices,
+	}
+	var response struct{}
+	err = mw.vspTicketRequest(vspTicket, "/setvotechoices", request, &response, privPass)
+	if err != nil {
+		return err
+	}
+
+	vspTicket.VoteChoices = voteChoices
+	vspTicket.UpdatedAt = time.Now().Unix()
+
+	err = mw.db.Save(vspTicket)
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// VSPTickets returns the json-encoded `VSPTicket`s of the specified wallet's
+// tickets registered with a VSP, as last updated.
+func (mw *MultiWallet) VSPTickets(walletID int) (string, error) {
+	vspTickets, err := mw.VSPTicketsRaw(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedTickets, err := json.Marshal(vspTickets)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedTickets), nil
+}
+
+func (mw *MultiWallet) VSPTicketsRaw(walletID int) ([]*VSPTicket, error) {
+	vspTickets := make([]*VSPTicket, 0)
+	err := mw.db.Find("WalletID", walletID, &vspTickets)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, translateError(err)
+	}
+	return vspTickets, nil
+}
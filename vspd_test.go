@@ -0,0 +1,96 @@
+package dcrlibwallet
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVSPDClientDo(t *testing.T) {
+	// failed requests are logged, the log rotator is not initialized
+	setLogLevel("DLWL", "off")
+
+	vspPubKey, vspPrivKey, err := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{1}, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivKey, err := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{2}, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(message string) ([]byte, error) {
+		return []byte("signature of " + message), nil
+	}
+
+	tests := []struct {
+		name        string
+		pubKey      []byte
+		sign        func(message string) ([]byte, error)
+		status      int
+		responseKey ed25519.PrivateKey
+		wantErr     string
+	}{
+		{name: "signed request and response", pubKey: vspPubKey, sign: sign, status: http.StatusOK,
+			responseKey: vspPrivKey},
+		{name: "unverified response", sign: sign, status: http.StatusOK},
+		{name: "response signed with another key", pubKey: vspPubKey, sign: sign, status: http.StatusOK,
+			responseKey: otherPrivKey, wantErr: ErrVSPFailure},
+		{name: "unsigned response", pubKey: vspPubKey, sign: sign, status: http.StatusOK, wantErr: ErrVSPFailure},
+		{name: "error response", pubKey: vspPubKey, sign: sign, status: http.StatusBadRequest,
+			responseKey: vspPrivKey, wantErr: ErrVSPFailure},
+		{name: "signing error", pubKey: vspPubKey, status: http.StatusOK, responseKey: vspPrivKey,
+			sign: func(string) ([]byte, error) {
+				return nil, fmt.Errorf("signing error")
+			}, wantErr: "signing error"},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil || r.URL.Path != vspdAPIPath+"/ticketstatus" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			wantSignature := base64.StdEncoding.EncodeToString([]byte("signature of " + string(body)))
+			if r.Header.Get("VSP-Client-Signature") != wantSignature {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			response := []byte(`{"tickethash":"` + string(body[1:len(body)-1]) + `"}`)
+			if test.responseKey != nil {
+				signature := ed25519.Sign(test.responseKey, response)
+				w.Header().Set("VSP-Server-Signature", base64.StdEncoding.EncodeToString(signature))
+			}
+			w.WriteHeader(test.status)
+			w.Write(response)
+		}))
+
+		var response struct {
+			TicketHash string `json:"tickethash"`
+		}
+		client := newVSPDClient(server.URL+"/", test.pubKey)
+		err := client.do(http.MethodPost, "/ticketstatus", "ticket", test.sign, &response)
+		server.Close()
+
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Fatalf("%s: error %v, want %s", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if response.TicketHash != "ticket" {
+			t.Fatalf("%s: ticket hash %q, want %q", test.name, response.TicketHash, "ticket")
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrwallet/errors/v2"
+)
+
+const (
+	// vspDirectoryURL is the public list of the VSPs of the Decred
+	// networks maintained by the Decred project.
+	vspDirectoryURL = "https://api.decred.org/?c=vsp"
+
+	// vspOnlineThreshold is how long after the directory last reached a VSP
+	// the VSP is still considered online.
+	vspOnlineThreshold = time.Hour
+)
+
+// VSP is a vspd VSP of the public VSP directory. The directory does not track
+// uptime, LastUpdated is the unix timestamp of the last time it reached the
+// VSP, and Online is true if that was within the last hour. Voted, Missed and
+// Expired are the counts of tickets of the VSP that voted, missed their vote
+// or expired. MissedProportion is the proportion of the votes of the VSP that
+// were missed.
+type VSP struct {
+	Host             string  `json:"host"`
+	Network          string  `json:"network"`
+	FeePercentage    float64 `json:"fee_percentage"`
+	Launched         int64   `json:"launched"`
+	LastUpdated      int64   `json:"last_updated"`
+	Online           bool    `json:"online"`
+	Voting           int64   `json:"voting"`
+	Voted            int64   `json:"voted"`
+	Missed           int64   `json:"missed"`
+	Expired          int64   `json:"expired"`
+	MissedProportion float64 `json:"missed_proportion"`
+}
+
+// vspDirectoryEntry is a VSP as listed by the VSP directory.
+type vspDirectoryEntry struct {
+	Network       string  `json:"network"`
+	URL           string  `json:"url"`
+	Launched      int64   `json:"launched"`
+	LastUpdated   int64   `json:"lastupdated"`
+	APIVersions   []int64 `json:"apiversions"`
+	FeePercentage float64 `json:"feepercentage"`
+	Closed        bool    `json:"closed"`
+	Voting        int64   `json:"voting"`
+	Voted         int64   `json:"voted"`
+	Missed        int64   `json:"missed"`
+	Expired       int64   `json:"expired"`
+}
+
+// supportsVSPDAPI returns true if the VSP supports the version of the vspd
+// API used to register tickets.
+func (entry *vspDirectoryEntry) supportsVSPDAPI() bool {
+	for _, version := range entry.APIVersions {
+		if version == 3 {
+			return true
+		}
+	}
+	return false
+}
+
+// GetVSPs returns the json-encoded `VSP`s of the public VSP directory that
+// tickets of this network can be registered with, cheapest first. VSPs with
+// the same fee are ordered by their proportion of missed votes.
+func (mw *MultiWallet) GetVSPs() (string, error) {
+	vsps, err := mw.GetVSPsRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedVSPs, err := json.Marshal(vsps)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedVSPs), nil
+}
+
+func (mw *MultiWallet) GetVSPsRaw() ([]*VSP, error) {
+	httpClient := &http.Client{Timeout: vspdRequestTimeout}
+	resp, err := httpClient.Get(vspDirectoryURL)
+	if err != nil {
+		log.Errorf("VSP directory request error: %v", err)
+		return nil, errors.New(ErrVSPFailure)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf("VSP directory error: %s", resp.Status)
+		return nil, errors.New(ErrVSPFailure)
+	}
+
+	var entries map[string]*vspDirectoryEntry
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxVSPDResponseSize)).Decode(&entries)
+	if err != nil {
+		log.Errorf("VSP directory response error: %v", err)
+		return nil, errors.New(ErrVSPFailure)
+	}
+
+	// the directory names the testnet "testnet" rather than "testnet3"
+	network := mw.chainParams.Name
+	if network == "testnet3" {
+		network = "testnet"
+	}
+
+	now := time.Now()
+	vsps := make([]*VSP, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Network != network || entry.Closed || !entry.supportsVSPDAPI() {
+			continue
+		}
+
+		vsp := &VSP{
+			Host:          entry.URL,
+			Network:       entry.Network,
+			FeePercentage: entry.FeePercentage,
+			Launched:      entry.Launched,
+			LastUpdated:   entry.LastUpdated,
+			Online:        now.Sub(time.Unix(entry.LastUpdated, 0)) <= vspOnlineThreshold,
+			Voting:        entry.Voting,
+			Voted:         entry.Voted,
+			Missed:        entry.Missed,
+			Expired:       entry.Expired,
+		}
+		if votes := entry.Voted + entry.Missed; votes > 0 {
+			vsp.MissedProportion = float64(entry.Missed) / float64(votes)
+		}
+		vsps = append(vsps, vsp)
+	}
+
+	sort.Slice(vsps, func(i, j int) bool {
+		if vsps[i].FeePercentage != vsps[j].FeePercentage {
+			return vsps[i].FeePercentage < vsps[j].FeePercentage
+		}
+		return vsps[i].MissedProportion < vsps[j].MissedProportion
+	})
+
+	return vsps, nil
+}
@@ -2,16 +2,17 @@ package dcrlibwallet
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/v2"
 	"github.com/decred/dcrwallet/errors/v2"
 	w "github.com/decred/dcrwallet/wallet/v3"
-	"github.com/decred/dcrwallet/walletseed"
 	"github.com/raedahgroup/dcrlibwallet/internal/loader"
 	"github.com/raedahgroup/dcrlibwallet/txindex"
 )
@@ -26,6 +27,26 @@ type Wallet struct {
 	HasDiscoveredAccounts bool
 	PrivatePassphraseType int32
 
+	// HasPublicPassphrase is true if a non-default public passphrase is
+	// required to open this wallet.
+	HasPublicPassphrase bool
+
+	// Tag is an app-defined icon or color tag used when displaying this wallet.
+	Tag string
+
+	// HasSeedPassphrase is true if a seed passphrase was used to derive
+	// this wallet's keys from its seed.
+	HasSeedPassphrase bool
+
+	// Birthday is the time before which this wallet is known to have no
+	// transactions. Blocks mined before the birthday are not scanned for
+	// this wallet. A zero birthday means the whole chain is scanned.
+	Birthday time.Time
+
+	// IsDuressWallet is true if this wallet is loaded when the wallets
+	// are opened using the duress passphrase.
+	IsDuressWallet bool
+
 	internal    *w.Wallet
 	chainParams *chaincfg.Params
 	dataDir     string
@@ -39,6 +60,20 @@ type Wallet struct {
 	shuttingDown chan bool
 	cancelFuncs  []context.CancelFunc
 
+	unlockSessionMu    sync.Mutex
+	unlockSession      bool
+	unlockSessionTimer *time.Timer
+
+	// rebroadcastCounts is the number of times each unmined transaction was
+	// rebroadcast since the wallet was loaded.
+	rebroadcastCountsMu sync.Mutex
+	rebroadcastCounts   map[string]int32
+
+	// ticketStates are the last known states of the unspent tickets of the
+	// wallet by hash, nil until the tickets are read once the wallet is synced.
+	ticketStatesMu sync.Mutex
+	ticketStates   map[string]*ticketState
+
 	// setUserConfigValue saves the provided key-value pair to a config database.
 	// This function is ideally assigned when the `wallet.prepare` method is
 	// called from a MultiWallet instance.
@@ -49,18 +84,72 @@ type Wallet struct {
 	// This function is ideally assigned when the `wallet.prepare` method is
 	// called from a MultiWallet instance.
 	readUserConfigValue configReadFn
+
+	// walletHooks are set when the `wallet.prepare` method is called from a
+	// MultiWallet instance.
+	walletHooks
+}
+
+// walletHooks are the functions through which a wallet reads and updates the
+// data that the MultiWallet keeps for it.
+type walletHooks struct {
+	// deleteUserConfigValue deletes the value saved for the provided key from
+	// a config database.
+	deleteUserConfigValue configDeleteFn
+
+	// unlockAttemptsExceeded is called to wipe the wallet when the maximum
+	// number of failed unlock attempts is exceeded.
+	unlockAttemptsExceeded func()
+
+	// duressUnlock is called with a passphrase that failed to unlock the
+	// wallet, it returns true if the passphrase is the duress passphrase and
+	// the wallet was hidden.
+	duressUnlock func(passphrase []byte) bool
+
+	// walletLocked is called when the wallet is locked after being unlocked
+	// with `UnlockWallet`.
+	walletLocked func()
+
+	// keySourcePassphrase returns the private passphrase of the wallet from
+	// the key source set by the host app, or nil if no key source is set.
+	keySourcePassphrase func() ([]byte, error)
+
+	// contactName returns the name of the address book contact with the
+	// provided address, or an empty string.
+	contactName func(address string) string
+
+	// atomicSwapActionsFn returns the actions of the transactions of the
+	// atomic swaps of this wallet, by transaction hash.
+	atomicSwapActionsFn func() map[string]string
+
+	// txNote returns the note attached to the transaction with the provided
+	// hash, or an empty string.
+	txNote func(txHash string) string
+
+	// txTags returns the tags of the transaction with the provided hash.
+	txTags func(txHash string) []string
+
+	// txFiatRate returns the exchange rate recorded when the transaction with
+	// the provided hash was confirmed.
+	txFiatRate func(txHash string) *TxFiatRate
+
+	// vspFeeInputs returns the inputs of the fee transactions of the tickets
+	// of this wallet registered with a VSP that must be kept locked, mapped
+	// to their tree.
+	vspFeeInputs func() map[string]int8
 }
 
 // prepare gets a wallet ready for use by opening the transactions index database
 // and initializing the wallet loader which can be used subsequently to create,
 // load and unload the wallet.
 func (wallet *Wallet) prepare(rootDir string, chainParams *chaincfg.Params,
-	setUserConfigValueFn configSaveFn, readUserConfigValueFn configReadFn) (err error) {
+	setUserConfigValueFn configSaveFn, readUserConfigValueFn configReadFn, hooks walletHooks) (err error) {
 
 	wallet.chainParams = chainParams
 	wallet.dataDir = filepath.Join(rootDir, strconv.Itoa(wallet.ID))
 	wallet.setUserConfigValue = setUserConfigValueFn
 	wallet.readUserConfigValue = readUserConfigValueFn
+	wallet.walletHooks = hooks
 
 	// open database for indexing transactions for faster loading
 	txDBPath := filepath.Join(wallet.dataDir, txindex.DbName)
@@ -71,7 +160,7 @@ func (wallet *Wallet) prepare(rootDir string, chainParams *chaincfg.Params,
 	}
 
 	// init loader
-	wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver)
+	wallet.loader = initWalletLoader(wallet.chainParams, wallet.dataDir, wallet.DbDriver, wallet.GapLimit())
 
 	// init cancelFuncs slice to hold cancel functions for long running
 	// operations and start go routine to listen for shutdown signal
@@ -92,6 +181,8 @@ func (wallet *Wallet) Shutdown() {
 	// `wallet.shutdownContext()` or `wallet.shutdownContextWithCancel()`.
 	wallet.shuttingDown <- true
 
+	wallet.endUnlockSession()
+
 	if _, loaded := wallet.loader.LoadedWallet(); loaded {
 		err := wallet.loader.UnloadWallet()
 		if err != nil {
@@ -121,6 +212,50 @@ func (wallet *Wallet) WalletCreationTimeInMillis() (int64, error) {
 	return wallet.CreatedAt.UnixNano() / int64(time.Millisecond), nil
 }
 
+// WalletInfo returns the json-encoded display name, creation date, tag and
+// other metadata for this wallet.
+func (wallet *Wallet) WalletInfo() (string, error) {
+	result, err := json.Marshal(wallet.WalletInfoRaw())
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+func (wallet *Wallet) WalletInfoRaw() *WalletInfo {
+	info := &WalletInfo{
+		ID:                wallet.ID,
+		Name:              wallet.Name,
+		CreatedAt:         wallet.CreatedAt.Unix(),
+		Birthday:          birthdayUnix(wallet.Birthday),
+		Tag:               wallet.Tag,
+		NetType:           wallet.NetType(),
+		IsRestored:        wallet.IsRestored,
+		HasSeedPassphrase: wallet.HasSeedPassphrase,
+		IsWatchingOnly:    wallet.IsWatchingOnlyWallet(),
+		CoinType:          -1,
+	}
+
+	// the coin type and derivation path of the default account can only
+	// be read from wallets that have been opened
+	if wallet.WalletOpened() {
+		if coinType, err := wallet.CoinType(); err == nil {
+			info.CoinType = coinType
+			info.DerivationPath, _ = wallet.HDPathForAccount(0)
+		}
+	}
+
+	return info
+}
+
+func birthdayUnix(birthday time.Time) int64 {
+	if birthday.IsZero() {
+		return 0
+	}
+	return birthday.Unix()
+}
+
 func (wallet *Wallet) NetType() string {
 	return wallet.chainParams.Name
 }
@@ -129,15 +264,23 @@ func (wallet *Wallet) WalletExists() (bool, error) {
 	return wallet.loader.WalletExists()
 }
 
-func (wallet *Wallet) createWallet(privatePassphrase, seedMnemonic string) error {
+// createWallet creates the wallet from `seedMnemonic` and `seedPassphrase`,
+// deriving its accounts with the legacy or SLIP0044 `coinType`. The default
+// public passphrase is used if `publicPassphrase` is empty.
+func (wallet *Wallet) createWallet(publicPassphrase, privatePassphrase, seedMnemonic, seedPassphrase string,
+	coinType uint32) error {
+
 	log.Info("Creating Wallet")
 	if len(seedMnemonic) == 0 {
 		return errors.New(ErrEmptySeed)
 	}
 
-	pubPass := []byte(w.InsecurePubPassphrase)
+	pubPass := []byte(publicPassphrase)
+	if len(pubPass) == 0 {
+		pubPass = []byte(w.InsecurePubPassphrase)
+	}
 	privPass := []byte(privatePassphrase)
-	seed, err := walletseed.DecodeUserInput(seedMnemonic)
+	seed, err := decodeSeedMnemonic(seedMnemonic, seedPassphrase)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -151,6 +294,22 @@ func (wallet *Wallet) createWallet(privatePassphrase, seedMnemonic string) error
 
 	wallet.internal = createdWallet
 
+	ctx := wallet.shutdownContext()
+	currentCoinType, err := createdWallet.CoinType(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	if coinType != currentCoinType {
+		if coinType != wallet.chainParams.SLIP0044CoinType {
+			return errors.New(ErrInvalid)
+		}
+		err = createdWallet.UpgradeToSLIP0044CoinType(ctx)
+		if err != nil {
+			log.Error(err)
+			return translateError(err)
+		}
+	}
+
 	log.Info("Created Wallet")
 	return nil
 }
@@ -178,8 +337,12 @@ func (wallet *Wallet) IsWatchingOnlyWallet() bool {
 	return false
 }
 
-func (wallet *Wallet) openWallet() error {
-	pubPass := []byte(w.InsecurePubPassphrase)
+// openWallet opens the wallet using the provided public passphrase.
+// The default public passphrase is used if `pubPass` is empty.
+func (wallet *Wallet) openWallet(pubPass []byte) error {
+	if len(pubPass) == 0 {
+		pubPass = []byte(w.InsecurePubPassphrase)
+	}
 
 	openedWallet, err := wallet.loader.OpenExistingWallet(wallet.shutdownContext(), pubPass)
 	if err != nil {
@@ -188,6 +351,7 @@ func (wallet *Wallet) openWallet() error {
 	}
 
 	wallet.internal = openedWallet
+	wallet.restoreLockedOutputs()
 
 	return nil
 }
@@ -196,31 +360,90 @@ func (wallet *Wallet) WalletOpened() bool {
 	return wallet.internal != nil
 }
 
+// UnlockWallet unlocks the wallet until `LockWallet` is called.
 func (wallet *Wallet) UnlockWallet(privPass []byte) error {
+	return wallet.UnlockWalletWithTimeout(privPass, 0)
+}
+
+// UnlockWalletWithTimeout unlocks the wallet for a session that ends after
+// `timeoutSeconds` or when `LockWallet` is called. The wallet is kept unlocked
+// until `LockWallet` is called if `timeoutSeconds` is 0. Spending and signing
+// methods may be called with an empty passphrase while the session is active.
+func (wallet *Wallet) UnlockWalletWithTimeout(privPass []byte, timeoutSeconds int64) error {
 	loadedWallet, ok := wallet.loader.LoadedWallet()
 	if !ok {
 		return fmt.Errorf("wallet has not been loaded")
 	}
 
+	if loadedWallet.Manager.WatchingOnly() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
 	defer func() {
 		for i := range privPass {
 			privPass[i] = 0
 		}
 	}()
 
+	if timeoutSeconds < 0 {
+		return errors.New(ErrInvalid)
+	}
+
 	ctx, _ := wallet.shutdownContextWithCancel()
-	err := loadedWallet.Unlock(ctx, privPass, nil)
+	err := wallet.unlock(ctx, privPass, nil)
 	if err != nil {
-		return translateError(err)
+		return err
+	}
+
+	wallet.startUnlockSession(time.Duration(timeoutSeconds) * time.Second)
+	return nil
+}
+
+// UnlockCheck verifies the provided private passphrase by unlocking the wallet.
+// The wallet is immediately re-locked if it was locked before this check.
+func (wallet *Wallet) UnlockCheck(privPass []byte) error {
+	defer func() {
+		for i := range privPass {
+			privPass[i] = 0
+		}
+	}()
+
+	if !wallet.WalletOpened() {
+		return errors.New(ErrWalletNotLoaded)
+	}
+
+	if wallet.IsWatchingOnlyWallet() {
+		return errors.New(ErrWalletIsWatchOnly)
+	}
+
+	wasLocked := wallet.internal.Locked()
+	err := wallet.unlock(wallet.shutdownContext(), privPass, nil)
+	if err != nil {
+		return err
+	}
+
+	if wasLocked {
+		wallet.internal.Lock()
 	}
 
 	return nil
 }
 
+// LockWallet locks the wallet and ends the unlock session, if any.
 func (wallet *Wallet) LockWallet() {
-	if !wallet.internal.Locked() {
+	hadUnlockSession := wallet.endUnlockSession()
+	if !wallet.WalletOpened() {
+		return
+	}
+
+	wasUnlocked := !wallet.internal.Locked()
+	if wasUnlocked {
 		wallet.internal.Lock()
 	}
+
+	if (wasUnlocked || hadUnlockSession) && wallet.walletLocked != nil {
+		wallet.walletLocked()
+	}
 }
 
 func (wallet *Wallet) IsLocked() bool {
@@ -238,7 +461,39 @@ func (wallet *Wallet) changePrivatePassphrase(oldPass []byte, newPass []byte) er
 		}
 	}()
 
+	if wallet.UnlockBackoffRemaining() > 0 {
+		return errors.New(ErrTooManyAttempts)
+	}
+
 	err := wallet.internal.ChangePrivatePassphrase(wallet.shutdownContext(), oldPass, newPass)
+	if err != nil {
+		if isPassphraseError(err) {
+			wallet.recordFailedUnlock()
+		}
+		return translateError(err)
+	}
+	return nil
+}
+
+func (wallet *Wallet) changePublicPassphrase(oldPass []byte, newPass []byte) error {
+	defer func() {
+		for i := range oldPass {
+			oldPass[i] = 0
+		}
+
+		for i := range newPass {
+			newPass[i] = 0
+		}
+	}()
+
+	if len(oldPass) == 0 {
+		oldPass = []byte(w.InsecurePubPassphrase)
+	}
+	if len(newPass) == 0 {
+		newPass = []byte(w.InsecurePubPassphrase)
+	}
+
+	err := wallet.internal.ChangePublicPassphrase(wallet.shutdownContext(), oldPass, newPass)
 	if err != nil {
 		return translateError(err)
 	}
@@ -257,15 +512,37 @@ func (wallet *Wallet) deleteWallet(privatePassphrase []byte) error {
 	}
 
 	if !wallet.IsWatchingOnlyWallet() {
-		err := wallet.internal.Unlock(wallet.shutdownContext(), privatePassphrase, nil)
+		err := wallet.unlock(wallet.shutdownContext(), privatePassphrase, nil)
 		if err != nil {
-			return translateError(err)
+			return err
 		}
 		wallet.internal.Lock()
 	}
 
+	return wallet.wipeWallet()
+}
+
+// wipeWallet shuts down the wallet, overwrites the wallet files and deletes
+// the wallet data directory.
+func (wallet *Wallet) wipeWallet() error {
 	wallet.Shutdown()
 
 	log.Info("Deleting Wallet")
+
+	// overwrite the wallet db files before unlinking them
+	// to prevent the recovery of wallet data from disk.
+	err := filepath.Walk(wallet.dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return overwriteFile(path, info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Error overwriting wallet files: %v", err)
+	}
+
 	return os.RemoveAll(wallet.dataDir)
 }
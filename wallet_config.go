@@ -30,6 +30,18 @@ func (wallet *Wallet) ReadUserConfigValue(key string, valueOut interface{}) erro
 	return err
 }
 
+func (wallet *Wallet) DeleteUserConfigValueForKey(key string) {
+	if wallet.deleteUserConfigValue == nil {
+		log.Errorf("call wallet.prepare before deleting wallet config values")
+		return
+	}
+
+	err := wallet.deleteUserConfigValue(key)
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("error deleting config value for key: %s, error: %v", key, err)
+	}
+}
+
 func (wallet *Wallet) SetBoolConfigValueForKey(key string, value bool) {
 	wallet.SaveUserConfigValue(key, value)
 }
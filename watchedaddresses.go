@@ -0,0 +1,413 @@
+package dcrlibwallet
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v2"
+	"github.com/decred/dcrd/txscript/v2"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/dcrwallet/errors/v2"
+	"github.com/raedahgroup/dcrlibwallet/spv"
+)
+
+// WatchedAddress is an address that does not belong to any of the wallets,
+// such as a VSP fee address or an exchange deposit address, whose balance and
+// activity is tracked while the multiwallet is synced. Only transactions
+// observed after the address is watched are tracked.
+type WatchedAddress struct {
+	ID            int       `storm:"id,increment" json:"id"`
+	Address       string    `storm:"unique" json:"address"`
+	Label         string    `json:"label"`
+	Balance       int64     `json:"balance"`
+	TotalReceived int64     `json:"total_received"`
+	LastActivity  int64     `json:"last_activity"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// watchedOutput is an output paid to a watched address. The ID of a watched
+// output is its outpoint, formatted as hash:index.
+// SpentHeight is the height of the block of the transaction that spent the
+// output, -1 if the transaction is unmined.
+type watchedOutput struct {
+	ID          string `storm:"id"`
+	Address     string `storm:"index"`
+	TxHash      string
+	Index       uint32
+	Tree        int8
+	Amount      int64
+	BlockHeight int32
+	Spent       bool
+	SpentHeight int32
+}
+
+// WatchAddress starts tracking the balance and activity of `address`, which
+// must not belong to any of the opened wallets. Watched address listeners are
+// notified of transactions that pay to or spend from the address.
+func (mw *MultiWallet) WatchAddress(address, label string) (int, error) {
+	address = strings.TrimSpace(address)
+	addr, err := dcrutil.DecodeAddress(address, mw.chainParams)
+	if err != nil {
+		return 0, errors.New(ErrInvalidAddress)
+	}
+
+	if mw.HaveAddress(address) {
+		return 0, errors.New(ErrInvalid)
+	}
+
+	watchedAddress := &WatchedAddress{
+		Address:   address,
+		Label:     strings.TrimSpace(label),
+		CreatedAt: time.Now(),
+	}
+
+	err = mw.db.Save(watchedAddress)
+	if err != nil {
+		if err == storm.ErrAlreadyExists {
+			return 0, errors.New(ErrExist)
+		}
+		return 0, err
+	}
+
+	mw.syncData.mu.RLock()
+	syncer := mw.syncData.syncer
+	mw.syncData.mu.RUnlock()
+	if syncer != nil {
+		syncer.WatchAddresses([]dcrutil.Address{addr}, nil)
+	}
+
+	return watchedAddress.ID, nil
+}
+
+// UnwatchAddress stops tracking the specified watched address.
+func (mw *MultiWallet) UnwatchAddress(watchedAddressID int) error {
+	mw.watchedAddressesMu.Lock()
+	defer mw.watchedAddressesMu.Unlock()
+
+	watchedAddress := &WatchedAddress{}
+	err := mw.db.One("ID", watchedAddressID, watchedAddress)
+	if err != nil {
+		if err == storm.ErrNotFound {
+			return errors.New(ErrNotExist)
+		}
+		return err
+	}
+
+	tx, err := mw.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var outputs []watchedOutput
+	err = tx.Find("Address", watchedAddress.Address, &outputs)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	for i := range outputs {
+		if err = tx.DeleteStruct(&outputs[i]); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.DeleteStruct(watchedAddress); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	mw.resetWatchFilter()
+	return nil
+}
+
+// WatchedAddresses returns the json-encoded list of watched addresses.
+func (mw *MultiWallet) WatchedAddresses() (string, error) {
+	watchedAddresses, err := mw.WatchedAddressesRaw()
+	if err != nil {
+		return "", err
+	}
+
+	jsonEncodedWatchedAddresses, err := json.Marshal(watchedAddresses)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonEncodedWatchedAddresses), nil
+}
+
+func (mw *MultiWallet) WatchedAddressesRaw() ([]WatchedAddress, error) {
+	watchedAddresses := make([]WatchedAddress, 0)
+	err := mw.db.All(&watchedAddresses)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return watchedAddresses, nil
+}
+
+// watchedAddressesFilter returns the watched addresses and their unspent
+// outpoints, to be added to the syncer's watch filter.
+func (mw *MultiWallet) watchedAddressesFilter() ([]dcrutil.Address, []wire.OutPoint, error) {
+	watchedAddresses, err := mw.WatchedAddressesRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addrs := make([]dcrutil.Address, 0, len(watchedAddresses))
+	for _, watchedAddress := range watchedAddresses {
+		addr, err := dcrutil.DecodeAddress(watchedAddress.Address, mw.chainParams)
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+
+	var outputs []watchedOutput
+	err = mw.db.Find("Spent", false, &outputs)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, nil, err
+	}
+
+	outpoints := make([]wire.OutPoint, 0, len(outputs))
+	for _, output := range outputs {
+		hash, err := chainhash.NewHashFromStr(output.TxHash)
+		if err != nil {
+			continue
+		}
+		outpoints = append(outpoints, *wire.NewOutPoint(hash, output.Index, output.Tree))
+	}
+
+	return addrs, outpoints, nil
+}
+
+// resetWatchFilter replaces the watch filter of the running syncer, if any,
+// with a filter of the watched addresses and their unspent outpoints.
+func (mw *MultiWallet) resetWatchFilter() {
+	mw.syncData.mu.RLock()
+	syncer := mw.syncData.syncer
+	mw.syncData.mu.RUnlock()
+	if syncer == nil {
+		return
+	}
+
+	addrs, outpoints, err := mw.watchedAddressesFilter()
+	if err != nil {
+		log.Errorf("Error reading watched addresses: %v", err)
+		return
+	}
+
+	syncer.SetWatchedAddresses(addrs, outpoints)
+}
+
+// watchedTxs updates the watched addresses paid to or spent from by `txs` and
+// notifies the watched address listeners. Transactions are reported again when
+// they are mined, outputs that were already recorded are only updated with
+// the block height.
+func (mw *MultiWallet) watchedTxs(txs []*wire.MsgTx, blockHeight int32) {
+	mw.watchedAddressesMu.Lock()
+	defer mw.watchedAddressesMu.Unlock()
+
+	for _, tx := range txs {
+		hash := tx.TxHash()
+		txHash := hash.String()
+
+		for _, input := range tx.TxIn {
+			output := &watchedOutput{}
+			err := mw.db.One("ID", input.PreviousOutPoint.String(), output)
+			if err != nil || output.Spent {
+				continue
+			}
+
+			output.Spent = true
+			output.SpentHeight = blockHeight
+			err = mw.updateWatchedAddress(output, -output.Amount, 0)
+			if err != nil {
+				log.Errorf("Error updating watched address %s: %v", output.Address, err)
+				continue
+			}
+
+			mw.publishWatchedAddressActivity(output.Address, txHash, -output.Amount, blockHeight)
+		}
+
+		for i, txOut := range tx.TxOut {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version, txOut.PkScript, mw.chainParams)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+
+			address := addrs[0].Address()
+			if err = mw.db.One("Address", address, &WatchedAddress{}); err != nil {
+				continue
+			}
+
+			outpoint := wire.NewOutPoint(&hash, uint32(i), spv.TxTree(tx))
+			output := &watchedOutput{}
+			err = mw.db.One("ID", outpoint.String(), output)
+			if err == nil {
+				if output.BlockHeight != -1 || blockHeight == -1 {
+					continue
+				}
+
+				// previously unmined output, now mined
+				output.BlockHeight = blockHeight
+				err = mw.db.Save(output)
+			} else {
+				output = &watchedOutput{
+					ID:          outpoint.String(),
+					Address:     address,
+					TxHash:      txHash,
+					Index:       outpoint.Index,
+					Tree:        outpoint.Tree,
+					Amount:      txOut.Value,
+					BlockHeight: blockHeight,
+				}
+				err = mw.updateWatchedAddress(output, txOut.Value, txOut.Value)
+			}
+			if err != nil {
+				log.Errorf("Error updating watched address %s: %v", address, err)
+				continue
+			}
+
+			mw.publishWatchedAddressActivity(address, txHash, txOut.Value, blockHeight)
+		}
+	}
+}
+
+// watchedBlocksDetached reverts the changes of the watched addresses caused by
+// the transactions mined in the blocks from `blockHeight`, which were removed
+// from the main chain. Outputs mined in the blocks are removed and outputs
+// spent in the blocks are unspent. Transactions that are mined again in the
+// new main chain are reported again.
+func (mw *MultiWallet) watchedBlocksDetached(blockHeight int32) {
+	mw.watchedAddressesMu.Lock()
+	defer mw.watchedAddressesMu.Unlock()
+
+	var outputs []watchedOutput
+	err := mw.db.All(&outputs)
+	if err != nil && err != storm.ErrNotFound {
+		log.Errorf("Error reading watched outputs: %v", err)
+		return
+	}
+
+	var reverted bool
+	for i := range outputs {
+		output := &outputs[i]
+
+		if output.Spent && output.SpentHeight >= blockHeight {
+			output.Spent = false
+			output.SpentHeight = 0
+			err = mw.updateWatchedAddress(output, output.Amount, 0)
+			if err != nil {
+				log.Errorf("Error updating watched address %s: %v", output.Address, err)
+				continue
+			}
+			reverted = true
+		}
+
+		if output.BlockHeight >= blockHeight {
+			err = mw.removeWatchedOutput(output)
+			if err != nil {
+				log.Errorf("Error updating watched address %s: %v", output.Address, err)
+				continue
+			}
+			reverted = true
+		}
+	}
+
+	// unspent outputs were removed from the watch filter when spent
+	if reverted {
+		mw.resetWatchFilter()
+	}
+}
+
+// removeWatchedOutput deletes `output`, which must be unspent, and subtracts
+// its amount from the balance and total received of its watched address.
+func (mw *MultiWallet) removeWatchedOutput(output *watchedOutput) error {
+	tx, err := mw.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	watchedAddress := &WatchedAddress{}
+	err = tx.One("Address", output.Address, watchedAddress)
+	if err != nil {
+		return err
+	}
+
+	watchedAddress.Balance -= output.Amount
+	watchedAddress.TotalReceived -= output.Amount
+
+	if err = tx.Save(watchedAddress); err != nil {
+		return err
+	}
+	if err = tx.DeleteStruct(output); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateWatchedAddress saves `output` and adds `balanceChange` and `received`
+// to the balance and total received of the output's watched address.
+func (mw *MultiWallet) updateWatchedAddress(output *watchedOutput, balanceChange, received int64) error {
+	tx, err := mw.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	watchedAddress := &WatchedAddress{}
+	err = tx.One("Address", output.Address, watchedAddress)
+	if err != nil {
+		return err
+	}
+
+	watchedAddress.Balance += balanceChange
+	watchedAddress.TotalReceived += received
+	watchedAddress.LastActivity = time.Now().Unix()
+
+	if err = tx.Save(watchedAddress); err != nil {
+		return err
+	}
+	if err = tx.Save(output); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (mw *MultiWallet) AddWatchedAddressListener(watchedAddressListener WatchedAddressListener, uniqueIdentifier string) error {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	_, ok := mw.watchedAddressListeners[uniqueIdentifier]
+	if ok {
+		return errors.New(ErrListenerAlreadyExist)
+	}
+
+	mw.watchedAddressListeners[uniqueIdentifier] = watchedAddressListener
+
+	return nil
+}
+
+func (mw *MultiWallet) RemoveWatchedAddressListener(uniqueIdentifier string) {
+	mw.notificationListenersMu.Lock()
+	defer mw.notificationListenersMu.Unlock()
+
+	delete(mw.watchedAddressListeners, uniqueIdentifier)
+}
+
+func (mw *MultiWallet) publishWatchedAddressActivity(address, txHash string, amount int64, blockHeight int32) {
+	mw.notificationListenersMu.RLock()
+	defer mw.notificationListenersMu.RUnlock()
+
+	for _, watchedAddressListener := range mw.watchedAddressListeners {
+		watchedAddressListener.OnWatchedAddressActivity(address, txHash, amount, blockHeight)
+	}
+}